@@ -17,7 +17,9 @@ limitations under the License.
 package brokers
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,15 +43,7 @@ func NewCmd(config *config.Config) *cobra.Command {
 					return err
 				}
 			}
-			list, err := List(config.ConfigHome, config.Context)
-			if err != nil {
-				return err
-			}
-			if len(list) == 0 {
-				return nil
-			}
-			fmt.Println(strings.Join(list, "\n"))
-			return nil
+			return printList(config)
 		},
 	}
 	brokersCmd.Flags().StringVar(&broker, "set", "", "Change the current broker")
@@ -63,6 +57,34 @@ func NewCmd(config *config.Config) *cobra.Command {
 	return brokersCmd
 }
 
+// printList prints the brokers of the workspace and the home directory.
+func printList(c *config.Config) error {
+	list, err := List(c.ConfigHome, c.Context)
+	if err != nil {
+		return err
+	}
+	home := config.HomeAbsPath()
+	if c.ConfigHome == home {
+		if len(list) != 0 {
+			fmt.Println(strings.Join(list, "\n"))
+		}
+		return nil
+	}
+	fmt.Printf("Workspace %s:\n", c.ConfigHome)
+	if len(list) != 0 {
+		fmt.Println(strings.Join(list, "\n"))
+	}
+	homeList, err := List(home, "")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	fmt.Printf("\nHome %s:\n", home)
+	if len(homeList) != 0 {
+		fmt.Println(strings.Join(homeList, "\n"))
+	}
+	return nil
+}
+
 func List(configDir, currentContext string) ([]string, error) {
 	dirs, err := os.ReadDir(configDir)
 	if err != nil {
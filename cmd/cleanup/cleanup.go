@@ -0,0 +1,262 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/journal"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/log"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+var providers = []string{adapter.ProviderAWS, adapter.ProviderAzure, adapter.ProviderGCP}
+
+type CliOptions struct {
+	Config *config.Config
+	CRD    map[string]crd.CRD
+
+	External bool
+	DryRun   bool
+	Force    bool
+	Provider string
+}
+
+func NewCmd(config *config.Config, crd map[string]crd.CRD) *cobra.Command {
+	o := &CliOptions{
+		CRD:    crd,
+		Config: config,
+	}
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup --external [--provider <provider>][--dry-run][--force]",
+		Short: "Remove leaked external resources of the deleted components",
+		Long: `Remove leaked external resources of the deleted components.
+
+Every component that creates the cloud resources, such as the SQS queues
+or the Pub/Sub subscriptions, is recorded in the journal in the home
+configuration directory. Components that are no longer in their broker
+manifest, e.g. when the broker or workspace directory was removed by hand
+or the deletion failed midway, are finalized and removed from the journal.
+Journal records of all workspaces are checked. Components that are still in
+any known broker manifest with the same external resources, e.g. in the moved
+workspace, are kept. Components of the workspaces that no longer exist are
+finalized only with --force, the workspace may have been moved.`,
+		Example: `tmctl cleanup --external --dry-run
+tmctl cleanup --external --provider aws`,
+		Args: cobra.NoArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{"--external", "--provider", "--dry-run", "--force"}, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !o.External {
+				return fmt.Errorf("nothing to clean up, use --external to remove the leaked external resources")
+			}
+			if o.Provider != "" && !contains(providers, o.Provider) {
+				return fmt.Errorf("unsupported provider %q, expected one of: %s", o.Provider, strings.Join(providers, ", "))
+			}
+			return o.cleanup(cmd.Context())
+		},
+	}
+	cleanupCmd.Flags().BoolVar(&o.External, "external", false, "Finalize the external resources of the components that are no longer in the manifests")
+	cleanupCmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "Print the components that would be finalized")
+	cleanupCmd.Flags().BoolVar(&o.Force, "force", false, "Finalize the components of the workspaces that no longer exist")
+	cleanupCmd.Flags().StringVar(&o.Provider, "provider", "", "Clean up the resources of one cloud provider: "+strings.Join(providers, ", "))
+	cobra.CheckErr(cleanupCmd.RegisterFlagCompletionFunc("provider", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return providers, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cleanupCmd
+}
+
+func (o *CliOptions) cleanup(ctx context.Context) error {
+	entries, err := journal.List()
+	if err != nil {
+		return err
+	}
+	manifests := make(map[string]*manifest.Manifest)
+	inUse, err := externalResourcesInUse(o.workspaces(entries), manifests)
+	if err != nil {
+		return err
+	}
+	var leaked, failed, skipped int
+	for _, entry := range entries {
+		component := entry.Component()
+		if o.Provider != "" && adapter.Provider(component.Kind) != o.Provider {
+			continue
+		}
+		brokerManifest, err := readManifest(filepath.Join(entry.Workspace, entry.Broker, triggermesh.ManifestFile), manifests)
+		if err != nil {
+			return fmt.Errorf("broker %q: %w", entry.Broker, err)
+		}
+		if referenced(brokerManifest, entry.Broker, component) {
+			continue
+		}
+		resources := component.Metadata.Annotations[triggermesh.ExternalResourcesAnnotation]
+		// component moved with its workspace is still in use
+		if resources != "" && inUse[component.Kind+"/"+resources] {
+			continue
+		}
+		workspace := ""
+		if entry.Workspace != o.Config.ConfigHome {
+			workspace = fmt.Sprintf(" [%s]", entry.Workspace)
+		}
+		// the workspace may have been moved to the unknown location
+		if _, err := os.Stat(entry.Workspace); os.IsNotExist(err) && !o.Force {
+			skipped++
+			if o.DryRun {
+				fmt.Printf("%s/%s (%s)%s: %s (workspace is missing, requires --force)\n", entry.Broker, component.Metadata.Name, component.Kind, workspace, resources)
+			}
+			continue
+		}
+		leaked++
+		if o.DryRun {
+			fmt.Printf("%s/%s (%s)%s: %s\n", entry.Broker, component.Metadata.Name, component.Kind, workspace, resources)
+			continue
+		}
+		log.Printf("Finalizing %s/%s", entry.Broker, component.Metadata.Name)
+		if err := o.finalize(ctx, entry); err != nil {
+			log.Printf("Finalizing %s/%s: %v", entry.Broker, component.Metadata.Name, err)
+			failed++
+			continue
+		}
+		if err := journal.Forget(entry.Workspace, entry.Broker, component.Kind, component.Metadata.Name); err != nil {
+			return fmt.Errorf("updating journal: %w", err)
+		}
+	}
+	switch {
+	case leaked == 0 && skipped == 0:
+		fmt.Println("No leaked external resources found")
+	case failed != 0:
+		return fmt.Errorf("%d of %d components are not finalized, fix the errors and run the command again", failed, leaked)
+	case skipped != 0 && !o.DryRun:
+		return fmt.Errorf("%d components of the missing workspaces are not finalized, run the command from the moved "+
+			"workspaces to keep their components or use --force if the workspaces were removed", skipped)
+	}
+	return nil
+}
+
+// workspaces returns the configuration directories known to the CLI:
+// the home, the current one and the ones recorded in the journal.
+func (o *CliOptions) workspaces(entries []journal.Entry) []string {
+	workspaces := []string{config.HomeAbsPath(), o.Config.ConfigHome}
+	for _, entry := range entries {
+		if !contains(workspaces, entry.Workspace) {
+			workspaces = append(workspaces, entry.Workspace)
+		}
+	}
+	return workspaces
+}
+
+// externalResourcesInUse returns the kinds and the external resources of
+// the components in all broker manifests of the workspaces.
+func externalResourcesInUse(workspaces []string, manifests map[string]*manifest.Manifest) (map[string]bool, error) {
+	inUse := make(map[string]bool)
+	for _, workspace := range workspaces {
+		dirs, err := os.ReadDir(workspace)
+		if err != nil {
+			continue
+		}
+		for _, dir := range dirs {
+			path := filepath.Join(workspace, dir.Name(), triggermesh.ManifestFile)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			m, err := readManifest(path, manifests)
+			if err != nil {
+				return nil, fmt.Errorf("broker %q: %w", dir.Name(), err)
+			}
+			for _, object := range m.Objects {
+				if resources := object.Metadata.Annotations[triggermesh.ExternalResourcesAnnotation]; resources != "" {
+					inUse[object.Kind+"/"+resources] = true
+				}
+			}
+		}
+	}
+	return inUse, nil
+}
+
+// readManifest returns the cached manifest, manifest of the removed
+// broker or workspace stays empty.
+func readManifest(path string, manifests map[string]*manifest.Manifest) (*manifest.Manifest, error) {
+	if m, exists := manifests[path]; exists {
+		return m, nil
+	}
+	m := manifest.New(path)
+	if _, err := os.Stat(path); err == nil {
+		if err := m.Read(); err != nil {
+			return nil, err
+		}
+	}
+	manifests[path] = m
+	return m, nil
+}
+
+// finalize removes the external resources of the journal entry component.
+func (o *CliOptions) finalize(ctx context.Context, entry journal.Entry) error {
+	if err := entry.Manifest.Resolve(""); err != nil {
+		return err
+	}
+	c, err := components.GetObject(entry.Component().Metadata.Name, o.Config, entry.Manifest, o.CRD)
+	if err != nil {
+		return err
+	}
+	reconcilable, ok := c.(triggermesh.Reconcilable)
+	if !ok {
+		return nil
+	}
+	var secrets map[string]string
+	if parent, ok := c.(triggermesh.Parent); ok {
+		if _, secrets, err = components.ProcessSecrets(parent, entry.Manifest); err != nil {
+			return fmt.Errorf("processing secrets: %w", err)
+		}
+	}
+	return reconcilable.Finalize(ctx, secrets)
+}
+
+// referenced returns true if the component of the broker is in the broker manifest.
+func referenced(m *manifest.Manifest, broker string, component kubernetes.Object) bool {
+	for _, object := range m.Objects {
+		if object.Kind != component.Kind || object.Metadata.Name != component.Metadata.Name {
+			continue
+		}
+		if label, set := object.Metadata.Labels[triggermesh.ContextLabel]; set && label != broker {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -19,28 +19,43 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 
 	"github.com/triggermesh/tmctl/cmd/brokers"
+	"github.com/triggermesh/tmctl/cmd/cleanup"
 	"github.com/triggermesh/tmctl/cmd/config"
 	"github.com/triggermesh/tmctl/cmd/create"
 	"github.com/triggermesh/tmctl/cmd/delete"
 	"github.com/triggermesh/tmctl/cmd/describe"
+	"github.com/triggermesh/tmctl/cmd/diff"
 	"github.com/triggermesh/tmctl/cmd/dump"
+	"github.com/triggermesh/tmctl/cmd/explain"
+	"github.com/triggermesh/tmctl/cmd/history"
+	"github.com/triggermesh/tmctl/cmd/images"
 	import_ "github.com/triggermesh/tmctl/cmd/import"
+	init_ "github.com/triggermesh/tmctl/cmd/init"
 	"github.com/triggermesh/tmctl/cmd/logs"
+	"github.com/triggermesh/tmctl/cmd/restart"
+	"github.com/triggermesh/tmctl/cmd/rollback"
+	"github.com/triggermesh/tmctl/cmd/secrets"
 	"github.com/triggermesh/tmctl/cmd/sendevent"
 	"github.com/triggermesh/tmctl/cmd/start"
 	"github.com/triggermesh/tmctl/cmd/stop"
+	"github.com/triggermesh/tmctl/cmd/upgrade"
 	"github.com/triggermesh/tmctl/cmd/version"
 	"github.com/triggermesh/tmctl/cmd/watch"
 
 	cliconfig "github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/file"
 	"github.com/triggermesh/tmctl/pkg/log"
 	"github.com/triggermesh/tmctl/pkg/manifest"
+	tmsecrets "github.com/triggermesh/tmctl/pkg/secrets"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
 )
 
@@ -56,7 +71,9 @@ Find more information at: https://docs.triggermesh.io`,
 
 	c, err := cliconfig.New()
 	cobra.CheckErr(err)
-	crds, err := crd.Fetch(c.ConfigHome, c.Triggermesh.ComponentsVersion)
+	restrictPermissions(c.ConfigHome)
+	tmsecrets.SetKeyFile(filepath.Join(cliconfig.HomeAbsPath(), tmsecrets.KeyFile))
+	crds, err := crd.Fetch(c.CacheHome, c.Triggermesh.ComponentsVersion)
 	cobra.CheckErr(err)
 
 	manifest := manifest.New(filepath.Join(
@@ -66,21 +83,38 @@ Find more information at: https://docs.triggermesh.io`,
 	_ = manifest.Read()
 
 	rootCmd.AddCommand(brokers.NewCmd(c))
+	rootCmd.AddCommand(cleanup.NewCmd(c, crds))
 	rootCmd.AddCommand(create.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(config.NewCmd())
 	rootCmd.AddCommand(delete.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(describe.NewCmd(c, manifest, crds))
+	rootCmd.AddCommand(diff.NewCmd(c, manifest))
 	rootCmd.AddCommand(dump.NewCmd(c, manifest, crds))
+	rootCmd.AddCommand(explain.NewCmd(c, crds))
+	rootCmd.AddCommand(history.NewCmd(c))
+	rootCmd.AddCommand(images.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(import_.NewCmd(c, crds))
+	rootCmd.AddCommand(init_.NewCmd())
 	rootCmd.AddCommand(logs.NewCmd(c, manifest, crds))
+	rootCmd.AddCommand(restart.NewCmd(c, manifest, crds))
+	rootCmd.AddCommand(rollback.NewCmd(c, manifest, crds))
+	rootCmd.AddCommand(secrets.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(sendevent.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(start.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(stop.NewCmd(c, manifest))
+	rootCmd.AddCommand(upgrade.NewCmd(c, manifest, crds))
 	rootCmd.AddCommand(watch.NewCmd(c))
 	rootCmd.AddCommand(version.NewCmd(ver, commit, c))
 
 	rootCmd.PersistentFlags().StringVar(&c.Triggermesh.ComponentsVersion, "version", c.Triggermesh.ComponentsVersion, "TriggerMesh components version.")
 	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("version", cobra.NoFileCompletions))
+	rootCmd.PersistentFlags().StringVar(&c.Docker.Pull, "pull", c.Docker.Pull, "Images pull policy: always, missing or never.")
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("pull", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(docker.PullAlways), string(docker.PullMissing), string(docker.PullNever)}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	rootCmd.PersistentFlags().StringVar(&c.Docker.Mirror, "registry-mirror", c.Docker.Mirror, "Registry that replaces "+cliconfig.TriggerMeshRegistry+" in the TriggerMesh images.")
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("registry-mirror", cobra.NoFileCompletions))
+	docker.SetConfig(&c.Docker)
 
 	if os.Getenv("TMCTL_GENERATE_DOCS") == "true" {
 		rootCmd.DisableAutoGenTag = true
@@ -91,3 +125,43 @@ Find more information at: https://docs.triggermesh.io`,
 	}
 	return rootCmd
 }
+
+// restrictPermissions removes the group and others access from
+// the configuration files created by the previous CLI versions and
+// moves their broker configuration to the configuration directory.
+func restrictPermissions(configHome string) {
+	var restricted []string
+	restrict := func(path string, perm os.FileMode) {
+		changed, err := file.Restrict(path, perm)
+		if err != nil {
+			log.Printf("WARNING: %s is accessible by other users, restrict its permissions to %o: %v", path, perm, err)
+			return
+		}
+		if changed {
+			restricted = append(restricted, path)
+		}
+	}
+	restrict(configHome, file.DirPerm)
+	restrict(filepath.Join(configHome, "config.yaml"), file.PrivatePerm)
+	entries, _ := os.ReadDir(configHome)
+	for _, entry := range entries {
+		brokerHome := filepath.Join(configHome, entry.Name())
+		if _, err := os.Stat(filepath.Join(brokerHome, triggermesh.ManifestFile)); err != nil {
+			continue
+		}
+		restrict(brokerHome, file.DirPerm)
+		// overlays may have secrets too
+		manifests, _ := filepath.Glob(filepath.Join(brokerHome, "manifest*.yaml"))
+		for _, m := range manifests {
+			restrict(m, file.PrivatePerm)
+		}
+		if err := tmbroker.MigrateConfig(brokerHome); err != nil {
+			log.Printf("WARNING: moving %s broker configuration: %v", entry.Name(), err)
+		}
+		restrict(filepath.Join(brokerHome, triggermesh.BrokerConfigDir), file.DirPerm)
+		restrict(filepath.Join(brokerHome, triggermesh.BrokerConfigFile), file.PrivatePerm)
+	}
+	if len(restricted) != 0 {
+		log.Printf("Restricted permissions of the configuration files: %s", strings.Join(restricted, ", "))
+	}
+}
@@ -39,15 +39,14 @@ func (o *CliOptions) newBrokerCmd() *cobra.Command {
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return o.broker(args[0], version)
+			return o.broker(cmd.Context(), args[0], version)
 		},
 	}
 	brokerCmd.Flags().StringVar(&version, "version", o.Config.Triggermesh.Broker.Version, "TriggerMesh broker version.")
 	return brokerCmd
 }
 
-func (o *CliOptions) broker(name, version string) error {
-	ctx := context.Background()
+func (o *CliOptions) broker(ctx context.Context, name, version string) error {
 	o.Manifest.Path = filepath.Join(o.Config.ConfigHome, name, triggermesh.ManifestFile)
 	if _, err := os.Stat(o.Manifest.Path); !os.IsNotExist(err) {
 		return fmt.Errorf("broker %q already exists", name)
@@ -60,7 +59,7 @@ func (o *CliOptions) broker(name, version string) error {
 	brokerConfig := o.Config.Triggermesh.Broker
 	brokerConfig.Version = version
 
-	broker, err := tmbroker.New(name, brokerConfig)
+	broker, err := tmbroker.New(name, o.Config.ConfigHome, brokerConfig)
 	if err != nil {
 		return fmt.Errorf("broker: %w", err)
 	}
@@ -18,22 +18,28 @@ package create
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/triggermesh/tmctl/pkg/completion"
 	"github.com/triggermesh/tmctl/pkg/config"
 	"github.com/triggermesh/tmctl/pkg/docker"
 	"github.com/triggermesh/tmctl/pkg/manifest"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
 type CliOptions struct {
 	Config   *config.Config
 	Manifest *manifest.Manifest
 	CRD      map[string]crd.CRD
+
+	annotations map[string]string
 }
 
 func NewCmd(config *config.Config, manifest *manifest.Manifest, crds map[string]crd.CRD) *cobra.Command {
@@ -47,8 +53,10 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crds map[string]
 		Short: "Create TriggerMesh component",
 		// CompletionOptions: cobra.CompletionOptions{DisableDescriptions: true},
 		Args: cobra.MinimumNArgs(1),
-		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
-			cobra.CheckErr(docker.CheckDaemon())
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if !isDryRun(args) {
+				cobra.CheckErr(docker.CheckDaemon())
+			}
 			if cmd.Name() != "broker" {
 				cobra.CheckErr(o.Manifest.Read())
 			}
@@ -62,26 +70,255 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crds map[string]
 	return createCmd
 }
 
-func argsToMap(args []string) map[string]string {
+// isDryRun returns true if the raw arguments of the command with
+// disabled flags parsing have the dry-run flag.
+func isDryRun(args []string) bool {
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			return true
+		}
+	}
+	return false
+}
+
+// repeatableFlags are the runtime flags that may be set multiple
+// times, their values are collected instead of being overwritten.
+var repeatableFlags = map[string]bool{
+	"env":   true,
+	"mount": true,
+}
+
+// argsToMap converts the arguments into the parameters map. Values
+// of the repeatable flags are returned separately in the order they
+// are set in.
+func argsToMap(args []string) (map[string]string, map[string][]string) {
 	result := make(map[string]string)
+	repeated := make(map[string][]string)
 	for k := 0; k < len(args); k++ {
-		if isFlag(args[k]) {
-			key := args[k]
-			var value string
-			if kv := strings.Split(args[k], "="); len(kv) == 2 {
-				key = kv[0]
-				value = kv[1]
+		if !isFlag(args[k]) {
+			continue
+		}
+		key, value, hasValue := strings.Cut(args[k], "=")
+		key = strings.TrimLeft(key, "-")
+		var values []string
+		if hasValue {
+			values = append(values, value)
+		}
+		for j := k + 1; j < len(args) && !isFlag(args[j]); j++ {
+			values = append(values, args[j])
+			k = j
+		}
+		if repeatableFlags[key] {
+			repeated[key] = append(repeated[key], values...)
+			continue
+		}
+		result[key] = strings.TrimSpace(strings.Join(values, " "))
+	}
+	return result, repeated
+}
+
+// parseRuntimeParams removes the component runtime parameters from
+// the arguments and stores them as the object annotations. Arguments
+// that match the component spec attributes are left untouched.
+func (o *CliOptions) parseRuntimeParams(params map[string]string, repeated map[string][]string, c crd.CRD) error {
+	var spec map[string]crd.Property
+	if len(c.Spec.Versions) != 0 {
+		_, spec = completion.SpecFromCRD(c)
+	}
+	if policy, exists := params["restart-policy"]; exists {
+		if err := o.setRestartPolicy(policy); err != nil {
+			return err
+		}
+		delete(params, "restart-policy")
+	}
+	if port, exists := params["port"]; exists {
+		if _, reserved := spec["port"]; !reserved {
+			if err := o.setHostPort(port); err != nil {
+				return err
 			}
-			key = strings.TrimLeft(key, "-")
-			for j := k + 1; j < len(args) && !isFlag(args[j]); j++ {
-				value = fmt.Sprintf("%s %s", value, args[j])
-				k = j
+			delete(params, "port")
+		}
+	}
+	if resources, exists := params["resources"]; exists {
+		if _, reserved := spec["resources"]; !reserved {
+			if err := o.setResources(resources); err != nil {
+				return err
 			}
-			result[key] = strings.TrimSpace(value)
-			continue
+			delete(params, "resources")
+		}
+	}
+	for key := range repeatableFlags {
+		if _, reserved := spec[key]; reserved {
+			if values, exists := repeated[key]; exists {
+				params[key] = strings.Join(values, " ")
+			}
+		}
+	}
+	if env, exists := repeated["env"]; exists {
+		if _, reserved := spec["env"]; !reserved {
+			if err := o.setEnv(env); err != nil {
+				return err
+			}
+		}
+	}
+	if credentials, exists := params["credentials"]; exists {
+		if _, reserved := spec["credentials"]; !reserved {
+			if err := o.setCredentials(credentials, c.Spec.Names.Kind, params); err != nil {
+				return err
+			}
+			delete(params, "credentials")
+		}
+	}
+	if _, reserved := spec["endpoint"]; !reserved {
+		endpoints := make(map[string]string)
+		for key, value := range params {
+			if strings.HasPrefix(key, "endpoint.") {
+				endpoints[strings.TrimPrefix(key, "endpoint.")] = value
+				delete(params, key)
+			}
+		}
+		if len(endpoints) != 0 {
+			if err := o.setEndpoints(endpoints); err != nil {
+				return err
+			}
+		}
+	}
+	if mounts, exists := repeated["mount"]; exists {
+		if _, reserved := spec["mount"]; !reserved {
+			if err := o.setMounts(mounts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *CliOptions) setResources(value string) error {
+	resources, err := adapter.ParseResources(value)
+	if err != nil {
+		return fmt.Errorf("resources: %w", err)
+	}
+	o.setAnnotation(triggermesh.ResourcesAnnotation, adapter.FormatResources(resources))
+	return nil
+}
+
+// setEnv stores the KEY=VALUE variables that override the container environment.
+func (o *CliOptions) setEnv(values []string) error {
+	envs, err := adapter.ParseEnv(strings.Join(values, "\n"))
+	if err != nil {
+		return fmt.Errorf("env: %w", err)
+	}
+	o.setAnnotation(triggermesh.EnvAnnotation, adapter.FormatEnv(envs))
+	return nil
+}
+
+// setMounts stores the host:container binds, relative host paths
+// are resolved against the working directory.
+func (o *CliOptions) setMounts(values []string) error {
+	mounts, err := adapter.ParseMounts(strings.Join(values, "\n"))
+	if err != nil {
+		return fmt.Errorf("mount: %w", err)
+	}
+	for i, m := range mounts {
+		if mounts[i].Host, err = filepath.Abs(m.Host); err != nil {
+			return fmt.Errorf("mount %q: %w", m.Host, err)
+		}
+	}
+	o.setAnnotation(triggermesh.MountsAnnotation, adapter.FormatMounts(mounts))
+	return nil
+}
+
+// setCredentials selects the credentials of the cloud component:
+// "native" is the developer credentials chain, "secret" is the default.
+// Azure components use the Azure CLI login for the external resources
+// only, their adapters still need the spec auth credentials.
+func (o *CliOptions) setCredentials(value, kind string, params map[string]string) error {
+	switch value {
+	case triggermesh.NativeCredentials:
+		switch adapter.Provider(kind) {
+		case "":
+			return fmt.Errorf("credentials: %q is not a cloud component", kind)
+		case adapter.ProviderAzure:
+			if !hasPrefix(params, "auth.") {
+				return fmt.Errorf("credentials: %w", adapter.ErrAzureNativeCredentials)
+			}
+		}
+	case "secret":
+	default:
+		return fmt.Errorf("credentials: unsupported value %q, expected \"native\" or \"secret\"", value)
+	}
+	o.setAnnotation(triggermesh.CredentialsAnnotation, value)
+	return nil
+}
+
+// setEndpoints stores the service endpoint overrides, e.g. the emulators.
+func (o *CliOptions) setEndpoints(endpoints map[string]string) error {
+	value := pkg.FormatEndpoints(endpoints)
+	if _, err := pkg.ParseEndpoints(value); err != nil {
+		return fmt.Errorf("endpoint: %w", err)
+	}
+	o.setAnnotation(triggermesh.EndpointsAnnotation, value)
+	return nil
+}
+
+func (o *CliOptions) setHostPort(port string) error {
+	if _, err := pkg.ParsePort(port); err != nil {
+		return err
+	}
+	o.setAnnotation(triggermesh.HostPortAnnotation, port)
+	return nil
+}
+
+func (o *CliOptions) setRestartPolicy(policy string) error {
+	if _, err := docker.ParseRestartPolicy(policy); err != nil {
+		return err
+	}
+	o.setAnnotation(triggermesh.RestartPolicyAnnotation, policy)
+	return nil
+}
+
+// annotate sets the runtime parameters on the component. Parameters of the
+// existing component are preserved unless they are overridden.
+func (o *CliOptions) annotate(c triggermesh.Component) error {
+	annotations := make(map[string]string, len(o.annotations))
+	for _, object := range o.Manifest.Objects {
+		if object.Metadata.Name == c.GetName() && object.Kind == c.GetKind() {
+			for k, v := range object.Metadata.Annotations {
+				annotations[k] = v
+			}
+			break
+		}
+	}
+	for k, v := range o.annotations {
+		annotations[k] = v
+	}
+	if port, set := o.annotations[triggermesh.HostPortAnnotation]; set {
+		for _, object := range o.Manifest.Objects {
+			if object.Metadata.Name != c.GetName() &&
+				object.Metadata.Annotations[triggermesh.HostPortAnnotation] == port {
+				return fmt.Errorf("port %s is already assigned to %q", port, object.Metadata.Name)
+			}
+		}
+	}
+	components.Annotate(c, annotations)
+	return nil
+}
+
+func (o *CliOptions) setAnnotation(key, value string) {
+	if o.annotations == nil {
+		o.annotations = make(map[string]string, 1)
+	}
+	o.annotations[key] = value
+}
+
+// hasPrefix returns true if any of the params keys has the prefix.
+func hasPrefix(params map[string]string, prefix string) bool {
+	for key := range params {
+		if strings.HasPrefix(key, prefix) {
+			return true
 		}
 	}
-	return result
+	return false
 }
 
 func isFlag(s string) bool {
@@ -29,13 +29,28 @@ import (
 // Completion functions are responsible for the logic
 // behind the CLI commands autocompletion.
 
+// runtimeParamsCompletion lists the component container parameters
+// that are stored in the manifest annotations.
+var runtimeParamsCompletion = []string{
+	"--port\tHost port of the component container.",
+	"--restart-policy\tContainer restart policy: never, always or on-failure[:max-retries].",
+	"--resources\tContainer resource limits, e.g. cpu=1,memory=512Mi.",
+	"--env\tContainer environment override in KEY=VALUE format.",
+	"--mount\tHost path bind in host:container[:ro] format.",
+	"--credentials\tCloud credentials: native for the developer credentials chain or secret.",
+	"--endpoint.aws\tAWS services endpoint URL, e.g. the LocalStack address.",
+	"--endpoint.pubsub\tGoogle Cloud Pub/Sub emulator host:port.",
+	"--endpoint.storage\tGoogle Cloud Storage emulator host:port.",
+	"--pin\tPin the component to the components version, see --version.",
+}
+
 func (o *CliOptions) sourcesCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) == 0 {
 		sources, err := crd.ListSources(o.CRD)
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
-		return append(sources, "--from-image"), cobra.ShellCompDirectiveNoFileComp
+		return append(sources, "--from-image", "--interactive"), cobra.ShellCompDirectiveNoFileComp
 	}
 	if toComplete == "--name" ||
 		toComplete == "--from-image" {
@@ -46,10 +61,10 @@ func (o *CliOptions) sourcesCompletion(cmd *cobra.Command, args []string, toComp
 	}
 	for _, arg := range args {
 		if arg == "--from-image" {
-			return []string{
+			return append([]string{
 				"--ce_type\tCE Type attribute override.",
 				"--name\tOptional component name.",
-			}, cobra.ShellCompDirectiveNoFileComp
+			}, runtimeParamsCompletion...), cobra.ShellCompDirectiveNoFileComp
 		}
 	}
 
@@ -96,7 +111,9 @@ func (o *CliOptions) sourcesCompletion(cmd *cobra.Command, args []string, toComp
 		name = prefix + name
 		spec = append(spec, fmt.Sprintf("--%s\t(%s) %s", name, attr, property.Description))
 	}
-	return append(spec, "--name\tOptional component name."), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	spec = append(spec, "--name\tOptional component name.")
+	spec = append(spec, "--dry-run\tPrint the external resources that would be created or modified.")
+	return append(spec, runtimeParamsCompletion...), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 }
 
 func (o *CliOptions) targetsCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -105,7 +122,7 @@ func (o *CliOptions) targetsCompletion(cmd *cobra.Command, args []string, toComp
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
-		return append(targets, "--from-image"), cobra.ShellCompDirectiveNoFileComp
+		return append(targets, "--from-image", "--interactive"), cobra.ShellCompDirectiveNoFileComp
 	}
 
 	if lastParam(args) == "--source" && strings.HasSuffix(args[len(args)-1], ",") {
@@ -177,11 +194,12 @@ func (o *CliOptions) targetsCompletion(cmd *cobra.Command, args []string, toComp
 		name = prefix + name
 		spec = append(spec, fmt.Sprintf("--%s\t(%s) %s", name, attr, property.Description))
 	}
-	return append(spec,
+	spec = append(spec,
 		"--source\tEvent source name.",
 		"--eventTypes\tEvent types filter.",
 		"--name\tOptional component name.",
-	), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	)
+	return append(spec, runtimeParamsCompletion...), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 }
 
 func lastParam(args []string) string {
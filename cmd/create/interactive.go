@@ -0,0 +1,79 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/triggermesh/tmctl/pkg/gui/wizard"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+var errCancelled = fmt.Errorf("cancelled")
+
+// interactive prompts the component kind, name and spec values
+// that are not set in the arguments. The group is either "source" or "target".
+func (o *CliOptions) interactive(group, kind, name string, params map[string]string) (string, string, map[string]string, error) {
+	w := wizard.New(os.Stdin, os.Stdout)
+	if kind == "" {
+		list := crd.ListSources
+		if group == "target" {
+			list = crd.ListTargets
+		}
+		kinds, err := list(o.CRD)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("list %ss: %w", group, err)
+		}
+		if kind, err = w.Choose(fmt.Sprintf("Select %s kind", group), kinds); err != nil {
+			return "", "", nil, err
+		}
+	}
+	c, exists := o.CRD[kind+group]
+	if !exists {
+		return "", "", nil, fmt.Errorf("CRD for kind %q not found", kind)
+	}
+	schema, err := crd.ServedSchema(c)
+	if err != nil {
+		return "", "", nil, err
+	}
+	spec, err := schema.Explain()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if name == "" {
+		if name, err = w.Input(fmt.Sprintf("%s name [optional]", c.Spec.Names.Kind)); err != nil {
+			return "", "", nil, err
+		}
+	}
+	if params, err = w.Spec(spec, params); err != nil {
+		return "", "", nil, err
+	}
+	preview, err := wizard.Preview(spec, params)
+	if err != nil {
+		return "", "", nil, err
+	}
+	fmt.Printf("\n%s spec:\n---\n%s---\n", c.Spec.Names.Kind, preview)
+	create, err := w.Confirm(fmt.Sprintf("Create %s?", c.Spec.Names.Kind), true)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !create {
+		return "", "", nil, errCancelled
+	}
+	return kind, name, params, nil
+}
@@ -36,13 +36,17 @@ import (
 
 func (o *CliOptions) newSourceCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "source [kind]/[--from-image <image>][--name <name>]",
+		Use:   "source [kind]/[--from-image <image>][--name <name>][--port <port>][--restart-policy <policy>][--resources <limits>][--env <KEY=VALUE>...][--mount <host:container>...][--credentials native|secret][--endpoint.<service> <endpoint>...][--pin][--interactive][--dry-run]",
 		Short: "Create TriggerMesh source. More information at https://docs.triggermesh.io",
 		Example: `tmctl create source httppoller \
 	--endpoint https://www.example.com \
 	--eventType sample-event \
 	--interval 30s  \
-	--method GET`,
+	--method GET
+
+tmctl create source awss3 --interactive
+
+tmctl create source awss3 --arn arn:aws:s3:::bucket --auth.credentials.accessKeyID <key> --auth.credentials.secretAccessKey <secret> --dry-run`,
 		DisableFlagParsing: true,
 		SilenceErrors:      true,
 		ValidArgsFunction:  o.sourcesCompletion,
@@ -58,7 +62,9 @@ func (o *CliOptions) newSourceCmd() *cobra.Command {
 				fmt.Printf("\nAvailable source kinds:\n---\n%s\n", strings.Join(sources, "\n"))
 				return nil
 			}
-			params := argsToMap(args)
+			params, repeated := argsToMap(args)
+			_, dryRun := params["dry-run"]
+			delete(params, "dry-run")
 			var name string
 			if n, exists := params["name"]; exists {
 				name = n
@@ -68,12 +74,33 @@ func (o *CliOptions) newSourceCmd() *cobra.Command {
 				o.Config.Triggermesh.ComponentsVersion = v
 				delete(params, "version")
 			}
-			crd, err := crd.Fetch(o.Config.ConfigHome, o.Config.Triggermesh.ComponentsVersion)
+			if _, pin := params["pin"]; pin {
+				o.setAnnotation(triggermesh.VersionAnnotation, o.Config.Triggermesh.ComponentsVersion)
+				delete(params, "pin")
+			}
+			crd, err := crd.Fetch(o.Config.CacheHome, o.Config.Triggermesh.ComponentsVersion)
 			if err != nil {
 				return err
 			}
 			// defer crd.Close()
 			o.CRD = crd
+			kind := args[0]
+			if _, exists := params["interactive"]; exists {
+				delete(params, "interactive")
+				if isFlag(kind) {
+					kind = ""
+				}
+				if kind, name, params, err = o.interactive("source", kind, name, params); err != nil {
+					if err == errCancelled {
+						log.Println("Cancelled")
+						return nil
+					}
+					return err
+				}
+			}
+			if err := o.parseRuntimeParams(params, repeated, o.CRD[kind+"source"]); err != nil {
+				return err
+			}
 
 			if _, readDisabled := params["disable-file-args"]; !readDisabled {
 				for key, value := range params {
@@ -87,17 +114,23 @@ func (o *CliOptions) newSourceCmd() *cobra.Command {
 				delete(params, "disable-file-args")
 			}
 			if image, exists := params["from-image"]; exists {
+				if dryRun {
+					fmt.Println("No external resources would be created or modified")
+					return nil
+				}
 				delete(params, "from-image")
-				return o.sourceFromImage(name, image, params)
+				return o.sourceFromImage(cmd.Context(), name, image, params)
+			}
+			if dryRun {
+				return o.sourcePlan(name, kind, params)
 			}
-			return o.source(name, args[0], params)
+			return o.source(cmd.Context(), name, kind, params)
 		},
 	}
 }
 
-func (o *CliOptions) source(name, kind string, params map[string]string) error {
-	ctx := context.Background()
-	broker, err := tmbroker.New(o.Config.Context, o.Config.Triggermesh.Broker)
+func (o *CliOptions) source(ctx context.Context, name, kind string, params map[string]string) error {
+	broker, err := tmbroker.New(o.Config.Context, o.Config.ConfigHome, o.Config.Triggermesh.Broker)
 	if err != nil {
 		return fmt.Errorf("broker object: %v", err)
 	}
@@ -112,6 +145,10 @@ func (o *CliOptions) source(name, kind string, params map[string]string) error {
 		return fmt.Errorf("CRD for kind %q not found", kind)
 	}
 	s := source.New(name, kind, o.Config.Context, o.Config.Triggermesh.ComponentsVersion, crd, params, nil)
+	if err := o.annotate(s); err != nil {
+		return err
+	}
+	tx := o.begin(s)
 
 	secrets, secretsEnv, err := components.ProcessSecrets(s.(triggermesh.Parent), o.Manifest)
 	if err != nil {
@@ -123,34 +160,63 @@ func (o *CliOptions) source(name, kind string, params map[string]string) error {
 	for _, secret := range secrets {
 		dirty, err := o.Manifest.Add(secret)
 		if err != nil {
-			return fmt.Errorf("unable to write secret: %w", err)
+			return tx.rollback(fmt.Errorf("unable to write secret: %w", err))
 		}
 		if dirty {
 			secretsChanged = true
 		}
 	}
 
-	status, err := s.(triggermesh.Reconcilable).Initialize(ctx, secretsEnv)
-	if err != nil {
-		return fmt.Errorf("source initialization: %w", err)
+	if err := tx.initialize(ctx, secretsEnv); err != nil {
+		return tx.rollback(fmt.Errorf("source initialization: %w", err))
 	}
-	s.(triggermesh.Reconcilable).UpdateStatus(status)
 
 	restart, err := o.Manifest.Add(s)
 	if err != nil {
-		return fmt.Errorf("unable to update manifest: %w", err)
+		return tx.rollback(fmt.Errorf("unable to update manifest: %w", err))
 	}
 	log.Println("Starting container")
-	if _, err := s.(triggermesh.Runnable).Start(ctx, secretsEnv, (restart || secretsChanged)); err != nil {
-		return err
+	container, err := s.(triggermesh.Runnable).Start(ctx, secretsEnv, (restart || secretsChanged))
+	if err != nil {
+		return tx.rollback(err)
+	}
+	if err := components.PinHostPort(s, container, o.Manifest); err != nil {
+		return tx.rollback(err)
 	}
 	output.PrintStatus("producer", s, []string{}, []string{})
 	return nil
 }
 
-func (o *CliOptions) sourceFromImage(name, image string, params map[string]string) error {
-	ctx := context.Background()
-	broker, err := tmbroker.New(o.Config.Context, o.Config.Triggermesh.Broker)
+// sourcePlan prints the external resources that the source initialization
+// would create or modify, the manifest and the containers are not touched.
+func (o *CliOptions) sourcePlan(name, kind string, params map[string]string) error {
+	crd, exists := o.CRD[kind+"source"]
+	if !exists {
+		return fmt.Errorf("CRD for kind %q not found", kind)
+	}
+	// the broker may be offline, the sink is not a part of the plan
+	params["sink.uri"] = "http://host.docker.internal"
+	s := source.New(name, kind, o.Config.Context, o.Config.Triggermesh.ComponentsVersion, crd, params, nil)
+	if err := o.annotate(s); err != nil {
+		return err
+	}
+	// secrets are moved out of the spec the same way as on creation,
+	// their values are not needed for the plan
+	_, _ = s.(triggermesh.Parent).GetChildren()
+	changes, err := s.(triggermesh.Reconcilable).Plan()
+	if err != nil {
+		return fmt.Errorf("source external resources: %w", err)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No external resources would be created or modified")
+		return nil
+	}
+	output.PrintPlan(s, changes)
+	return nil
+}
+
+func (o *CliOptions) sourceFromImage(ctx context.Context, name, image string, params map[string]string) error {
+	broker, err := tmbroker.New(o.Config.Context, o.Config.ConfigHome, o.Config.Triggermesh.Broker)
 	if err != nil {
 		return fmt.Errorf("broker object: %v", err)
 	}
@@ -161,15 +227,23 @@ func (o *CliOptions) sourceFromImage(name, image string, params map[string]strin
 	params["K_SINK"] = "http://host.docker.internal:" + port
 
 	s := service.New(name, image, o.Config.Context, service.Producer, params)
+	if err := o.annotate(s); err != nil {
+		return err
+	}
+	tx := o.begin(s)
 
 	log.Println("Updating manifest")
 	restart, err := o.Manifest.Add(s)
 	if err != nil {
-		return fmt.Errorf("unable to update manifest: %w", err)
+		return tx.rollback(fmt.Errorf("unable to update manifest: %w", err))
 	}
 	log.Println("Starting container")
-	if _, err := s.(triggermesh.Runnable).Start(ctx, nil, restart); err != nil {
-		return err
+	container, err := s.(triggermesh.Runnable).Start(ctx, nil, restart)
+	if err != nil {
+		return tx.rollback(err)
+	}
+	if err := components.PinHostPort(s, container, o.Manifest); err != nil {
+		return tx.rollback(err)
 	}
 	output.PrintStatus("producer", s, []string{}, []string{})
 	return nil
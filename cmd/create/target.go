@@ -38,12 +38,14 @@ import (
 
 func (o *CliOptions) newTargetCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "target [kind]/[--from-image <image>][--name <name>][--source <name>...][--eventTypes <type>...]",
+		Use:   "target [kind]/[--from-image <image>][--name <name>][--port <port>][--restart-policy <policy>][--resources <limits>][--env <KEY=VALUE>...][--mount <host:container>...][--credentials native|secret][--endpoint.<service> <endpoint>...][--pin][--interactive][--source <name>...][--eventTypes <type>...]",
 		Short: "Create TriggerMesh target. More information at https://docs.triggermesh.io",
 		Example: `tmctl create target http \
 	--endpoint https://image-charts.com \
 	--method GET \
-	--response.eventType qr-data.response`,
+	--response.eventType qr-data.response
+
+tmctl create target --interactive`,
 		DisableFlagParsing: true,
 		SilenceErrors:      true,
 		ValidArgsFunction:  o.targetsCompletion,
@@ -58,7 +60,7 @@ func (o *CliOptions) newTargetCmd() *cobra.Command {
 				fmt.Printf("\nAvailable target kinds:\n---\n%s\n", strings.Join(targets, "\n"))
 				return nil
 			}
-			params := argsToMap(args[0:])
+			params, repeated := argsToMap(args[0:])
 			var name string
 			if n, exists := params["name"]; exists {
 				name = n
@@ -68,11 +70,32 @@ func (o *CliOptions) newTargetCmd() *cobra.Command {
 				o.Config.Triggermesh.ComponentsVersion = v
 				delete(params, "version")
 			}
-			crd, err := crd.Fetch(o.Config.ConfigHome, o.Config.Triggermesh.ComponentsVersion)
+			if _, pin := params["pin"]; pin {
+				o.setAnnotation(triggermesh.VersionAnnotation, o.Config.Triggermesh.ComponentsVersion)
+				delete(params, "pin")
+			}
+			crd, err := crd.Fetch(o.Config.CacheHome, o.Config.Triggermesh.ComponentsVersion)
 			if err != nil {
 				return err
 			}
 			o.CRD = crd
+			kind := args[0]
+			if _, exists := params["interactive"]; exists {
+				delete(params, "interactive")
+				if isFlag(kind) {
+					kind = ""
+				}
+				if kind, name, params, err = o.interactive("target", kind, name, params); err != nil {
+					if err == errCancelled {
+						log.Println("Cancelled")
+						return nil
+					}
+					return err
+				}
+			}
+			if err := o.parseRuntimeParams(params, repeated, o.CRD[kind+"target"]); err != nil {
+				return err
+			}
 
 			var eventSourcesFilter, eventTypesFilter []string
 			if sf, exists := params["source"]; exists {
@@ -102,16 +125,14 @@ func (o *CliOptions) newTargetCmd() *cobra.Command {
 			}
 			if image, exists := params["from-image"]; exists {
 				delete(params, "from-image")
-				return o.targetFromImage(name, image, params, eventSourcesFilter, eventTypesFilter)
+				return o.targetFromImage(cmd.Context(), name, image, params, eventSourcesFilter, eventTypesFilter)
 			}
-			return o.target(name, args[0], params, eventSourcesFilter, eventTypesFilter)
+			return o.target(cmd.Context(), name, kind, params, eventSourcesFilter, eventTypesFilter)
 		},
 	}
 }
 
-func (o *CliOptions) target(name, kind string, args map[string]string, eventSourcesFilter, eventTypesFilter []string) error {
-	ctx := context.Background()
-
+func (o *CliOptions) target(ctx context.Context, name, kind string, args map[string]string, eventSourcesFilter, eventTypesFilter []string) error {
 	et, err := o.translateEventSource(eventSourcesFilter)
 	if err != nil {
 		return err
@@ -123,6 +144,10 @@ func (o *CliOptions) target(name, kind string, args map[string]string, eventSour
 		return fmt.Errorf("CRD for kind %q not found", kind)
 	}
 	t := target.New(name, kind, o.Config.Context, o.Config.Triggermesh.ComponentsVersion, crd, args)
+	if err := o.annotate(t); err != nil {
+		return err
+	}
+	tx := o.begin(t)
 
 	secrets, secretsEnv, err := components.ProcessSecrets(t.(triggermesh.Parent), o.Manifest)
 	if err != nil {
@@ -134,7 +159,7 @@ func (o *CliOptions) target(name, kind string, args map[string]string, eventSour
 	for _, secret := range secrets {
 		dirty, err := o.Manifest.Add(secret)
 		if err != nil {
-			return fmt.Errorf("unable to write secret: %w", err)
+			return tx.rollback(fmt.Errorf("unable to write secret: %w", err))
 		}
 		if dirty {
 			secretsChanged = true
@@ -142,24 +167,28 @@ func (o *CliOptions) target(name, kind string, args map[string]string, eventSour
 	}
 	restart, err := o.Manifest.Add(t)
 	if err != nil {
-		return fmt.Errorf("unable to update manifest: %w", err)
+		return tx.rollback(fmt.Errorf("unable to update manifest: %w", err))
 	}
 
 	log.Println("Starting container")
-	if _, err := t.(triggermesh.Runnable).Start(ctx, secretsEnv, (restart || secretsChanged)); err != nil {
-		return err
+	container, err := t.(triggermesh.Runnable).Start(ctx, secretsEnv, (restart || secretsChanged))
+	if err != nil {
+		return tx.rollback(err)
+	}
+	if err := components.PinHostPort(t, container, o.Manifest); err != nil {
+		return tx.rollback(err)
 	}
 
 	// update our triggers in case of target container restart
 	if restart || secretsChanged {
 		if err := o.updateTriggers(t); err != nil {
-			return err
+			return tx.rollback(err)
 		}
 	}
 
 	for _, et := range eventTypesFilter {
 		if _, err := o.createTrigger("", t, tmbroker.FilterAttribute("type", et)); err != nil {
-			return fmt.Errorf("creating trigger: %w", err)
+			return tx.rollback(fmt.Errorf("creating trigger: %w", err))
 		}
 	}
 
@@ -195,9 +224,7 @@ func (o *CliOptions) updateTriggers(target triggermesh.Component) error {
 	return nil
 }
 
-func (o *CliOptions) targetFromImage(name, image string, params map[string]string, eventSourcesFilter, eventTypesFilter []string) error {
-	ctx := context.Background()
-
+func (o *CliOptions) targetFromImage(ctx context.Context, name, image string, params map[string]string, eventSourcesFilter, eventTypesFilter []string) error {
 	et, err := o.translateEventSource(eventSourcesFilter)
 	if err != nil {
 		return err
@@ -205,25 +232,33 @@ func (o *CliOptions) targetFromImage(name, image string, params map[string]strin
 	eventTypesFilter = append(eventTypesFilter, et...)
 
 	s := service.New(name, image, o.Config.Context, service.Consumer, params)
+	if err := o.annotate(s); err != nil {
+		return err
+	}
+	tx := o.begin(s)
 
 	log.Println("Updating manifest")
 	restart, err := o.Manifest.Add(s)
 	if err != nil {
-		return fmt.Errorf("unable to update manifest: %w", err)
+		return tx.rollback(fmt.Errorf("unable to update manifest: %w", err))
 	}
 	log.Println("Starting container")
-	if _, err := s.(triggermesh.Runnable).Start(ctx, nil, restart); err != nil {
-		return err
+	container, err := s.(triggermesh.Runnable).Start(ctx, nil, restart)
+	if err != nil {
+		return tx.rollback(err)
+	}
+	if err := components.PinHostPort(s, container, o.Manifest); err != nil {
+		return tx.rollback(err)
 	}
 	// update our triggers in case of target container restart
 	if restart {
 		if err := o.updateTriggers(s); err != nil {
-			return err
+			return tx.rollback(err)
 		}
 	}
 	for _, et := range eventTypesFilter {
 		if _, err := o.createTrigger("", s, tmbroker.FilterAttribute("type", et)); err != nil {
-			return fmt.Errorf("creating trigger: %w", err)
+			return tx.rollback(fmt.Errorf("creating trigger: %w", err))
 		}
 	}
 	output.PrintStatus("consumer", s, eventSourcesFilter, eventTypesFilter)
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/triggermesh/tmctl/pkg/journal"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/log"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
+)
+
+// rollbackTimeout limits the time spent on reverting the changes,
+// command context may be already canceled at this point.
+const rollbackTimeout = 30 * time.Second
+
+// transaction keeps the state of the broker manifest and configuration
+// before the component creation, so that the changes could be reverted
+// if the component fails to initialize or start.
+type transaction struct {
+	manifest         *manifest.Manifest
+	objects          []kubernetes.Object
+	configHome       string
+	brokerHome       string
+	brokerConfigData []byte
+
+	component   triggermesh.Component
+	existed     bool
+	secrets     map[string]string
+	initialized bool
+}
+
+func (o *CliOptions) begin(component triggermesh.Component) *transaction {
+	t := &transaction{
+		manifest:   o.Manifest,
+		objects:    make([]kubernetes.Object, len(o.Manifest.Objects)),
+		configHome: o.Config.ConfigHome,
+		brokerHome: filepath.Join(o.Config.ConfigHome, o.Config.Context),
+		component:  component,
+	}
+	copy(t.objects, o.Manifest.Objects)
+	for _, object := range t.objects {
+		if object.Metadata.Name == component.GetName() && object.Kind == component.GetKind() {
+			t.existed = true
+			break
+		}
+	}
+	// missing config is removed on rollback
+	t.brokerConfigData, _ = os.ReadFile(filepath.Join(t.brokerHome, triggermesh.BrokerConfigFile))
+	return t
+}
+
+// initialize reconciles the external resources of the component
+// and records that they must be finalized on rollback.
+func (t *transaction) initialize(ctx context.Context, secrets map[string]string) error {
+	reconcilable, ok := t.component.(triggermesh.Reconcilable)
+	if !ok {
+		return nil
+	}
+	status, err := reconcilable.Initialize(ctx, secrets)
+	if err != nil {
+		return err
+	}
+	reconcilable.UpdateStatus(status)
+	t.secrets = secrets
+	t.initialized = true
+	if err := journal.Record(t.configHome, t.component, t.manifest); err != nil {
+		log.Printf("Recording %s external resources: %v", t.component.GetName(), err)
+	}
+	return nil
+}
+
+// rollback reverts the changes made since the transaction has begun,
+// prints the summary and returns the cause of the rollback.
+func (t *transaction) rollback(cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	name := t.component.GetName()
+	log.Printf("Creating %s failed, rolling back", name)
+	var undone []string
+	if !t.existed {
+		if runnable, ok := t.component.(triggermesh.Runnable); ok {
+			if err := runnable.Stop(ctx); err == nil {
+				undone = append(undone, fmt.Sprintf("container %q removed", name))
+			}
+		}
+		if t.initialized {
+			if err := t.component.(triggermesh.Reconcilable).Finalize(ctx, t.secrets); err != nil {
+				log.Printf("Removing %s external resources: %v", name, err)
+			} else {
+				undone = append(undone, fmt.Sprintf("%s external resources removed", name))
+				t.forget()
+			}
+		}
+	}
+
+	if err := t.restoreManifest(); err != nil {
+		log.Printf("Restoring manifest: %v", err)
+	} else {
+		undone = append(undone, "manifest restored")
+	}
+
+	if err := t.restoreBrokerConfig(); err != nil {
+		log.Printf("Restoring broker configuration: %v", err)
+	} else {
+		undone = append(undone, "broker configuration restored")
+	}
+
+	for _, item := range undone {
+		log.Printf(" - %s", item)
+	}
+	if t.existed {
+		log.Printf("Previous version of %s is restored in the manifest, run \"tmctl start %s\" to start it again", name, name)
+	}
+	return cause
+}
+
+// forget removes the component from the external resources journal.
+func (t *transaction) forget() {
+	object, err := t.component.AsK8sObject()
+	if err != nil {
+		return
+	}
+	broker := object.Metadata.Labels[triggermesh.ContextLabel]
+	if err := journal.Forget(t.configHome, broker, object.Kind, object.Metadata.Name); err != nil {
+		log.Printf("Updating external resources journal: %v", err)
+	}
+}
+
+// restoreManifest reverts the manifest objects changed by the command one by
+// one, the changes made by the other CLI processes in the meantime are kept.
+func (t *transaction) restoreManifest() error {
+	changes, err := manifest.Diff(t.objects, t.manifest.Objects)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.Change == manifest.Added {
+			if err := t.manifest.Remove(change.Name, change.Kind); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, object := range t.objects {
+			if object.Kind == change.Kind && object.Metadata.Name == change.Name {
+				if _, err := t.manifest.AddObject(object); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (t *transaction) restoreBrokerConfig() error {
+	if t.brokerConfigData == nil {
+		if err := os.Remove(filepath.Join(t.brokerHome, triggermesh.BrokerConfigFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return tmbroker.RestoreConfig(t.brokerHome, t.brokerConfigData)
+}
@@ -67,11 +67,11 @@ https://github.com/triggermesh/triggermesh/tree/main/config/samples/bumblebee`
 )
 
 func (o *CliOptions) newTransformationCmd() *cobra.Command {
-	var name, target, file string
-	var eventSourcesFilter, eventTypesFilter []string
-	var wizard bool
+	var name, target, file, restartPolicy, port, resources string
+	var eventSourcesFilter, eventTypesFilter, env, mounts []string
+	var wizard, pin bool
 	transformationCmd := &cobra.Command{
-		Use:   "transformation [--target <name>][--source <name>...][--eventTypes <type>...][--from <path>][--wizard]",
+		Use:   "transformation [--target <name>][--source <name>...][--eventTypes <type>...][--from <path>][--port <port>][--restart-policy <policy>][--resources <limits>][--env <KEY=VALUE>...][--mount <host:container>...][--pin][--wizard]",
 		Short: "Create TriggerMesh transformation. More information at https://docs.triggermesh.io/transformation/jsontransformation/",
 		Example: `tmctl create transformation <<EOF
   data:
@@ -80,8 +80,36 @@ func (o *CliOptions) newTransformationCmd() *cobra.Command {
     - key: new-field
       value: hello from Transformation!
 EOF`,
-		ValidArgs: []string{"--name", "--target", "--source", "--eventTypes", "--from", "--wizard"},
+		ValidArgs: []string{"--name", "--target", "--source", "--eventTypes", "--from", "--port", "--restart-policy", "--resources", "--env", "--mount", "--pin", "--wizard"},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if restartPolicy != "" {
+				if err := o.setRestartPolicy(restartPolicy); err != nil {
+					return err
+				}
+			}
+			if pin {
+				o.setAnnotation(triggermesh.VersionAnnotation, o.Config.Triggermesh.ComponentsVersion)
+			}
+			if port != "" {
+				if err := o.setHostPort(port); err != nil {
+					return err
+				}
+			}
+			if resources != "" {
+				if err := o.setResources(resources); err != nil {
+					return err
+				}
+			}
+			if len(env) != 0 {
+				if err := o.setEnv(env); err != nil {
+					return err
+				}
+			}
+			if len(mounts) != 0 {
+				if err := o.setMounts(mounts); err != nil {
+					return err
+				}
+			}
 			if wizard {
 				name, sourceEventType, target, spec, err := transformationgui.Create(o.CRD, o.Manifest, o.Config)
 				if err == gocui.ErrQuit {
@@ -90,20 +118,20 @@ EOF`,
 				if err != nil {
 					return fmt.Errorf("transformation wizard error: %w", err)
 				}
-				return o.transformation(name, target, spec, []string{}, []string{sourceEventType})
+				return o.transformation(cmd.Context(), name, target, spec, []string{}, []string{sourceEventType})
 			}
 			if file != "" {
 				data, err := os.ReadFile(file)
 				if err != nil {
 					return fmt.Errorf("file %q read: %w", file, err)
 				}
-				return o.transformation(name, target, bytes.NewBuffer(data), eventSourcesFilter, eventTypesFilter)
+				return o.transformation(cmd.Context(), name, target, bytes.NewBuffer(data), eventSourcesFilter, eventTypesFilter)
 			}
-			return o.transformation(name, target, nil, eventSourcesFilter, eventTypesFilter)
+			return o.transformation(cmd.Context(), name, target, nil, eventSourcesFilter, eventTypesFilter)
 		},
 	}
 
-	crd, err := crd.Fetch(o.Config.ConfigHome, o.Config.Triggermesh.ComponentsVersion)
+	crd, err := crd.Fetch(o.Config.CacheHome, o.Config.Triggermesh.ComponentsVersion)
 	cobra.CheckErr(err)
 	o.CRD = crd
 
@@ -112,6 +140,12 @@ EOF`,
 	transformationCmd.Flags().StringVar(&target, "target", "", "Target name")
 	transformationCmd.Flags().StringSliceVar(&eventSourcesFilter, "source", []string{}, "Sources component names")
 	transformationCmd.Flags().StringSliceVar(&eventTypesFilter, "eventTypes", []string{}, "Event types filter")
+	transformationCmd.Flags().StringVar(&port, "port", "", "Host port of the transformation container")
+	transformationCmd.Flags().StringVar(&restartPolicy, "restart-policy", "", "Container restart policy: never, always or on-failure[:max-retries]")
+	transformationCmd.Flags().StringVar(&resources, "resources", "", "Container resource limits, e.g. cpu=1,memory=512Mi")
+	transformationCmd.Flags().StringArrayVar(&env, "env", []string{}, "Container environment override in KEY=VALUE format")
+	transformationCmd.Flags().StringArrayVar(&mounts, "mount", []string{}, "Host path bind in host:container[:ro] format")
+	transformationCmd.Flags().BoolVar(&pin, "pin", false, "Pin the transformation to the components version, see --version")
 
 	transformationCmd.Flags().BoolVar(&wizard, "wizard", false, "Experimental transformation wizard")
 
@@ -128,8 +162,7 @@ EOF`,
 	return transformationCmd
 }
 
-func (o *CliOptions) transformation(name, target string, specReader io.Reader, eventSourcesFilter, eventTypesFilter []string) error {
-	ctx := context.Background()
+func (o *CliOptions) transformation(ctx context.Context, name, target string, specReader io.Reader, eventSourcesFilter, eventTypesFilter []string) error {
 	targetLabel := ""
 
 	var targetComponent triggermesh.Component
@@ -182,6 +215,9 @@ func (o *CliOptions) transformation(name, target string, specReader io.Reader, e
 
 	t := transformation.New(name, "transformation", o.Config.Context,
 		o.Config.Triggermesh.ComponentsVersion, crd, spec)
+	if err := o.annotate(t); err != nil {
+		return err
+	}
 
 	transformationEventType := fmt.Sprintf("%s.output", t.GetName())
 	if len(expectedEventTypes) > 0 {
@@ -216,22 +252,27 @@ func (o *CliOptions) transformation(name, target string, specReader io.Reader, e
 	}
 
 	t.(*transformation.Transformation).SetLabel(transformation.TransformationContextLabel, transformationContexts(targetLabel, eventTypesFilter))
+	tx := o.begin(t)
 
 	log.Println("Updating manifest")
 	restart, err := o.Manifest.Add(t)
 	if err != nil {
-		return fmt.Errorf("unable to update manifest: %w", err)
+		return tx.rollback(fmt.Errorf("unable to update manifest: %w", err))
 	}
 
 	log.Println("Starting container")
-	if _, err := t.(triggermesh.Runnable).Start(ctx, nil, restart); err != nil {
-		return err
+	container, err := t.(triggermesh.Runnable).Start(ctx, nil, restart)
+	if err != nil {
+		return tx.rollback(err)
+	}
+	if err := components.PinHostPort(t, container, o.Manifest); err != nil {
+		return tx.rollback(err)
 	}
 
 	// update our triggers in case of target container restart
 	if restart {
 		if err := o.updateTriggers(t); err != nil {
-			return err
+			return tx.rollback(err)
 		}
 	}
 
@@ -239,10 +280,10 @@ func (o *CliOptions) transformation(name, target string, specReader io.Reader, e
 	// creating new trigger from transformation to target
 	if targetComponent != nil {
 		if targetTriggers, err = tmbroker.GetTargetTriggers(targetComponent.GetName(), o.Config.Context, o.Config.ConfigHome); err != nil {
-			return fmt.Errorf("target triggers: %w", err)
+			return tx.rollback(fmt.Errorf("target triggers: %w", err))
 		}
 		if _, err := o.createTrigger("", targetComponent, tmbroker.FilterAttribute("type", transformationEventType)); err != nil {
-			return fmt.Errorf("create trigger: %w", err)
+			return tx.rollback(fmt.Errorf("create trigger: %w", err))
 		}
 	}
 
@@ -250,7 +291,7 @@ func (o *CliOptions) transformation(name, target string, specReader io.Reader, e
 	for _, et := range eventTypesFilter {
 		filter := tmbroker.FilterAttribute("type", et)
 		if _, err := o.createTrigger("", t, filter); err != nil {
-			return err
+			return tx.rollback(err)
 		}
 		for _, component := range targetTriggers {
 			trigger := component.(*tmbroker.Trigger)
@@ -259,10 +300,10 @@ func (o *CliOptions) transformation(name, target string, specReader io.Reader, e
 				continue
 			}
 			if err := trigger.RemoveFromLocalConfig(); err != nil {
-				return err
+				return tx.rollback(err)
 			}
 			if err := o.Manifest.Remove(trigger.GetName(), trigger.GetKind()); err != nil {
-				return err
+				return tx.rollback(err)
 			}
 		}
 	}
@@ -275,10 +316,10 @@ func (o *CliOptions) transformation(name, target string, specReader io.Reader, e
 			}
 			trigger.(*tmbroker.Trigger).SetTarget(t)
 			if err := trigger.(*tmbroker.Trigger).WriteLocalConfig(); err != nil {
-				return err
+				return tx.rollback(err)
 			}
 			if _, err := o.Manifest.Add(trigger); err != nil {
-				return err
+				return tx.rollback(err)
 			}
 		}
 	}
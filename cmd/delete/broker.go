@@ -17,6 +17,7 @@ limitations under the License.
 package delete
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -37,18 +38,18 @@ func (o *CliOptions) deleteBrokerCmd() *cobra.Command {
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 			return completion.ListObjectsByKind("RedisBroker", o.Manifest), cobra.ShellCompDirectiveNoFileComp
 		}, RunE: func(cmd *cobra.Command, args []string) error {
-			return o.deleteBroker(args[0])
+			return o.deleteBroker(cmd.Context(), args[0])
 		},
 	}
 }
 
-func (o *CliOptions) deleteBroker(broker string) error {
+func (o *CliOptions) deleteBroker(ctx context.Context, broker string) error {
 	oo := *o
 	oo.Config.Context = broker
 	oo.Manifest = manifest.New(filepath.Join(oo.Config.ConfigHome, broker, triggermesh.ManifestFile))
 	cobra.CheckErr(oo.Manifest.Read())
 
-	if err := oo.deleteBrokerComponents([]string{}, true); err != nil {
+	if err := oo.deleteBrokerComponents(ctx, []string{}, true); err != nil {
 		return fmt.Errorf("deleting component: %w", err)
 	}
 	if err := os.RemoveAll(filepath.Join(oo.Config.ConfigHome, broker)); err != nil {
@@ -27,6 +27,7 @@ import (
 	"github.com/triggermesh/tmctl/cmd/brokers"
 	"github.com/triggermesh/tmctl/pkg/config"
 	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/journal"
 	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/log"
 	"github.com/triggermesh/tmctl/pkg/manifest"
@@ -68,8 +69,7 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crds map[string]
 	return deleteCmd
 }
 
-func (o *CliOptions) deleteBrokerComponents(names []string, deleteBroker bool) error {
-	ctx := context.Background()
+func (o *CliOptions) deleteBrokerComponents(ctx context.Context, names []string, deleteBroker bool) error {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Errorf("docker client: %w", err)
@@ -186,7 +186,10 @@ func (o *CliOptions) removeExternalServices(ctx context.Context, object kubernet
 	if err != nil {
 		return fmt.Errorf("secrets extraction: %w", err)
 	}
-	return r.Finalize(ctx, secretsEnv)
+	if err := r.Finalize(ctx, secretsEnv); err != nil {
+		return err
+	}
+	return journal.Forget(o.Config.ConfigHome, object.Metadata.Labels[triggermesh.ContextLabel], object.Kind, object.Metadata.Name)
 }
 
 func (o *CliOptions) switchContext() error {
@@ -37,13 +37,12 @@ func (o *CliOptions) deleteSourceCmd() *cobra.Command {
 				cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return o.deleteSources(args)
+			return o.deleteSources(cmd.Context(), args)
 		},
 	}
 }
 
-func (o *CliOptions) deleteSources(names []string) error {
-	ctx := context.Background()
+func (o *CliOptions) deleteSources(ctx context.Context, names []string) error {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Errorf("docker client: %w", err)
@@ -37,13 +37,12 @@ func (o *CliOptions) deleteTargetCmd() *cobra.Command {
 				cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return o.deleteTarget(args)
+			return o.deleteTarget(cmd.Context(), args)
 		},
 	}
 }
 
-func (o *CliOptions) deleteTarget(names []string) error {
-	ctx := context.Background()
+func (o *CliOptions) deleteTarget(ctx context.Context, names []string) error {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Errorf("docker client: %w", err)
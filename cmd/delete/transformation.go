@@ -35,13 +35,12 @@ func (o *CliOptions) deleteTransformationCmd() *cobra.Command {
 			return completion.ListObjectsByKind("Transformation", o.Manifest), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return o.deleteTransformation(args)
+			return o.deleteTransformation(cmd.Context(), args)
 		},
 	}
 }
 
-func (o *CliOptions) deleteTransformation(names []string) error {
-	ctx := context.Background()
+func (o *CliOptions) deleteTransformation(ctx context.Context, names []string) error {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Errorf("docker client: %w", err)
@@ -35,13 +35,12 @@ func (o *CliOptions) deleteTriggerCmd() *cobra.Command {
 			return completion.ListObjectsByKind("Trigger", o.Manifest), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return o.deleteTrigger(args)
+			return o.deleteTrigger(cmd.Context(), args)
 		},
 	}
 }
 
-func (o *CliOptions) deleteTrigger(names []string) error {
-	ctx := context.Background()
+func (o *CliOptions) deleteTrigger(ctx context.Context, names []string) error {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Errorf("docker client: %w", err)
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
@@ -50,6 +51,8 @@ type CliOptions struct {
 	Config   *config.Config
 	Manifest *manifest.Manifest
 	CRD      map[string]crd.CRD
+
+	External bool
 }
 
 func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
@@ -58,11 +61,12 @@ func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD)
 		Config:   config,
 		Manifest: m,
 	}
-	return &cobra.Command{
-		Use:     "describe [broker]",
-		Short:   "List broker components and their statuses",
-		Example: "tmctl describe",
-		Args:    cobra.RangeArgs(0, 1),
+	describeCmd := &cobra.Command{
+		Use:   "describe [broker]",
+		Short: "List broker components and their statuses",
+		Example: `tmctl describe
+tmctl describe --external`,
+		Args: cobra.RangeArgs(0, 1),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 			return []string{}, cobra.ShellCompDirectiveNoFileComp
 		},
@@ -75,12 +79,17 @@ func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD)
 					triggermesh.ManifestFile))
 			}
 			cobra.CheckErr(o.Manifest.Read())
-			return o.Describe()
+			if o.External {
+				return o.DescribeExternal(cmd.Context())
+			}
+			return o.Describe(cmd.Context())
 		},
 	}
+	describeCmd.Flags().BoolVar(&o.External, "external", false, "List the external resources of the components and check if they exist")
+	return describeCmd
 }
 
-func (o *CliOptions) Describe() error {
+func (o *CliOptions) Describe(ctx context.Context) error {
 	broker := tabwriter.NewWriter(os.Stdout, 10, 5, 5, ' ', 0)
 	triggers := tabwriter.NewWriter(os.Stdout, 10, 5, 5, ' ', 0)
 	producers := tabwriter.NewWriter(os.Stdout, 10, 5, 5, ' ', 0)
@@ -109,7 +118,7 @@ func (o *CliOptions) Describe() error {
 			switch c.GetKind() {
 			case tmbroker.BrokerKind:
 				brokersPrint = true
-				fmt.Fprintf(broker, "%s\t%s\n", c.GetName(), status(c))
+				fmt.Fprintf(broker, "%s\t%s\n", c.GetName(), status(ctx, c))
 			case tmbroker.TriggerKind:
 				filterString := "*"
 				if len(c.(*tmbroker.Trigger).Filters) != 0 {
@@ -133,7 +142,7 @@ func (o *CliOptions) Describe() error {
 						et = []string{"*"}
 					}
 					producersPrint = true
-					fmt.Fprintf(producers, "%s\tservice (%s)\t%s\t%s\n", c.GetName(), service.Image, strings.Join(et, ", "), status(c))
+					fmt.Fprintf(producers, "%s\tservice (%s)\t%s\t%s\n", c.GetName(), service.Image, strings.Join(et, ", "), status(ctx, c))
 				}
 				if service.IsTarget() {
 					et, _ := c.(triggermesh.Consumer).ConsumedEventTypes()
@@ -141,7 +150,7 @@ func (o *CliOptions) Describe() error {
 						et = []string{"*"}
 					}
 					consumersPrint = true
-					fmt.Fprintf(consumers, "%s\tservice (%s)\t%s\t%s\n", c.GetName(), service.Image, strings.Join(et, ", "), status(c))
+					fmt.Fprintf(consumers, "%s\tservice (%s)\t%s\t%s\n", c.GetName(), service.Image, strings.Join(et, ", "), status(ctx, c))
 				}
 			}
 			// transformation
@@ -151,7 +160,7 @@ func (o *CliOptions) Describe() error {
 					et = []string{"*"}
 				}
 				transformationsPrint = true
-				fmt.Fprintf(transformations, "%s\t%s\t%s\n", c.GetName(), strings.Join(et, ", "), status(c))
+				fmt.Fprintf(transformations, "%s\t%s\t%s\n", c.GetName(), strings.Join(et, ", "), status(ctx, c))
 			}
 		case pOk:
 			// source
@@ -160,7 +169,7 @@ func (o *CliOptions) Describe() error {
 				et = []string{"*"}
 			}
 			producersPrint = true
-			fmt.Fprintf(producers, "%s\t%s\t%s\t%s\n", c.GetName(), c.GetKind(), strings.Join(et, ", "), status(c))
+			fmt.Fprintf(producers, "%s\t%s\t%s\t%s\n", c.GetName(), c.GetKind(), strings.Join(et, ", "), status(ctx, c))
 		case cOk:
 			// target
 			et, _ := consumer.ConsumedEventTypes()
@@ -168,7 +177,7 @@ func (o *CliOptions) Describe() error {
 				et = []string{"*"}
 			}
 			consumersPrint = true
-			fmt.Fprintf(consumers, "%s\t%s\t%s\t%s\n", c.GetName(), c.GetKind(), strings.Join(et, ", "), status(c))
+			fmt.Fprintf(consumers, "%s\t%s\t%s\t%s\n", c.GetName(), c.GetKind(), strings.Join(et, ", "), status(ctx, c))
 		}
 	}
 	if brokersPrint {
@@ -189,10 +198,68 @@ func (o *CliOptions) Describe() error {
 	return nil
 }
 
-func status(component triggermesh.Component) string {
+// DescribeExternal lists the external resources recorded in the components
+// annotations and checks their existence with the read-only cloud APIs.
+func (o *CliOptions) DescribeExternal(ctx context.Context) error {
+	if err := o.Manifest.Resolve(""); err != nil {
+		return err
+	}
+	resources := tabwriter.NewWriter(os.Stdout, 10, 5, 5, ' ', 0)
+	fmt.Fprintln(resources, "Component\tResource\tValue\tStatus")
+	resourcesPrint := false
+	for _, object := range o.Manifest.Objects {
+		c, err := components.GetObject(object.Metadata.Name, o.Config, o.Manifest, o.CRD)
+		if err != nil {
+			return fmt.Errorf("creating component interface: %w", err)
+		}
+		reconcilable, ok := c.(triggermesh.Reconcilable)
+		if !ok || len(reconcilable.GetExternalResources()) == 0 {
+			continue
+		}
+		var secrets map[string]string
+		var secretsErr error
+		if parent, ok := c.(triggermesh.Parent); ok {
+			if _, secrets, err = components.ProcessSecrets(parent, o.Manifest); err != nil {
+				secretsErr = fmt.Errorf("processing secrets: %w", err)
+			}
+		}
+		external := reconcilable.GetExternalResources()
+		keys := make([]string, 0, len(external))
+		for key := range external {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			resourcesPrint = true
+			fmt.Fprintf(resources, "%s\t%s\t%v\t%s\n", c.GetName(), key, external[key], externalStatus(ctx, reconcilable, secrets, secretsErr, key))
+		}
+	}
+	if !resourcesPrint {
+		fmt.Println("No external resources recorded")
+		return nil
+	}
+	fmt.Fprintln(resources)
+	return nil
+}
+
+func externalStatus(ctx context.Context, reconcilable triggermesh.Reconcilable, secrets map[string]string, secretsErr error, key string) string {
+	if secretsErr != nil {
+		return fmt.Sprintf("unknown (%v)", secretsErr)
+	}
+	exists, err := reconcilable.ExternalResourceExists(ctx, secrets, key)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("unknown (%v)", err)
+	case exists:
+		return fmt.Sprintf("%sexists%s", successColorCode, defaultColorCode)
+	}
+	return fmt.Sprintf("%smissing%s", offlineColorCode, defaultColorCode)
+}
+
+func status(ctx context.Context, component triggermesh.Component) string {
 	offlineStatus := fmt.Sprintf("%soffline%s", offlineColorCode, defaultColorCode)
 	if container, ok := component.(triggermesh.Runnable); ok {
-		c, err := container.Info(context.Background())
+		c, err := container.Info(ctx)
 		if err != nil || !c.Online {
 			return offlineStatus
 		}
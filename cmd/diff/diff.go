@@ -0,0 +1,204 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/history"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+type CliOptions struct {
+	Config   *config.Config
+	Manifest *manifest.Manifest
+
+	Revision int
+}
+
+func NewCmd(config *config.Config, m *manifest.Manifest) *cobra.Command {
+	o := &CliOptions{
+		Config:   config,
+		Manifest: m,
+	}
+	diffCmd := &cobra.Command{
+		Use:   "diff [--rev N] [file]",
+		Short: "Show the changes of the broker components",
+		Long: `Show the changes of the broker components.
+
+Manifest objects are compared by kind and name, modified objects are listed
+with the changed fields, secret values are not printed. Without arguments,
+the changes made by the last command are shown. With --rev, the changes
+since the revision from "tmctl history" are shown. The manifest file
+argument is compared with the current manifest or, if --rev is set, with
+the revision manifest.`,
+		Example: `tmctl diff
+tmctl diff --rev 3
+tmctl diff manifest.yaml`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Manifest.Read(); err != nil {
+				return err
+			}
+			var file string
+			if len(args) == 1 {
+				file = args[0]
+			}
+			return o.diff(file)
+		},
+	}
+	diffCmd.Flags().IntVar(&o.Revision, "rev", 0, "Revision number to compare with")
+	cobra.CheckErr(diffCmd.RegisterFlagCompletionFunc("rev", cobra.NoFileCompletions))
+	return diffCmd
+}
+
+func (o *CliOptions) diff(file string) error {
+	brokerHome := filepath.Dir(o.Manifest.Path)
+	from, to := o.Manifest, o.Manifest
+	var fromConfig, toConfig string
+	if file != "" {
+		to = manifest.New(file)
+		if err := to.Read(); err != nil {
+			return fmt.Errorf("manifest %q: %w", file, err)
+		}
+	} else {
+		toConfig = filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
+	}
+	if o.Revision != 0 || file == "" {
+		revision, err := o.revision(brokerHome)
+		if err != nil {
+			return err
+		}
+		from = manifest.New(revision.Path(triggermesh.ManifestFile))
+		if err := from.Read(); err != nil {
+			return fmt.Errorf("revision %d: %w", revision.Number, err)
+		}
+		fromConfig = revision.Path(triggermesh.BrokerConfigFile)
+	}
+
+	objects, err := manifest.Diff(from.Objects, to.Objects)
+	if err != nil {
+		return err
+	}
+	var triggers []manifest.ObjectDiff
+	if fromConfig != "" && toConfig != "" {
+		if triggers, err = diffConfig(fromConfig, toConfig); err != nil {
+			return err
+		}
+	}
+	if len(objects) == 0 && len(triggers) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	for _, object := range objects {
+		fmt.Println(object)
+	}
+	if len(triggers) != 0 {
+		fmt.Printf("%s:\n", triggermesh.BrokerConfigFile)
+		for _, trigger := range triggers {
+			fmt.Println(trigger)
+		}
+	}
+	return nil
+}
+
+// revision returns the requested revision or the one
+// preceding the last change.
+func (o *CliOptions) revision(brokerHome string) (history.Revision, error) {
+	if o.Revision != 0 {
+		return history.Get(brokerHome, o.Revision)
+	}
+	revisions, err := history.List(brokerHome)
+	if err != nil {
+		return history.Revision{}, fmt.Errorf("history: %w", err)
+	}
+	switch len(revisions) {
+	case 0:
+		return history.Revision{}, fmt.Errorf("broker has no history")
+	case 1:
+		return revisions[0], nil
+	}
+	return revisions[len(revisions)-2], nil
+}
+
+// diffConfig compares the triggers of the broker configuration files.
+func diffConfig(fromPath, toPath string) ([]manifest.ObjectDiff, error) {
+	from, err := readTriggers(fromPath)
+	if err != nil {
+		return nil, err
+	}
+	to, err := readTriggers(toPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{}, len(from)+len(to))
+	for name := range from {
+		names[name] = struct{}{}
+	}
+	for name := range to {
+		names[name] = struct{}{}
+	}
+	var result []manifest.ObjectDiff
+	for name := range names {
+		d := manifest.ObjectDiff{Kind: "trigger", Name: name}
+		fromTrigger, inFrom := from[name]
+		toTrigger, inTo := to[name]
+		switch {
+		case !inFrom:
+			d.Change = manifest.Added
+		case !inTo:
+			d.Change = manifest.Removed
+		default:
+			fields, err := manifest.DiffValues(fromTrigger, toTrigger)
+			if err != nil {
+				return nil, fmt.Errorf("trigger %q: %w", name, err)
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			d.Change = manifest.Modified
+			d.Fields = fields
+		}
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func readTriggers(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("broker config: %w", err)
+	}
+	var config struct {
+		Triggers map[string]interface{} `yaml:"triggers"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("broker config %q: %w", path, err)
+	}
+	return config.Triggers, nil
+}
@@ -18,6 +18,7 @@ package dump
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -32,6 +33,7 @@ import (
 	"github.com/triggermesh/tmctl/pkg/config"
 	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/secrets"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
 	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
@@ -61,6 +63,7 @@ type CliOptions struct {
 	Platform string
 
 	NoSecrets bool
+	Overlay   string
 }
 
 func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
@@ -74,7 +77,7 @@ func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD)
 		Use:       "dump [broker] -p <kubernetes|knative|docker-compose|digitalocean> [-o json]",
 		Short:     "Generate TriggerMesh manifests",
 		Example:   "tmctl dump",
-		ValidArgs: []string{"--platform", "--output"},
+		ValidArgs: []string{"--platform", "--output", "--overlay"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 0 {
 				o.Config.Context = args[0]
@@ -84,13 +87,17 @@ func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD)
 					triggermesh.ManifestFile))
 			}
 			cobra.CheckErr(o.Manifest.Read())
-			return o.dump(do)
+			if err := o.Manifest.Resolve(manifest.OverlayPath(o.Manifest.Path, o.Overlay)); err != nil {
+				return err
+			}
+			return o.dump(cmd.Context(), do)
 		},
 	}
 
 	dumpCmd.Flags().StringVarP(&o.Platform, "platform", "p", "kubernetes", "Target platform. One of kubernetes, knative, docker-compose, digitalocean")
-	dumpCmd.Flags().BoolVar(&o.NoSecrets, "no-secrets", false, "Remove secret values from the manifest")
+	dumpCmd.Flags().BoolVar(&o.NoSecrets, "no-secrets", false, "Remove secret values from the manifest, secret references are kept")
 	dumpCmd.Flags().StringVarP(&o.Format, "output", "o", "yaml", "Output format")
+	dumpCmd.Flags().StringVar(&o.Overlay, "overlay", "", "Overlay manifest name or path merged over the manifest, e.g. \"dev\" for manifest.dev.yaml")
 
 	dumpCmd.Flags().StringVarP(&do.Region, "do-region", "r", "fra", "DigitalOcean region")
 	dumpCmd.Flags().StringVarP(&do.InstanceSize, "do-instance", "i", "professional-xs", "DigitalOcean instance size")
@@ -111,7 +118,7 @@ func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD)
 	return dumpCmd
 }
 
-func (o *CliOptions) dump(do *doOptions) error {
+func (o *CliOptions) dump(ctx context.Context, do *doOptions) error {
 	var externalReconcilable []string
 	var output interface{}
 	for _, object := range o.Manifest.Objects {
@@ -120,17 +127,17 @@ func (o *CliOptions) dump(do *doOptions) error {
 		if err != nil {
 			continue
 		}
-		if o.NoSecrets && component.GetAPIVersion() == "v1" && component.GetKind() == "Secret" {
-			redactedData := make(map[string]string, len(component.GetSpec()))
-			for key := range component.GetSpec() {
-				redactedData[key] = triggermesh.UserInputTag
+		if component.GetAPIVersion() == "v1" && component.GetKind() == "Secret" {
+			s, err := o.secret(component)
+			if err != nil {
+				return fmt.Errorf("secret %q: %w", component.GetName(), err)
 			}
-			component = secret.New(component.GetName(), o.Config.Context, redactedData)
+			component = s
 			object, _ = component.AsK8sObject()
 		}
 		if reconcilable, ok := component.(triggermesh.Reconcilable); ok {
 			if container, ok := component.(triggermesh.Runnable); ok {
-				if _, err := container.Info(context.Background()); err == nil {
+				if _, err := container.Info(ctx); err == nil {
 					var resources []string
 					for _, r := range reconcilable.GetExternalResources() {
 						resources = append(resources, r.(string))
@@ -226,6 +233,7 @@ func (o *CliOptions) dump(do *doOptions) error {
 			output = append(output.([]interface{}), deployment, svc)
 		case platformKnative:
 			object.Metadata.Namespace = ""
+			object.Metadata.Annotations = clusterAnnotations(object.Metadata.Annotations)
 			if output == nil {
 				output = []interface{}{o.knativeEventingTransformation(object)}
 				continue
@@ -233,6 +241,7 @@ func (o *CliOptions) dump(do *doOptions) error {
 			output = append(output.([]interface{}), o.knativeEventingTransformation(object))
 		case platformKubernetes:
 			object.Metadata.Namespace = ""
+			object.Metadata.Annotations = clusterAnnotations(object.Metadata.Annotations)
 			if output == nil {
 				output = []interface{}{object}
 				continue
@@ -256,6 +265,29 @@ func (o *CliOptions) dump(do *doOptions) error {
 	return nil
 }
 
+// secret returns the secret with the references resolved or, if the secret
+// values are removed, with the references kept and the rest of the values
+// replaced with the user input placeholders.
+func (o *CliOptions) secret(component triggermesh.Component) (triggermesh.Component, error) {
+	data := make(map[string]string, len(component.GetSpec()))
+	for key, value := range component.GetSpec() {
+		v := value.(string)
+		switch {
+		case secrets.IsReference(v) && !o.NoSecrets:
+			plain, err := secrets.Resolve(v)
+			if err != nil {
+				return nil, err
+			}
+			data[key] = base64.StdEncoding.EncodeToString([]byte(plain))
+		case o.NoSecrets && !secrets.IsReference(v):
+			data[key] = triggermesh.UserInputTag
+		default:
+			data[key] = v
+		}
+	}
+	return secret.New(component.GetName(), o.Config.Context, data), nil
+}
+
 func (o *CliOptions) getStaticBrokerConfig() ([]byte, error) {
 	var staticBrokerConfig tmbroker.Configuration
 	for _, object := range o.Manifest.Objects {
@@ -289,6 +321,22 @@ func (o *CliOptions) getStaticBrokerConfig() ([]byte, error) {
 	return json.Marshal(staticBrokerConfig)
 }
 
+// clusterAnnotations returns the copy of the annotations
+// without the ones that configure the local containers.
+func clusterAnnotations(annotations map[string]string) map[string]string {
+	result := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		result[k] = v
+	}
+	for _, local := range triggermesh.LocalRuntimeAnnotations {
+		delete(result, local)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
 func (o *CliOptions) knativeEventingTransformation(object kubernetes.Object) kubernetes.Object {
 	switch object.APIVersion {
 	case tmbroker.APIVersion:
@@ -0,0 +1,231 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+const wrapWidth = 80
+
+type CliOptions struct {
+	Config *config.Config
+	CRD    map[string]crd.CRD
+
+	Recursive bool
+}
+
+func NewCmd(config *config.Config, crd map[string]crd.CRD) *cobra.Command {
+	o := &CliOptions{
+		CRD:    crd,
+		Config: config,
+	}
+	explainCmd := &cobra.Command{
+		Use:   "explain <kind>[.spec.field...]",
+		Short: "Describe the fields of TriggerMesh components",
+		Long: `Describe the fields of TriggerMesh components.
+
+Field types, descriptions, default values and allowed values are read
+from the cached CRDs of the components version selected by the global
+--version flag. Secret fields accept plain string values that are stored
+in the component secret.`,
+		Example: `tmctl explain awss3source
+tmctl explain awss3source.spec.destination
+tmctl explain httptarget --recursive`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return o.completion(toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("version") {
+				if err := o.fetchCRD(o.Config.Triggermesh.ComponentsVersion); err != nil {
+					return err
+				}
+			}
+			return o.explain(os.Stdout, args[0])
+		},
+	}
+	explainCmd.Flags().BoolVar(&o.Recursive, "recursive", false, "Print the fields of all nesting levels")
+	return explainCmd
+}
+
+func (o *CliOptions) explain(w io.Writer, query string) error {
+	c, path, err := o.parse(query)
+	if err != nil {
+		return err
+	}
+	schema, err := crd.ServedSchema(c)
+	if err != nil {
+		return err
+	}
+	field, err := schema.Explain(path...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Spec.Names.Kind, err)
+	}
+	version := ""
+	for _, v := range c.Spec.Versions {
+		if v.Served {
+			version = c.Spec.Group + "/" + v.Name
+			break
+		}
+	}
+	fmt.Fprintf(w, "KIND:     %s\n", c.Spec.Names.Kind)
+	fmt.Fprintf(w, "VERSION:  %s\n\n", version)
+	fmt.Fprintf(w, "FIELD:    %s <%s>%s\n", field.Name, field.Type, flags(field))
+	if field.Default != "" {
+		fmt.Fprintf(w, "DEFAULT:  %s\n", field.Default)
+	}
+	if len(field.Enum) != 0 {
+		fmt.Fprintf(w, "ENUM:     %s\n", strings.Join(field.Enum, ", "))
+	}
+	if field.Description != "" {
+		fmt.Fprintf(w, "\nDESCRIPTION:\n")
+		printWrapped(w, field.Description, "     ")
+	}
+	if len(field.Fields) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\nFIELDS:\n")
+	if o.Recursive {
+		printTree(w, field.Fields, "   ")
+		return nil
+	}
+	for _, nested := range field.Fields {
+		fmt.Fprintf(w, "   %s\t<%s>%s\n", nested.Name, nested.Type, flags(nested))
+		if nested.Default != "" {
+			fmt.Fprintf(w, "     Default: %s\n", nested.Default)
+		}
+		if len(nested.Enum) != 0 {
+			fmt.Fprintf(w, "     Enum: %s\n", strings.Join(nested.Enum, ", "))
+		}
+		printWrapped(w, nested.Description, "     ")
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func (o *CliOptions) fetchCRD(version string) error {
+	crds, err := crd.Fetch(o.Config.CacheHome, version)
+	if err != nil {
+		return fmt.Errorf("%s CRD: %w", version, err)
+	}
+	o.CRD = crds
+	return nil
+}
+
+// parse splits the query into the CRD and the spec field path.
+func (o *CliOptions) parse(query string) (crd.CRD, []string, error) {
+	parts := strings.Split(strings.Trim(query, "."), ".")
+	kind := strings.ToLower(parts[0])
+	c, exists := o.CRD[kind]
+	if !exists {
+		c, exists = o.CRD[kind+"source"]
+	}
+	if !exists {
+		c, exists = o.CRD[kind+"target"]
+	}
+	if !exists {
+		return crd.CRD{}, nil, fmt.Errorf("kind %q does not exist", parts[0])
+	}
+	path := parts[1:]
+	if len(path) != 0 && path[0] == "spec" {
+		path = path[1:]
+	}
+	return c, path, nil
+}
+
+// completion returns the kinds or the nested fields of the partial query.
+func (o *CliOptions) completion(toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !strings.Contains(toComplete, ".") {
+		var kinds []string
+		for k := range o.CRD {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		return kinds, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	prefix := toComplete[:strings.LastIndex(toComplete, ".")]
+	c, path, err := o.parse(prefix)
+	if err != nil {
+		return []string{}, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(path) == 0 && !strings.HasSuffix(prefix, ".spec") {
+		return []string{prefix + ".spec"}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	schema, err := crd.ServedSchema(c)
+	if err != nil {
+		return []string{}, cobra.ShellCompDirectiveNoFileComp
+	}
+	field, err := schema.Explain(path...)
+	if err != nil {
+		return []string{}, cobra.ShellCompDirectiveNoFileComp
+	}
+	var result []string
+	for _, nested := range field.Fields {
+		result = append(result, fmt.Sprintf("%s.%s\t%s", prefix, nested.Name, nested.Type))
+	}
+	return result, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func printTree(w io.Writer, fields []crd.Field, indent string) {
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s%s\t<%s>%s\n", indent, field.Name, field.Type, flags(field))
+		printTree(w, field.Fields, indent+"   ")
+	}
+}
+
+func flags(field crd.Field) string {
+	var result string
+	if field.Required {
+		result += " -required-"
+	}
+	if field.Secret {
+		result += " -secret-"
+	}
+	return result
+}
+
+func printWrapped(w io.Writer, text, indent string) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := indent
+		for _, word := range strings.Fields(paragraph) {
+			if len(line) > len(indent) && len(line)+len(word)+1 > wrapWidth {
+				fmt.Fprintln(w, line)
+				line = indent
+			}
+			if len(line) > len(indent) {
+				line += " "
+			}
+			line += word
+		}
+		if len(line) > len(indent) {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/cmd/brokers"
+	"github.com/triggermesh/tmctl/cmd/start"
+	"github.com/triggermesh/tmctl/pkg/config"
+	tmhistory "github.com/triggermesh/tmctl/pkg/history"
+)
+
+type CliOptions struct {
+	Config *config.Config
+}
+
+func NewCmd(config *config.Config) *cobra.Command {
+	o := &CliOptions{
+		Config: config,
+	}
+	historyCmd := &cobra.Command{
+		Use:   "history [broker]",
+		Short: "Show the history of the broker changes",
+		Long: `Show the history of the broker changes.
+
+Every command that changes the manifest or the broker configuration
+stores the snapshot of both files as the new revision. Revisions can be
+compared with "tmctl diff" and restored with "tmctl rollback".`,
+		Example: `tmctl history
+tmctl history foo`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			list, _ := brokers.List(o.Config.ConfigHome, "")
+			return list, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			broker := o.Config.Context
+			if len(args) == 1 {
+				broker = args[0]
+			}
+			if !start.IsBroker(o.Config.ConfigHome, broker) {
+				return fmt.Errorf("broker %q does not exist", broker)
+			}
+			return o.history(filepath.Join(o.Config.ConfigHome, broker))
+		},
+	}
+	return historyCmd
+}
+
+func (o *CliOptions) history(brokerHome string) error {
+	revisions, err := tmhistory.List(brokerHome)
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	if len(revisions) == 0 {
+		fmt.Println("No history")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 10, 5, 5, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tTIME\tCOMMAND")
+	for _, revision := range revisions {
+		command := revision.Command
+		if command == "" {
+			command = "(initial state)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", revision.Number, revision.Time.Local().Format("2006-01-02 15:04:05"), command)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/log"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+	"github.com/triggermesh/tmctl/pkg/wiretap"
+)
+
+type CliOptions struct {
+	Config   *config.Config
+	Manifest *manifest.Manifest
+	CRD      map[string]crd.CRD
+}
+
+func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
+	o := &CliOptions{
+		CRD:      crd,
+		Config:   config,
+		Manifest: m,
+	}
+	imagesCmd := &cobra.Command{
+		Use:   "images [list|pull|save|load]",
+		Short: "Manage container images of the broker components",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+	imagesCmd.AddCommand(o.listCmd())
+	imagesCmd.AddCommand(o.pullCmd())
+	imagesCmd.AddCommand(o.saveCmd())
+	imagesCmd.AddCommand(o.loadCmd())
+	return imagesCmd
+}
+
+func (o *CliOptions) listCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list [broker]",
+		Short:   "List images required to run the broker components",
+		Example: "tmctl images list",
+		Args:    cobra.RangeArgs(0, 1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			images, err := o.images(args)
+			if err != nil {
+				return err
+			}
+			for _, image := range images {
+				fmt.Println(image)
+			}
+			return nil
+		},
+	}
+}
+
+func (o *CliOptions) pullCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "pull [broker]",
+		Short:   "Pull images required to run the broker components",
+		Example: "tmctl images pull",
+		Args:    cobra.RangeArgs(0, 1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			images, err := o.images(args)
+			if err != nil {
+				return err
+			}
+			client, err := docker.NewClient()
+			if err != nil {
+				return fmt.Errorf("docker client: %w", err)
+			}
+			return docker.PullImages(cmd.Context(), client, images)
+		},
+	}
+}
+
+func (o *CliOptions) saveCmd() *cobra.Command {
+	var output string
+	saveCmd := &cobra.Command{
+		Use:     "save [broker] -o <file>",
+		Short:   "Save images required to run the broker components to the tar archive",
+		Example: "tmctl images save -o bundle.tar",
+		Args:    cobra.RangeArgs(0, 1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			images, err := o.images(args)
+			if err != nil {
+				return err
+			}
+			return o.save(cmd.Context(), images, output)
+		},
+	}
+	saveCmd.Flags().StringVarP(&output, "output", "o", "", "Archive file path")
+	cobra.CheckErr(saveCmd.MarkFlagRequired("output"))
+	return saveCmd
+}
+
+func (o *CliOptions) loadCmd() *cobra.Command {
+	var input string
+	loadCmd := &cobra.Command{
+		Use:     "load -i <file>",
+		Short:   "Load images from the tar archive created by \"tmctl images save\"",
+		Example: "tmctl images load -i bundle.tar",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := docker.NewClient()
+			if err != nil {
+				return fmt.Errorf("docker client: %w", err)
+			}
+			f, err := os.Open(input)
+			if err != nil {
+				return fmt.Errorf("archive file: %w", err)
+			}
+			defer f.Close()
+			return docker.LoadImages(cmd.Context(), client, f)
+		},
+	}
+	loadCmd.Flags().StringVarP(&input, "input", "i", "", "Archive file path")
+	cobra.CheckErr(loadCmd.MarkFlagRequired("input"))
+	return loadCmd
+}
+
+// images returns the sorted list of the images used by the manifest
+// components, the broker and the wiretap used by "tmctl watch".
+func (o *CliOptions) images(args []string) ([]string, error) {
+	if len(args) == 1 {
+		o.Config.Context = args[0]
+		o.Manifest = manifest.New(filepath.Join(
+			o.Config.ConfigHome,
+			o.Config.Context,
+			triggermesh.ManifestFile))
+	}
+	if err := o.Manifest.Read(); err != nil {
+		return nil, fmt.Errorf("manifest read: %w", err)
+	}
+	unique := map[string]struct{}{
+		wiretap.Image: {},
+	}
+	for _, object := range o.Manifest.Objects {
+		c, err := components.GetObject(object.Metadata.Name, o.Config, o.Manifest, o.CRD)
+		if err != nil {
+			return nil, fmt.Errorf("creating component interface: %w", err)
+		}
+		if r, ok := c.(triggermesh.Runnable); ok {
+			unique[r.GetImage()] = struct{}{}
+		}
+	}
+	images := make([]string, 0, len(unique))
+	for image := range unique {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+func (o *CliOptions) save(ctx context.Context, images []string, path string) error {
+	client, err := docker.NewClient()
+	if err != nil {
+		return fmt.Errorf("docker client: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("archive file: %w", err)
+	}
+	log.Printf("Saving %d images to %s", len(images), path)
+	if err := docker.SaveImages(ctx, client, images, f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("saving images, run \"tmctl images pull\" to fetch missing ones: %w", err)
+	}
+	return f.Close()
+}
@@ -17,24 +17,96 @@ limitations under the License.
 package import_
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/load"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
 )
 
 func NewCmd(config *config.Config, crd map[string]crd.CRD) *cobra.Command {
-	var from string
+	var from, format, overlay, valuesFile string
+	var set []string
+	var fromCluster bool
+	var namespace, broker, kubeconfig string
+	values := &load.Values{}
 	importCmd := &cobra.Command{
-		Use:     "import -f <path/to/manifest.yaml>/<manifest URL>",
-		Short:   "Import TriggerMesh manifest",
-		Example: "tmctl import -f manifest.yaml",
+		Use:   "import -f <path/to/manifest.yaml>/<manifest URL> [--format <manifest|compose|digitalocean>][--values <values.yaml>][--set <component.key=value>...][--from-env][--strict][--overlay <name>] | --from-cluster [--namespace <namespace>][--broker <name>]",
+		Short: "Import TriggerMesh manifest",
+		Long: `Import TriggerMesh manifest.
+
+Manifest values set to "<user_input>" are filled from the --set arguments,
+the values file and, with --from-env, the environment variables named as
+the upper-cased component name and key path with non-alphanumeric characters
+replaced by underscores, e.g. FOO_AWSS3SOURCE_SECRET_ACCESSKEYID. Remaining
+values are prompted unless --strict is set. The ${VAR} and ${VAR:-default}
+references are replaced with the environment variables, the manifest keeps
+the references.
+
+With --from-cluster, the broker, its triggers, TriggerMesh sources, targets
+and transformations are imported from the Kubernetes namespace along with
+the secrets they reference.
+
+With --format compose or digitalocean, the integration is restored from
+the docker-compose file or the DigitalOcean App Platform spec created with
+the dump command. TriggerMesh adapters are recognized by their images and
+their specs are rebuilt from the environment variables, triggers are read
+from the broker static configuration and the rest of the containers become
+services. Variables that do not match any spec field are kept as the adapter
+env overrides, these and the other settings that could not be mapped are
+reported. Required fields missing in the environment are filled as the
+"<user_input>" values.`,
+		Example: `tmctl import -f manifest.yaml
+tmctl import -f docker-compose.yaml --format compose
+tmctl import -f manifest.yaml --values values.yaml --set foo-awss3source-secret.accessKeyID=AKID --strict
+tmctl import --from-cluster --namespace production --broker default`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return load.Import(from, config, crd)
+			if fromCluster {
+				client, ns, err := kubernetes.DynamicClient(kubeconfig, namespace)
+				if err != nil {
+					return err
+				}
+				return load.ImportCluster(cmd.Context(), client, ns, broker, config, crd)
+			}
+			if from == "" {
+				return fmt.Errorf("either --from or --from-cluster must be set")
+			}
+			if overlay != "" && format != load.FormatManifest {
+				return fmt.Errorf("--overlay is only supported for the manifest format")
+			}
+			if valuesFile != "" {
+				if err := values.ReadFile(valuesFile); err != nil {
+					return fmt.Errorf("values file: %w", err)
+				}
+			}
+			for _, s := range set {
+				if err := values.Set(s); err != nil {
+					return err
+				}
+			}
+			if format != load.FormatManifest {
+				return load.ImportPlatform(cmd.Context(), from, format, config, crd, values)
+			}
+			return load.Import(cmd.Context(), from, overlay, config, crd, values)
 		},
 	}
 	importCmd.Flags().StringVarP(&from, "from", "f", "", "Import manifest from")
-	cobra.CheckErr(importCmd.MarkFlagRequired("from"))
+	importCmd.Flags().StringVar(&format, "format", load.FormatManifest, "Imported file format: manifest, compose or digitalocean")
+	importCmd.Flags().StringVar(&overlay, "overlay", "", "Overlay manifest name or path merged over the imported manifest, e.g. \"dev\" for manifest.dev.yaml")
+	importCmd.Flags().StringVar(&valuesFile, "values", "", "YAML file with the values of the components keys")
+	importCmd.Flags().StringArrayVar(&set, "set", []string{}, "Component key value in component.key=value format")
+	importCmd.Flags().BoolVar(&values.FromEnv, "from-env", false, "Read the values from the environment variables")
+	importCmd.Flags().BoolVar(&values.Strict, "strict", false, "Fail if some values are not provided instead of prompting them")
+	importCmd.Flags().BoolVar(&fromCluster, "from-cluster", false, "Import objects from the Kubernetes cluster")
+	importCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Cluster namespace, kubeconfig context namespace by default")
+	importCmd.Flags().StringVar(&broker, "broker", "", "Cluster broker to import, required if the namespace has multiple brokers")
+	importCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	importCmd.MarkFlagsMutuallyExclusive("from", "from-cluster")
+	cobra.CheckErr(importCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{load.FormatManifest, load.FormatCompose, load.FormatDigitalOcean}, cobra.ShellCompDirectiveNoFileComp
+	}))
 	return importCmd
 }
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package init_
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
+)
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+
+func NewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [broker]",
+		Short: "Create the workspace in the current directory",
+		Long: `Create the workspace in the current directory.
+
+The workspace is the ` + config.WorkspaceDir + ` directory with the CLI config and the brokers
+that can be versioned alongside the application code. Commands use the nearest
+workspace in the current or parent directories, the home directory config is
+used if there is no workspace. The broker name defaults to the directory name.`,
+		Example:           "tmctl init foo",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			name := brokerName(filepath.Base(dir))
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return initWorkspace(dir, name)
+		},
+	}
+}
+
+func initWorkspace(dir, name string) error {
+	if _, err := os.Stat(filepath.Join(dir, config.WorkspaceDir)); err == nil {
+		return fmt.Errorf("workspace already exists in %s", dir)
+	}
+	if name == "" {
+		return fmt.Errorf("broker name is required")
+	}
+	c, err := config.InitWorkspace(dir)
+	if err != nil {
+		return fmt.Errorf("workspace config: %w", err)
+	}
+	if _, err := tmbroker.CreateBrokerConfig(c.ConfigHome, name); err != nil {
+		return fmt.Errorf("creating broker config: %w", err)
+	}
+	broker, err := tmbroker.New(name, c.ConfigHome, c.Triggermesh.Broker)
+	if err != nil {
+		return fmt.Errorf("broker: %w", err)
+	}
+	m := manifest.New(filepath.Join(c.ConfigHome, name, triggermesh.ManifestFile))
+	if _, err := m.Add(broker); err != nil {
+		return fmt.Errorf("unable to update manifest: %w", err)
+	}
+	c.Context = name
+	if err := c.Save(); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	fmt.Printf("Workspace with broker %q is created in %s, run \"tmctl start\" to start it\n", name, c.ConfigHome)
+	return nil
+}
+
+// brokerName converts the directory name into the broker name.
+func brokerName(dir string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(dir), "-"), "-")
+}
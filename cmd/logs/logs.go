@@ -22,9 +22,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -78,20 +75,14 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crd map[string]c
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cobra.CheckErr(o.Manifest.Read())
-			return o.logs(args, follow)
+			return o.logs(cmd.Context(), args, follow)
 		},
 	}
 	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow logs output")
 	return logsCmd
 }
 
-func (o *CliOptions) logs(filter []string, follow bool) error {
-	cancel := make(chan os.Signal, 1)
-	signal.Notify(cancel, os.Interrupt, syscall.SIGTERM)
-	defer close(cancel)
-
-	ctx := context.Background()
-
+func (o *CliOptions) logs(ctx context.Context, filter []string, follow bool) error {
 	colorIndex := 0
 	for _, object := range o.Manifest.Objects {
 		component, err := components.GetObject(object.Metadata.Name, o.Config, o.Manifest, o.CRD)
@@ -138,24 +129,24 @@ func (o *CliOptions) logs(filter []string, follow bool) error {
 		colorIndex++
 		if follow {
 			log.Printf("%sListening %s%s", colorCode, component.GetName(), defaultColorCode)
-			go readLogs(logs, cancel, colorCode)
+			go readLogs(ctx, logs, colorCode)
 		} else {
 			fmt.Printf("---------------\n%s\n---------------\n", component.GetName())
-			readLogs(logs, cancel, defaultColorCode)
+			readLogs(ctx, logs, defaultColorCode)
 		}
 	}
 	if follow {
-		<-cancel
+		<-ctx.Done()
 	}
 	return nil
 }
 
-func readLogs(logs io.ReadCloser, calncel chan os.Signal, colorCode string) {
+func readLogs(ctx context.Context, logs io.ReadCloser, colorCode string) {
 	defer logs.Close()
 	scanner := bufio.NewScanner(logs)
 	for scanner.Scan() {
 		select {
-		case <-calncel:
+		case <-ctx.Done():
 			return
 		default:
 			log := scanner.Bytes()
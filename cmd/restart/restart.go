@@ -0,0 +1,53 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restart
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/cmd/start"
+	"github.com/triggermesh/tmctl/pkg/completion"
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
+	o := &start.CliOptions{
+		CRD:      crd,
+		Config:   config,
+		Manifest: m,
+		Restart:  true,
+	}
+	restartCmd := &cobra.Command{
+		Use:   "restart [broker] | [component...]",
+		Short: "Restarts TriggerMesh components",
+		Example: `tmctl restart
+tmctl restart foo-awss3source
+tmctl restart --selector kind=awss3source`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return append(completion.ListAll(o.Manifest), "--selector", "--supervise", "--overlay"), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd.Context(), args)
+		},
+	}
+	restartCmd.Flags().BoolVar(&o.Supervise, "supervise", false, "Stay in foreground and restart crashed components according to their restart policies")
+	restartCmd.Flags().StringVar(&o.Selector, "selector", "", "Restart components matching the kind, label or annotation selector (key=value[,key=value])")
+	restartCmd.Flags().StringVar(&o.Overlay, "overlay", "", "Overlay manifest name or path merged over the manifest, e.g. \"dev\" for manifest.dev.yaml")
+	return restartCmd
+}
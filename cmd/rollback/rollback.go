@@ -0,0 +1,217 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/cmd/brokers"
+	"github.com/triggermesh/tmctl/cmd/start"
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/history"
+	"github.com/triggermesh/tmctl/pkg/log"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
+	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+type CliOptions struct {
+	Config   *config.Config
+	Manifest *manifest.Manifest
+	CRD      map[string]crd.CRD
+
+	Revision int
+}
+
+func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
+	o := &CliOptions{
+		CRD:      crd,
+		Config:   config,
+		Manifest: m,
+	}
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback [broker] --rev N",
+		Short: "Restore the broker to the revision from the history",
+		Long: `Restore the broker to the revision from the history.
+
+The manifest and the broker configuration are replaced with the revision
+files, running components that were changed are restarted and the ones
+that do not exist in the revision are stopped. Rollback is recorded as
+the new revision and can be reverted too.`,
+		Example: `tmctl rollback --rev 3
+tmctl rollback foo --rev 3`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			list, _ := brokers.List(o.Config.ConfigHome, "")
+			return append(list, "--rev"), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if !start.IsBroker(o.Config.ConfigHome, args[0]) {
+					return fmt.Errorf("broker %q does not exist", args[0])
+				}
+				o.Config.Context = args[0]
+				o.Manifest = manifest.New(filepath.Join(
+					o.Config.ConfigHome,
+					o.Config.Context,
+					triggermesh.ManifestFile))
+			}
+			if err := o.Manifest.Read(); err != nil {
+				return err
+			}
+			return o.rollback(cmd.Context())
+		},
+	}
+	rollbackCmd.Flags().IntVar(&o.Revision, "rev", 0, "Revision number to restore")
+	cobra.CheckErr(rollbackCmd.MarkFlagRequired("rev"))
+	cobra.CheckErr(rollbackCmd.RegisterFlagCompletionFunc("rev", cobra.NoFileCompletions))
+	return rollbackCmd
+}
+
+func (o *CliOptions) rollback(ctx context.Context) error {
+	brokerHome := filepath.Dir(o.Manifest.Path)
+	revision, err := history.Get(brokerHome, o.Revision)
+	if err != nil {
+		return err
+	}
+	target := manifest.New(o.Manifest.Path)
+	revisionManifest := manifest.New(revision.Path(triggermesh.ManifestFile))
+	if err := revisionManifest.Read(); err != nil {
+		return fmt.Errorf("revision %d: %w", revision.Number, err)
+	}
+	target.Objects = revisionManifest.Objects
+	brokerConfig, err := revision.Read(triggermesh.BrokerConfigFile)
+	if err != nil {
+		return fmt.Errorf("revision %d: %w", revision.Number, err)
+	}
+
+	changes, err := manifest.Diff(o.Manifest.Objects, target.Objects)
+	if err != nil {
+		return err
+	}
+	// containers are checked before the manifest is replaced
+	stopped := o.running(ctx, o.Manifest, changes, manifest.Removed)
+
+	if err := target.Write(); err != nil {
+		return fmt.Errorf("manifest write: %w", err)
+	}
+	if err := tmbroker.RestoreConfig(brokerHome, brokerConfig); err != nil {
+		return fmt.Errorf("broker config write: %w", err)
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	log.Printf("Restored revision %d", revision.Number)
+	o.Manifest = target
+
+	if len(stopped) != 0 {
+		client, err := docker.NewClient()
+		if err != nil {
+			return fmt.Errorf("docker client: %w", err)
+		}
+		for _, name := range stopped {
+			log.Printf("Stopping %s\n", name)
+			if err := docker.ForceStop(ctx, name, client); err != nil {
+				log.Printf("Stopping %q: %v", name, err)
+			}
+		}
+	}
+	return o.restart(ctx, changes)
+}
+
+// restart restarts the running components affected by the changes.
+// Changed running broker restarts all its components, the broker that
+// is not running is left stopped.
+func (o *CliOptions) restart(ctx context.Context, changes []manifest.ObjectDiff) error {
+	s := &start.CliOptions{
+		Config:   o.Config,
+		Manifest: o.Manifest,
+		CRD:      o.CRD,
+		Restart:  true,
+	}
+	for _, change := range changes {
+		if change.Kind != tmbroker.BrokerKind || change.Change != manifest.Modified {
+			continue
+		}
+		b, err := tmbroker.New(change.Name, o.Config.ConfigHome, o.Config.Triggermesh.Broker)
+		if err != nil {
+			return fmt.Errorf("creating broker object: %w", err)
+		}
+		if container, err := b.(triggermesh.Runnable).Info(ctx); err != nil || !container.Online {
+			break
+		}
+		return s.Run(ctx, []string{change.Name})
+	}
+	running := o.running(ctx, o.Manifest, o.affected(changes), manifest.Modified)
+	if len(running) == 0 {
+		return nil
+	}
+	return s.Run(ctx, running)
+}
+
+// affected adds the components using the modified secrets to the changes.
+func (o *CliOptions) affected(changes []manifest.ObjectDiff) []manifest.ObjectDiff {
+	result := append([]manifest.ObjectDiff{}, changes...)
+	for _, change := range changes {
+		if change.Kind != "Secret" || change.Change != manifest.Modified {
+			continue
+		}
+		for _, object := range o.Manifest.SecretUsers(change.Name) {
+			result = append(result, manifest.ObjectDiff{
+				Change: manifest.Modified,
+				Kind:   object.Kind,
+				Name:   object.Metadata.Name,
+			})
+		}
+	}
+	return result
+}
+
+// running returns the names of the changed components
+// that have running containers, the broker is not included.
+func (o *CliOptions) running(ctx context.Context, m *manifest.Manifest, changes []manifest.ObjectDiff, changeType string) []string {
+	var result []string
+	seen := make(map[string]struct{})
+	for _, change := range changes {
+		if change.Change != changeType || change.Kind == tmbroker.TriggerKind || change.Kind == tmbroker.BrokerKind {
+			continue
+		}
+		if _, exists := seen[change.Name]; exists {
+			continue
+		}
+		seen[change.Name] = struct{}{}
+		c, err := components.GetObject(change.Name, o.Config, m, o.CRD)
+		if err != nil || c == nil {
+			continue
+		}
+		r, ok := c.(triggermesh.Runnable)
+		if !ok {
+			continue
+		}
+		if container, err := r.Info(ctx); err == nil && container.Online {
+			result = append(result, c.GetName())
+		}
+	}
+	return result
+}
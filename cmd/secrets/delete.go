@@ -0,0 +1,64 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/completion"
+	"github.com/triggermesh/tmctl/pkg/log"
+)
+
+func (o *CliOptions) deleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete <name>...",
+		Short:   "Delete the secrets that are not used by the components",
+		Example: "tmctl secret delete foo-secret",
+		Args:    cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.ListObjectsByKind("Secret", o.Manifest), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.delete(args)
+		},
+	}
+}
+
+func (o *CliOptions) delete(names []string) error {
+	for _, name := range names {
+		if _, err := o.secret(name); err != nil {
+			return err
+		}
+		if users := o.Manifest.SecretUsers(name); len(users) != 0 {
+			var list []string
+			for _, user := range users {
+				list = append(list, user.Metadata.Name)
+			}
+			return fmt.Errorf("secret %q is used by %s", name, strings.Join(list, ", "))
+		}
+	}
+	for _, name := range names {
+		if err := o.Manifest.Remove(name, "Secret"); err != nil {
+			return fmt.Errorf("deleting %q: %w", name, err)
+		}
+		log.Printf("Secret %q is deleted", name)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/completion"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	tmsecrets "github.com/triggermesh/tmctl/pkg/secrets"
+)
+
+const hiddenValue = "********"
+
+func (o *CliOptions) getCmd() *cobra.Command {
+	getCmd := &cobra.Command{
+		Use:   "get <name> [key]",
+		Short: "Show the secret values",
+		Long: `Show the secret values.
+
+Values are hidden unless --reveal is set, references to the external
+sources are shown as is and resolved with --reveal.`,
+		Example: `tmctl secret get foo-secret
+tmctl secret get foo-secret token --reveal`,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completion.ListObjectsByKind("Secret", o.Manifest), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.get(args)
+		},
+	}
+	getCmd.Flags().BoolVar(&o.Reveal, "reveal", false, "Show the plain secret values")
+	return getCmd
+}
+
+func (o *CliOptions) get(args []string) error {
+	secret, err := o.secret(args[0])
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		if len(args) == 2 && key != args[1] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("secret %q does not have the key %q", args[0], args[1])
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := secret.Data[key]
+		switch {
+		case o.Reveal:
+			if value, err = plain(value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		case !tmsecrets.IsReference(value):
+			value = hiddenValue
+		}
+		if len(args) == 2 {
+			fmt.Println(value)
+			continue
+		}
+		fmt.Printf("%s=%s\n", key, value)
+	}
+	return nil
+}
+
+// secret returns the secret object from the manifest.
+func (o *CliOptions) secret(name string) (kubernetes.Object, error) {
+	for _, object := range o.Manifest.Objects {
+		if object.Kind == "Secret" && object.Metadata.Name == name {
+			return object, nil
+		}
+	}
+	return kubernetes.Object{}, fmt.Errorf("secret %q does not exist", name)
+}
+
+// plain returns the decoded secret value or the resolved reference.
+func plain(value string) (string, error) {
+	switch {
+	case tmsecrets.IsEncrypted(value):
+		return "", tmsecrets.ErrNoKey
+	case tmsecrets.IsReference(value):
+		return tmsecrets.Resolve(value)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("decoding secret value: %w", err)
+	}
+	return string(decoded), nil
+}
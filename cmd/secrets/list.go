@@ -0,0 +1,69 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func (o *CliOptions) listCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List the broker secrets and the components using them",
+		Example: "tmctl secret list",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.list()
+		},
+	}
+}
+
+func (o *CliOptions) list() error {
+	w := tabwriter.NewWriter(os.Stdout, 10, 5, 5, ' ', 0)
+	fmt.Fprintln(w, "Secret\tKeys\tUsed by")
+	var found bool
+	for _, object := range o.Manifest.Objects {
+		if object.Kind != "Secret" {
+			continue
+		}
+		found = true
+		keys := make([]string, 0, len(object.Data))
+		for key := range object.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var users []string
+		for _, user := range o.Manifest.SecretUsers(object.Metadata.Name) {
+			users = append(users, user.Metadata.Name)
+		}
+		if len(users) == 0 {
+			users = []string{"-"}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", object.Metadata.Name, strings.Join(keys, ","), strings.Join(users, ","))
+	}
+	if !found {
+		fmt.Println("No secrets")
+		return nil
+	}
+	return w.Flush()
+}
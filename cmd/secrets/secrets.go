@@ -0,0 +1,189 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/history"
+	"github.com/triggermesh/tmctl/pkg/journal"
+	"github.com/triggermesh/tmctl/pkg/log"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	tmsecrets "github.com/triggermesh/tmctl/pkg/secrets"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+type CliOptions struct {
+	Config   *config.Config
+	Manifest *manifest.Manifest
+	CRD      map[string]crd.CRD
+
+	Reveal bool
+}
+
+func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
+	o := &CliOptions{
+		CRD:      crd,
+		Config:   config,
+		Manifest: m,
+	}
+	secretsCmd := &cobra.Command{
+		Use:     "secrets [list|get|set|delete|rotate-key]",
+		Aliases: []string{"secret"},
+		Short:   "Manage the broker secrets",
+		Long: `Manage the broker secrets.
+
+Secrets are created from the component credentials and named
+"<component>-secret". Secret values may reference the external sources
+instead of holding the value: env:NAME, file:PATH or keyring:SERVICE/USER.
+
+When the encryption key is set, secret values are stored in the manifest
+encrypted with age (https://age-encryption.org) as "age:<base64>" and
+decrypted transparently by the CLI. Values are regular base64-encoded age
+files and can be decrypted with the age tool too. The key is the age X25519
+identity, e.g. generated by age-keygen, read from the ` + tmsecrets.KeyEnv + `
+environment variable or from the ` + tmsecrets.KeyFile + ` file in the CLI home
+directory, the key file is never stored in the workspace.`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if cmd.Name() == "rotate-key" {
+				return nil
+			}
+			return o.Manifest.Read()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+	secretsCmd.AddCommand(o.listCmd())
+	secretsCmd.AddCommand(o.getCmd())
+	secretsCmd.AddCommand(o.setCmd())
+	secretsCmd.AddCommand(o.deleteCmd())
+	secretsCmd.AddCommand(o.rotateKeyCmd())
+	return secretsCmd
+}
+
+func (o *CliOptions) rotateKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Generate the new encryption key and re-encrypt the secrets",
+		Long: `Generate the new encryption key and re-encrypt the secrets.
+
+Secrets of all brokers in the current workspace and in the home directory
+are re-encrypted, including the manifest overlays, the history revisions
+and the external resources journal. If the key does not exist yet, it is created and the plain
+secrets are encrypted. Interrupted rotation is resumed with the same key.`,
+		Example: "tmctl secrets rotate-key",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.rotateKey()
+		},
+	}
+}
+
+func (o *CliOptions) rotateKey() error {
+	if err := tmsecrets.Rotate(); err != nil {
+		return fmt.Errorf("rotate key: %w", err)
+	}
+	homes := []string{o.Config.ConfigHome}
+	if home := config.HomeAbsPath(); home != o.Config.ConfigHome {
+		homes = append(homes, home)
+	}
+	var failed []string
+	for _, home := range homes {
+		entries, err := os.ReadDir(home)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range entries {
+			brokerHome := filepath.Join(home, entry.Name())
+			if _, err := os.Stat(filepath.Join(brokerHome, triggermesh.ManifestFile)); err != nil {
+				continue
+			}
+			failed = append(failed, encryptBroker(brokerHome)...)
+		}
+	}
+	records, _ := journal.Files()
+	for _, record := range records {
+		if err := manifest.EncryptFile(record); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", record, err))
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("some secrets could not be re-encrypted, fix them and run the command again to finish the rotation:\n%s",
+			strings.Join(failed, "\n"))
+	}
+	if err := tmsecrets.Finish(); err != nil {
+		return fmt.Errorf("remove previous key: %w", err)
+	}
+	log.Printf("Secrets are encrypted with the key %s", tmsecrets.KeyFilePath())
+	return nil
+}
+
+// encryptBroker encrypts the broker manifest secrets with the current
+// key and returns the files that failed.
+func encryptBroker(brokerHome string) []string {
+	var failed []string
+	manifestPath := filepath.Join(brokerHome, triggermesh.ManifestFile)
+	if err := encryptManifest(manifestPath); err != nil {
+		failed = append(failed, fmt.Sprintf("%s: %v", manifestPath, err))
+	}
+	overlays, _ := filepath.Glob(filepath.Join(brokerHome, "manifest.*.yaml"))
+	for _, overlay := range overlays {
+		if err := manifest.ReencryptFile(overlay); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", overlay, err))
+		}
+	}
+	revisions, _ := filepath.Glob(filepath.Join(brokerHome, history.Dir, "*", triggermesh.ManifestFile))
+	for _, revision := range revisions {
+		if err := manifest.EncryptFile(revision); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", revision, err))
+		}
+	}
+	return failed
+}
+
+// encryptManifest writes the manifest with secrets back,
+// the change is recorded in the broker history.
+func encryptManifest(path string) error {
+	m := manifest.New(path)
+	if err := m.Read(); err != nil {
+		return err
+	}
+	var hasSecrets bool
+	for _, object := range m.Objects {
+		if object.Kind != "Secret" {
+			continue
+		}
+		for k, v := range object.Data {
+			if tmsecrets.IsEncrypted(v) {
+				return fmt.Errorf("secret %q: %s: %w", object.Metadata.Name, k, tmsecrets.ErrNoKey)
+			}
+			hasSecrets = true
+		}
+	}
+	if !hasSecrets {
+		return nil
+	}
+	return m.Write()
+}
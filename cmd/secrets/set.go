@@ -0,0 +1,117 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/cmd/start"
+	"github.com/triggermesh/tmctl/pkg/completion"
+	"github.com/triggermesh/tmctl/pkg/log"
+	tmsecrets "github.com/triggermesh/tmctl/pkg/secrets"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components/secret"
+)
+
+func (o *CliOptions) setCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <name> <key>=<value>...",
+		Short: "Update the secret values",
+		Long: `Update the secret values.
+
+Values are set in plain text, references to the external sources are
+stored as is. Running components that use the secret are restarted to
+apply the new values.`,
+		Example: `tmctl secret set foo-secret token=bar
+tmctl secret set foo-secret token=env:FOO_TOKEN`,
+		Args: cobra.MinimumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return []string{}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completion.ListObjectsByKind("Secret", o.Manifest), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.set(cmd.Context(), args[0], args[1:])
+		},
+	}
+}
+
+func (o *CliOptions) set(ctx context.Context, name string, pairs []string) error {
+	object, err := o.secret(name)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]string, len(object.Data)+len(pairs))
+	for key, value := range object.Data {
+		data[key] = value
+	}
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return fmt.Errorf("malformed value %q, must be key=value", pair)
+		}
+		if !tmsecrets.IsReference(value) {
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		data[key] = value
+	}
+	updated, err := o.Manifest.Add(secret.New(name, o.Config.Context, data))
+	if err != nil {
+		return fmt.Errorf("manifest write: %w", err)
+	}
+	if !updated {
+		log.Printf("Secret %q is unchanged", name)
+		return nil
+	}
+	log.Printf("Secret %q is updated", name)
+	return o.restartUsers(ctx, name)
+}
+
+// restartUsers restarts the running components that use the secret.
+func (o *CliOptions) restartUsers(ctx context.Context, name string) error {
+	var running []string
+	for _, object := range o.Manifest.SecretUsers(name) {
+		c, err := components.GetObject(object.Metadata.Name, o.Config, o.Manifest, o.CRD)
+		if err != nil || c == nil {
+			continue
+		}
+		r, ok := c.(triggermesh.Runnable)
+		if !ok {
+			continue
+		}
+		if container, err := r.Info(ctx); err == nil && container.Online {
+			running = append(running, c.GetName())
+		}
+	}
+	if len(running) == 0 {
+		return nil
+	}
+	s := &start.CliOptions{
+		Config:   o.Config,
+		Manifest: o.Manifest,
+		CRD:      o.CRD,
+		Restart:  true,
+	}
+	return s.Run(ctx, running)
+}
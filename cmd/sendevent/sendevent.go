@@ -72,7 +72,7 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crd map[string]c
 				}
 
 				for _, event := range events {
-					err := o.send(eventType, target, event)
+					err := o.send(cmd.Context(), eventType, target, event)
 					if err != nil {
 						fmt.Printf("Failed to send event: %v\n", err)
 					}
@@ -81,7 +81,7 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crd map[string]c
 				return nil
 			}
 
-			return o.send(eventType, target, strings.Join(args, " "))
+			return o.send(cmd.Context(), eventType, target, strings.Join(args, " "))
 		},
 	}
 	sendCmd.Flags().StringVar(&target, "target", "", "Component to send the event to. Default is the broker")
@@ -97,8 +97,7 @@ func NewCmd(config *config.Config, manifest *manifest.Manifest, crd map[string]c
 	return sendCmd
 }
 
-func (o *CliOptions) send(eventType, target, data string) error {
-	ctx := context.Background()
+func (o *CliOptions) send(ctx context.Context, eventType, target, data string) error {
 	component, err := components.GetObject(target, o.Config, o.Manifest, o.CRD)
 	if err != nil {
 		return fmt.Errorf("destination target: %w", err)
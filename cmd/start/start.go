@@ -19,13 +19,20 @@ package start
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/triggermesh/tmctl/pkg/completion"
 	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/journal"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/log"
 	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/output"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
 	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
@@ -33,12 +40,20 @@ import (
 	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
 )
 
+// abortTimeout limits the time spent on stopping the containers
+// when the start is interrupted, command context is already canceled.
+const abortTimeout = 30 * time.Second
+
 type CliOptions struct {
 	Config   *config.Config
 	Manifest *manifest.Manifest
 	CRD      map[string]crd.CRD
 
-	Restart bool
+	Restart   bool
+	Supervise bool
+	Selector  string
+	Overlay   string
+	DryRun    bool
 }
 
 func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
@@ -48,45 +63,123 @@ func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD)
 		Manifest: m,
 	}
 	startCmd := &cobra.Command{
-		Use:     "start [broker]",
-		Short:   "Starts TriggerMesh components",
-		Example: "tmctl start",
-		Args:    cobra.RangeArgs(0, 1),
+		Use:   "start [broker] | [component...]",
+		Short: "Starts TriggerMesh components",
+		Long: `Starts TriggerMesh components.
+
+The ${VAR} and ${VAR:-default} references in the manifest are replaced with
+the environment variables, "$${" is the escaped "${" sequence. The overlay
+manifest values are merged over the manifest ones. The manifest file keeps
+the references and the values are never written back.`,
+		Example: `tmctl start
+tmctl start foo-awss3source --restart
+tmctl start --selector kind=awss3source
+tmctl start --dry-run`,
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
-			return []string{"--restart", "--version"}, cobra.ShellCompDirectiveNoFileComp
+			return append(completion.ListAll(o.Manifest), "--restart", "--selector", "--supervise", "--overlay", "--dry-run", "--version"), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 0 {
-				o.Config.Context = args[0]
-				o.Manifest = manifest.New(filepath.Join(
-					o.Config.ConfigHome,
-					o.Config.Context,
-					triggermesh.ManifestFile))
-			}
-			cobra.CheckErr(o.Manifest.Read())
-			return o.start()
+			return o.Run(cmd.Context(), args)
 		},
 	}
 	startCmd.Flags().BoolVar(&o.Restart, "restart", false, "Restart components")
+	startCmd.Flags().BoolVar(&o.Supervise, "supervise", false, "Stay in foreground and restart crashed components according to their restart policies")
+	startCmd.Flags().StringVar(&o.Selector, "selector", "", "Start components matching the kind, label or annotation selector (key=value[,key=value])")
+	startCmd.Flags().StringVar(&o.Overlay, "overlay", "", "Overlay manifest name or path merged over the manifest, e.g. \"dev\" for manifest.dev.yaml")
+	startCmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "Print the external resources that would be created or modified, nothing is started")
 	return startCmd
 }
 
-func (o *CliOptions) start() error {
-	ctx := context.Background()
+// Run starts the broker and all its components. If the arguments are the
+// component names or the selector is set, only matching components of the
+// current broker are started and the broker itself is not touched.
+func (o *CliOptions) Run(ctx context.Context, args []string) error {
+	if len(args) == 1 && o.Selector == "" && IsBroker(o.Config.ConfigHome, args[0]) {
+		o.Config.Context = args[0]
+		o.Manifest = manifest.New(filepath.Join(
+			o.Config.ConfigHome,
+			o.Config.Context,
+			triggermesh.ManifestFile))
+		args = []string{}
+	}
+	if err := o.Manifest.Read(); err != nil {
+		return err
+	}
+	if err := o.Manifest.Resolve(manifest.OverlayPath(o.Manifest.Path, o.Overlay)); err != nil {
+		return err
+	}
+	if len(args) == 0 && o.Selector == "" {
+		if o.DryRun {
+			return o.plan(o.Manifest.Objects)
+		}
+		return o.start(ctx)
+	}
+	selector, err := manifest.ParseSelector(o.Selector)
+	if err != nil {
+		return err
+	}
+	objects, err := o.Manifest.Select(args, selector)
+	if err != nil {
+		return err
+	}
+	if o.DryRun {
+		return o.plan(objects)
+	}
+	return o.startComponents(ctx, objects)
+}
+
+// plan prints the external resources that the components initialization
+// would create or modify. No cloud APIs or containers are touched.
+func (o *CliOptions) plan(objects []kubernetes.Object) error {
+	planned := false
+	for _, object := range objects {
+		c, _ := components.GetObject(object.Metadata.Name, o.Config, o.Manifest, o.CRD)
+		reconcilable, ok := c.(triggermesh.Reconcilable)
+		if !ok {
+			continue
+		}
+		changes, err := reconcilable.Plan()
+		if err != nil {
+			return fmt.Errorf("%q external resources: %w", c.GetName(), err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		output.PrintPlan(c, changes)
+		planned = true
+	}
+	if !planned {
+		fmt.Println("No external resources would be created or modified")
+	}
+	return nil
+}
+
+// IsBroker returns true if the name is the broker with the manifest
+// in the configuration directory.
+func IsBroker(configHome, name string) bool {
+	_, err := os.Stat(filepath.Join(configHome, name, triggermesh.ManifestFile))
+	return err == nil
+}
+
+func (o *CliOptions) start(ctx context.Context) error {
+	r := o.begin()
 	var brokerPort string
+	var containers []*docker.Container
 	// start eventing first
 	for _, object := range o.Manifest.Objects {
 		if object.Kind == tmbroker.BrokerKind {
-			b, err := tmbroker.New(object.Metadata.Name, o.Config.Triggermesh.Broker)
+			b, err := tmbroker.New(object.Metadata.Name, o.Config.ConfigHome, o.Config.Triggermesh.Broker)
 			if err != nil {
 				return fmt.Errorf("creating broker object: %w", err)
 			}
 			log.Println("Starting broker")
+			r.track(ctx, b.(triggermesh.Runnable), o.Restart)
 			container, err := b.(triggermesh.Runnable).Start(ctx, nil, o.Restart)
 			if err != nil {
-				return fmt.Errorf("starting broker container: %w", err)
+				return r.abortIfInterrupted(ctx, fmt.Errorf("starting broker container: %w", err))
 			}
 			brokerPort = container.HostPort()
+			containers = append(containers, container)
 		}
 	}
 
@@ -94,56 +187,184 @@ func (o *CliOptions) start() error {
 		if object.APIVersion == tmbroker.APIVersion {
 			continue
 		}
-		c, _ := components.GetObject(object.Metadata.Name, o.Config, o.Manifest, o.CRD)
-		if c == nil {
-			continue
+		if ctx.Err() != nil {
+			return r.abortIfInterrupted(ctx, ctx.Err())
+		}
+		container, err := o.startComponent(ctx, r, object.Metadata.Name, brokerPort)
+		if err != nil {
+			return r.abortIfInterrupted(ctx, err)
+		}
+		if container != nil {
+			containers = append(containers, container)
 		}
-		if _, ok := c.(triggermesh.Runnable); !ok {
+	}
+	if o.Supervise {
+		return supervise(ctx, containers)
+	}
+	return nil
+}
+
+func (o *CliOptions) startComponents(ctx context.Context, objects []kubernetes.Object) error {
+	broker, err := tmbroker.New(o.Config.Context, o.Config.ConfigHome, o.Config.Triggermesh.Broker)
+	if err != nil {
+		return fmt.Errorf("broker object: %w", err)
+	}
+	brokerPort, err := broker.(triggermesh.Consumer).GetPort(ctx)
+	if err != nil {
+		return fmt.Errorf("broker offline: %w", err)
+	}
+	r := o.begin()
+	var containers []*docker.Container
+	for _, object := range objects {
+		if object.APIVersion == tmbroker.APIVersion {
 			continue
 		}
-		if _, ok := c.(triggermesh.Producer); ok {
-			sink := "http://host.docker.internal:" + brokerPort
-			spec := c.GetSpec()
-			if spec == nil {
-				spec = make(map[string]interface{})
+		if ctx.Err() != nil {
+			return r.abortIfInterrupted(ctx, ctx.Err())
+		}
+		container, err := o.startComponent(ctx, r, object.Metadata.Name, brokerPort)
+		if err != nil {
+			return r.abortIfInterrupted(ctx, err)
+		}
+		if container != nil {
+			containers = append(containers, container)
+		}
+	}
+	if o.Supervise {
+		return supervise(ctx, containers)
+	}
+	return nil
+}
+
+func (o *CliOptions) startComponent(ctx context.Context, r *run, name, brokerPort string) (*docker.Container, error) {
+	c, _ := components.GetObject(name, o.Config, o.Manifest, o.CRD)
+	if c == nil {
+		return nil, nil
+	}
+	if _, ok := c.(triggermesh.Runnable); !ok {
+		return nil, nil
+	}
+	if _, ok := c.(triggermesh.Producer); ok {
+		sink := "http://host.docker.internal:" + brokerPort
+		if service, ok := c.(*service.Service); ok {
+			if service.IsSource() {
+				c.SetSpec(map[string]interface{}{"K_SINK": sink})
 			}
-			if service, ok := c.(*service.Service); ok && service.IsSource() {
-				spec["K_SINK"] = sink
-			} else {
-				spec["sink"] = map[string]interface{}{"uri": sink}
+		} else {
+			// the spec is shared with the manifest object,
+			// runtime sink must not end up in the manifest
+			spec := make(map[string]interface{}, len(c.GetSpec())+1)
+			for k, v := range c.GetSpec() {
+				spec[k] = v
 			}
+			spec["sink"] = map[string]interface{}{"uri": sink}
+			c.SetSpec(spec)
 		}
-		secrets := make(map[string]string, 0)
-		if parent, ok := c.(triggermesh.Parent); ok {
-			_, secretsEnv, err := components.ProcessSecrets(parent, o.Manifest)
-			if err != nil {
-				return fmt.Errorf("processing secrets: %w", err)
-			}
-			secrets = secretsEnv
+	}
+	secrets := make(map[string]string, 0)
+	if parent, ok := c.(triggermesh.Parent); ok {
+		_, secretsEnv, err := components.ProcessSecrets(parent, o.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("processing secrets: %w", err)
 		}
-		if reconcilable, ok := c.(triggermesh.Reconcilable); ok {
-			status, err := reconcilable.Initialize(ctx, secrets)
-			if err != nil {
-				return fmt.Errorf("external services initialization: %w", err)
-			}
-			reconcilable.UpdateStatus(status)
+		secrets = secretsEnv
+	}
+	if reconcilable, ok := c.(triggermesh.Reconcilable); ok {
+		status, err := reconcilable.Initialize(ctx, secrets)
+		if err != nil {
+			return nil, fmt.Errorf("external services initialization: %w", err)
 		}
-		log.Printf("Starting %s\n", object.Metadata.Name)
-		if _, err := c.(triggermesh.Runnable).Start(ctx, secrets, o.Restart); err != nil {
-			return fmt.Errorf("starting component %q: %w", c.GetName(), err)
+		reconcilable.UpdateStatus(status)
+		if err := journal.Record(o.Config.ConfigHome, c, o.Manifest); err != nil {
+			log.Printf("Recording %s external resources: %v", name, err)
 		}
-		if _, ok := c.(triggermesh.Consumer); ok {
-			triggers, err := tmbroker.GetTargetTriggers(c.GetName(), o.Config.Context, o.Config.ConfigHome)
-			if err != nil {
-				return fmt.Errorf("%q target triggers: %w", c.GetName(), err)
-			}
-			for _, t := range triggers {
-				t.(*tmbroker.Trigger).SetTarget(c)
-				if err := t.(*tmbroker.Trigger).WriteLocalConfig(); err != nil {
-					return fmt.Errorf("updating broker config: %w", err)
-				}
+	}
+	log.Printf("Starting %s\n", name)
+	r.track(ctx, c.(triggermesh.Runnable), o.Restart)
+	container, err := c.(triggermesh.Runnable).Start(ctx, secrets, o.Restart)
+	if err != nil {
+		return nil, fmt.Errorf("starting component %q: %w", c.GetName(), err)
+	}
+	if err := components.PinHostPort(c, container, o.Manifest); err != nil {
+		return nil, err
+	}
+	if _, ok := c.(triggermesh.Consumer); ok {
+		triggers, err := tmbroker.GetTargetTriggers(c.GetName(), o.Config.Context, o.Config.ConfigHome)
+		if err != nil {
+			return nil, fmt.Errorf("%q target triggers: %w", c.GetName(), err)
+		}
+		for _, t := range triggers {
+			t.(*tmbroker.Trigger).SetTarget(c)
+			if err := t.(*tmbroker.Trigger).WriteLocalConfig(); err != nil {
+				return nil, fmt.Errorf("updating broker config: %w", err)
 			}
 		}
 	}
-	return nil
+	return container, nil
+}
+
+// run keeps the containers started by the command and the broker
+// configuration they change, so that the start could be undone
+// if it is interrupted.
+type run struct {
+	brokerHome       string
+	brokerConfigData []byte
+	started          []triggermesh.Runnable
+}
+
+func (o *CliOptions) begin() *run {
+	r := &run{
+		brokerHome: filepath.Join(o.Config.ConfigHome, o.Config.Context),
+	}
+	r.brokerConfigData, _ = os.ReadFile(filepath.Join(r.brokerHome, triggermesh.BrokerConfigFile))
+	return r
+}
+
+// track records the component that is about to be started unless its
+// container is already running and will be left untouched.
+func (r *run) track(ctx context.Context, runnable triggermesh.Runnable, restart bool) {
+	if !restart {
+		if container, err := runnable.Info(ctx); err == nil && container.Online {
+			return
+		}
+	}
+	r.started = append(r.started, runnable)
+}
+
+// abortIfInterrupted stops the containers started by the command and restores
+// the broker configuration if the command context is canceled.
+func (r *run) abortIfInterrupted(ctx context.Context, cause error) error {
+	if ctx.Err() == nil {
+		return cause
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	log.Println("Interrupted, stopping components started by this command")
+	for i := len(r.started) - 1; i >= 0; i-- {
+		if err := r.started[i].Stop(ctx); err != nil {
+			log.Printf("Stopping container: %v", err)
+		}
+	}
+	if r.brokerConfigData != nil {
+		if err := tmbroker.RestoreConfig(r.brokerHome, r.brokerConfigData); err != nil {
+			log.Printf("Restoring broker configuration: %v", err)
+		}
+	}
+	return cause
+}
+
+func supervise(ctx context.Context, containers []*docker.Container) error {
+	client, err := docker.NewClient()
+	if err != nil {
+		return fmt.Errorf("docker client: %w", err)
+	}
+	supervisor := docker.NewSupervisor(client)
+	for _, container := range containers {
+		if err := supervisor.Add(ctx, container); err != nil {
+			return fmt.Errorf("supervising containers: %w", err)
+		}
+	}
+	log.Println("Supervising components, press Ctrl+C to exit")
+	return supervisor.Run(ctx)
 }
@@ -23,8 +23,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/triggermesh/tmctl/cmd/start"
+	"github.com/triggermesh/tmctl/pkg/completion"
 	"github.com/triggermesh/tmctl/pkg/config"
 	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/log"
 	"github.com/triggermesh/tmctl/pkg/manifest"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
@@ -34,6 +37,8 @@ import (
 type CliOptions struct {
 	Config   *config.Config
 	Manifest *manifest.Manifest
+
+	Selector string
 }
 
 func NewCmd(config *config.Config, m *manifest.Manifest) *cobra.Command {
@@ -41,37 +46,57 @@ func NewCmd(config *config.Config, m *manifest.Manifest) *cobra.Command {
 		Config:   config,
 		Manifest: m,
 	}
-	return &cobra.Command{
-		Use:     "stop [broker]",
-		Short:   "Stops TriggerMesh components, removes docker containers",
-		Example: "tmctl stop",
-		Args:    cobra.RangeArgs(0, 1),
+	stopCmd := &cobra.Command{
+		Use:   "stop [broker] | [component...]",
+		Short: "Stops TriggerMesh components, removes docker containers",
+		Example: `tmctl stop
+tmctl stop foo-awss3source
+tmctl stop --selector kind=awss3source`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return append(completion.ListAll(o.Manifest), "--selector"), cobra.ShellCompDirectiveNoFileComp
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 0 {
+			if len(args) == 1 && o.Selector == "" && start.IsBroker(o.Config.ConfigHome, args[0]) {
 				o.Config.Context = args[0]
 				o.Manifest = manifest.New(filepath.Join(
 					o.Config.ConfigHome,
 					o.Config.Context,
 					triggermesh.ManifestFile))
+				args = []string{}
 			}
 			cobra.CheckErr(o.Manifest.Read())
-			return o.stop()
+			if len(args) == 0 && o.Selector == "" {
+				return o.stop(cmd.Context(), o.Manifest.Objects, true)
+			}
+			selector, err := manifest.ParseSelector(o.Selector)
+			if err != nil {
+				return err
+			}
+			objects, err := o.Manifest.Select(args, selector)
+			if err != nil {
+				return err
+			}
+			return o.stop(cmd.Context(), objects, false)
 		},
 	}
+	stopCmd.Flags().StringVar(&o.Selector, "selector", "", "Stop components matching the kind, label or annotation selector (key=value[,key=value])")
+	return stopCmd
 }
 
-func (o *CliOptions) stop() error {
-	ctx := context.Background()
+func (o *CliOptions) stop(ctx context.Context, objects []kubernetes.Object, withBroker bool) error {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Errorf("docker client: %w", err)
 	}
 
-	for _, object := range o.Manifest.Objects {
+	for _, object := range objects {
 		if object.Kind == tmbroker.TriggerKind || object.Kind == "Secret" {
 			continue
 		}
 		if object.Kind == tmbroker.BrokerKind {
+			if !withBroker {
+				continue
+			}
 			wiretapContainerName := object.Metadata.Name + "-wiretap"
 			if err := docker.ForceStop(ctx, wiretapContainerName, client); err != nil {
 				log.Printf("Stopping %q: %v", wiretapContainerName, err)
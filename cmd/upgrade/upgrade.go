@@ -0,0 +1,260 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/triggermesh/tmctl/cmd/start"
+	"github.com/triggermesh/tmctl/pkg/completion"
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/log"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+type CliOptions struct {
+	Config   *config.Config
+	Manifest *manifest.Manifest
+	CRD      map[string]crd.CRD
+
+	To    string
+	Force bool
+}
+
+// upgradeResult is the upgraded manifest object.
+type upgradeResult struct {
+	index   int
+	object  kubernetes.Object
+	changes []crd.FieldChange
+}
+
+func NewCmd(config *config.Config, m *manifest.Manifest, crd map[string]crd.CRD) *cobra.Command {
+	o := &CliOptions{
+		CRD:      crd,
+		Config:   config,
+		Manifest: m,
+	}
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade [broker] | [component...]",
+		Short: "Upgrade TriggerMesh components to the new version",
+		Long: `Upgrade TriggerMesh components to the new version.
+
+Without component names, all components that are not pinned to a specific
+version are upgraded and the new version becomes the default one. Named
+components are pinned to the new version. Component specs are validated
+against the new CRDs, renamed fields are migrated and running containers
+are restarted.`,
+		Example: `tmctl upgrade
+tmctl upgrade --to v1.26.0
+tmctl upgrade foo-awss3source --to v1.26.0`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return append(completion.ListAll(o.Manifest), "--to", "--force"), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 && start.IsBroker(o.Config.ConfigHome, args[0]) {
+				o.Config.Context = args[0]
+				o.Manifest = manifest.New(filepath.Join(
+					o.Config.ConfigHome,
+					o.Config.Context,
+					triggermesh.ManifestFile))
+				args = []string{}
+			}
+			if err := o.Manifest.Read(); err != nil {
+				return err
+			}
+			return o.upgrade(cmd.Context(), args)
+		},
+	}
+	upgradeCmd.Flags().StringVar(&o.To, "to", "", "Target version, latest release by default")
+	upgradeCmd.Flags().BoolVar(&o.Force, "force", false, "Upgrade even if the specs have fields removed in the new version, the fields are dropped")
+	cobra.CheckErr(upgradeCmd.RegisterFlagCompletionFunc("to", cobra.NoFileCompletions))
+	return upgradeCmd
+}
+
+func (o *CliOptions) upgrade(ctx context.Context, names []string) error {
+	to := o.To
+	if to == "" {
+		latest, err := config.LatestRelease("triggermesh")
+		if err != nil {
+			return fmt.Errorf("latest version: %w", err)
+		}
+		to = latest
+	}
+	newCRDs, err := crd.Fetch(o.Config.CacheHome, to)
+	if err != nil {
+		return fmt.Errorf("%s CRD: %w", to, err)
+	}
+
+	selected := make(map[string]struct{}, len(names))
+	if len(names) != 0 {
+		objects, err := o.Manifest.Select(names, nil)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			selected[object.Metadata.Name] = struct{}{}
+		}
+	}
+
+	crds := map[string]map[string]crd.CRD{o.Config.Triggermesh.ComponentsVersion: o.CRD}
+	var results []upgradeResult
+	var invalid, removed []string
+	for i, object := range o.Manifest.Objects {
+		if !isVersioned(object) {
+			continue
+		}
+		if _, ok := selected[object.Metadata.Name]; len(names) != 0 && !ok {
+			continue
+		}
+		from := o.Config.Triggermesh.ComponentsVersion
+		if pinned, set := object.Metadata.Annotations[triggermesh.VersionAnnotation]; set {
+			if len(names) == 0 {
+				log.Printf("%s is pinned to %s, skipping", object.Metadata.Name, pinned)
+				continue
+			}
+			from = pinned
+		}
+		if from == to {
+			continue
+		}
+		if _, cached := crds[from]; !cached {
+			// missing old CRDs only affect the renamed fields detection
+			crds[from], _ = crd.Fetch(o.Config.CacheHome, from)
+		}
+		result, err := o.upgradeObject(object, crds[from], newCRDs, len(names) != 0, to)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", object.Metadata.Name, err))
+			continue
+		}
+		result.index = i
+		fmt.Printf("%s (%s): %s -> %s\n", object.Metadata.Name, object.Kind, from, to)
+		for _, change := range result.changes {
+			fmt.Printf("\t%s\n", change)
+			if change.Removed() && !o.Force {
+				removed = append(removed, fmt.Sprintf("%s: %s", object.Metadata.Name, change))
+			}
+		}
+		results = append(results, result)
+	}
+	if len(invalid) != 0 {
+		return fmt.Errorf("upgrade to %s is not possible:\n%s", to, strings.Join(invalid, "\n"))
+	}
+	if len(removed) != 0 {
+		return fmt.Errorf("upgrade to %s is not possible, update the specs or use --force to drop the removed fields:\n%s",
+			to, strings.Join(removed, "\n"))
+	}
+	if len(results) == 0 && len(names) != 0 {
+		log.Printf("Nothing to upgrade")
+		return nil
+	}
+
+	for _, result := range results {
+		o.Manifest.Objects[result.index] = result.object
+	}
+	if err := o.Manifest.Write(); err != nil {
+		return fmt.Errorf("manifest write: %w", err)
+	}
+	if len(names) == 0 {
+		o.Config.Triggermesh.ComponentsVersion = to
+		if err := o.Config.Save(); err != nil {
+			return fmt.Errorf("config write: %w", err)
+		}
+		log.Printf("Default components version is %s", to)
+	}
+	o.CRD = newCRDs
+	return o.restart(ctx, results)
+}
+
+// upgradeObject migrates the object spec to the new CRD and validates it.
+func (o *CliOptions) upgradeObject(object kubernetes.Object, oldCRDs, newCRDs map[string]crd.CRD, pin bool, to string) (upgradeResult, error) {
+	kind := strings.ToLower(object.Kind)
+	newCRD, exists := newCRDs[kind]
+	if !exists {
+		return upgradeResult{}, fmt.Errorf("kind %q does not exist in %s", object.Kind, to)
+	}
+	spec, changes, err := crd.MigrateSpec(object.Spec, oldCRDs[kind], newCRD, o.Force)
+	if err != nil {
+		return upgradeResult{}, err
+	}
+	for _, change := range changes {
+		if change.Removed() && !o.Force {
+			// the spec is not valid yet, report the changes only
+			return upgradeResult{object: object, changes: changes}, nil
+		}
+	}
+	upgraded, err := kubernetes.CreateObject(newCRD, object.Metadata, spec)
+	if err != nil {
+		return upgradeResult{}, err
+	}
+	object.Spec = upgraded.Spec
+	if pin {
+		annotations := make(map[string]string, len(object.Metadata.Annotations)+1)
+		for k, v := range object.Metadata.Annotations {
+			annotations[k] = v
+		}
+		annotations[triggermesh.VersionAnnotation] = to
+		object.Metadata.Annotations = annotations
+	}
+	return upgradeResult{object: object, changes: changes}, nil
+}
+
+// restart restarts the upgraded components that are running.
+func (o *CliOptions) restart(ctx context.Context, results []upgradeResult) error {
+	var running []string
+	for _, result := range results {
+		c, err := components.GetObject(result.object.Metadata.Name, o.Config, o.Manifest, o.CRD)
+		if err != nil {
+			return fmt.Errorf("creating component interface: %w", err)
+		}
+		r, ok := c.(triggermesh.Runnable)
+		if !ok {
+			continue
+		}
+		if container, err := r.Info(ctx); err == nil && container.Online {
+			running = append(running, c.GetName())
+		}
+	}
+	if len(running) == 0 {
+		return nil
+	}
+	s := &start.CliOptions{
+		Config:   o.Config,
+		Manifest: o.Manifest,
+		CRD:      o.CRD,
+		Restart:  true,
+	}
+	return s.Run(ctx, running)
+}
+
+func isVersioned(object kubernetes.Object) bool {
+	switch object.APIVersion {
+	case "sources.triggermesh.io/v1alpha1",
+		"targets.triggermesh.io/v1alpha1",
+		"flow.triggermesh.io/v1alpha1":
+		return true
+	}
+	return false
+}
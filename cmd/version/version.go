@@ -34,7 +34,7 @@ func NewCmd(ver, commit string, c *config.Config) *cobra.Command {
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 			return []string{}, cobra.ShellCompDirectiveNoFileComp
 		},
-		Run: func(_ *cobra.Command, _ []string) {
+		Run: func(cmd *cobra.Command, _ []string) {
 			fmt.Println("CLI:")
 			fmt.Println(" Version: ", ver)
 			fmt.Println(" Commit: ", commit)
@@ -42,18 +42,18 @@ func NewCmd(ver, commit string, c *config.Config) *cobra.Command {
 			fmt.Println("\nTriggerMesh:")
 			fmt.Println(" Components version: ", c.Triggermesh.ComponentsVersion)
 			fmt.Println("\nDocker:")
-			fmt.Println(" ", dockerVersion())
+			fmt.Println(" ", dockerVersion(cmd.Context()))
 		},
 	}
 	return versionCmd
 }
 
-func dockerVersion() string {
+func dockerVersion(ctx context.Context) string {
 	client, err := docker.NewClient()
 	if err != nil {
 		return fmt.Sprintf("Not available (%v)", err)
 	}
-	ver, err := client.ServerVersion(context.Background())
+	ver, err := client.ServerVersion(ctx)
 	if err != nil {
 		return fmt.Sprintf("Not available (%v)", err)
 	}
@@ -23,10 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -61,25 +58,23 @@ func NewCmd(config *config.Config) *cobra.Command {
 			if len(args) != 0 {
 				o.Config.Context = args[0]
 			}
-			return o.watch()
+			return o.watch(cmd.Context())
 		},
 	}
 	return watchCmd
 }
 
-func (o *CliOptions) watch() error {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	defer close(c)
-
-	ctx := context.Background()
+func (o *CliOptions) watch(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	w, err := wiretap.New(o.Config.Context, o.Config.ConfigHome)
 	if err != nil {
 		return fmt.Errorf("wiretap: %w", err)
 	}
 	defer func() {
-		if err := w.Cleanup(ctx); err != nil {
+		// command context is canceled by now
+		if err := w.Cleanup(context.Background()); err != nil {
 			log.Printf("Cleanup: %v", err)
 		}
 	}()
@@ -96,23 +91,23 @@ func (o *CliOptions) watch() error {
 		return fmt.Errorf("broker logs: %w", err)
 	}
 	log.Println("Watching...")
-	go listenBroker(brokerLogs, c)
-	go listenEvents(eventDisplayLogs, c)
-	go checkConnectivity(w.Destination, c)
+	go listenBroker(ctx, brokerLogs)
+	go listenEvents(ctx, eventDisplayLogs)
+	go checkConnectivity(ctx, cancel, w.Destination)
 
-	<-c
+	<-ctx.Done()
 	log.Println("Cleaning up")
 	return nil
 }
 
-func listenEvents(output io.ReadCloser, done chan os.Signal) {
-	readLogs(output, done, func(data []byte) {
+func listenEvents(ctx context.Context, output io.ReadCloser) {
+	readLogs(ctx, output, func(data []byte) {
 		fmt.Println(string(data))
 	})
 }
 
-func listenBroker(output io.ReadCloser, done chan os.Signal) {
-	readLogs(output, done, func(data []byte) {
+func listenBroker(ctx context.Context, output io.ReadCloser) {
+	readLogs(ctx, output, func(data []byte) {
 		var logItem brokerLog
 		if err := json.Unmarshal(data, &logItem); err != nil {
 			return
@@ -127,11 +122,11 @@ func listenBroker(output io.ReadCloser, done chan os.Signal) {
 	})
 }
 
-func readLogs(output io.ReadCloser, done chan os.Signal, handler func([]byte)) {
+func readLogs(ctx context.Context, output io.ReadCloser, handler func([]byte)) {
 	scanner := bufio.NewScanner(output)
 	for scanner.Scan() {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			output.Close()
 			return
 		default:
@@ -144,18 +139,18 @@ func readLogs(output io.ReadCloser, done chan os.Signal, handler func([]byte)) {
 	}
 }
 
-func checkConnectivity(destination string, done chan os.Signal) {
+func checkConnectivity(ctx context.Context, cancel context.CancelFunc, destination string) {
 	port := strings.TrimPrefix(destination, "http://host.docker.internal")
 	ticker := time.NewTicker(3 * time.Second)
 	defer ticker.Stop()
 	for {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			if _, err := net.Dial("tcp", "localhost"+port); err != nil {
 				log.Printf("Wiretap container is unreachable: %v", err)
-				done <- syscall.SIGTERM
+				cancel()
 				return
 			}
 		}
@@ -17,6 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/triggermesh/tmctl/cmd"
 	"github.com/triggermesh/tmctl/pkg/log"
 )
@@ -27,7 +32,10 @@ var (
 )
 
 func main() {
-	if err := cmd.NewRootCommand(Version, Commit).Execute(); err != nil {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := cmd.NewRootCommand(Version, Commit).ExecuteContext(ctx)
+	cancel()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
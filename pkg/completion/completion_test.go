@@ -30,27 +30,27 @@ import (
 const version = "v1.21.1"
 
 func TestListSources(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 	expectedSources := []string{"foo-awss3source", "foo-transformation"}
 	assert.Equal(t, expectedSources, ListSources(m))
 }
 
 func TestListTargets(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 	expectedTargets := []string{"sockeye", "foo-transformation"}
 	assert.Equal(t, expectedTargets, ListTargets(m))
 }
 
 func TestListAll(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 	assert.Len(t, ListAll(m), 7)
 }
 
 func TestListEventTypes(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 	expectedEventTypes := []string{
 		"com.amazon.s3.objectcreated",
@@ -66,7 +66,7 @@ func TestListEventTypes(t *testing.T) {
 }
 
 func TestFilteredEventTypes(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 	expectedFilteresEventTypes := []string{
 		"foo-transformation.output",
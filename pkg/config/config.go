@@ -27,10 +27,14 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/triggermesh/tmctl/pkg/file"
 )
 
 const (
 	defaultConfigPath = ".triggermesh/cli"
+	// WorkspaceDir is the project-local configuration directory.
+	WorkspaceDir      = ".tmctl"
 	defaultConfigFile = "config.yaml"
 	defaultContext    = ""
 
@@ -39,8 +43,12 @@ const (
 
 	defaultDockerTimeout = "5s"
 
-	MemoryBrokerImage = "gcr.io/triggermesh/memory-broker"
-	RedisBrokerImage  = "gcr.io/triggermesh/redis-broker"
+	// TriggerMeshRegistry is the upstream registry of the TriggerMesh images,
+	// the prefix that is replaced by the configured registry mirror.
+	TriggerMeshRegistry = "gcr.io/triggermesh"
+
+	MemoryBrokerImage = TriggerMeshRegistry + "/memory-broker"
+	RedisBrokerImage  = TriggerMeshRegistry + "/redis-broker"
 
 	// In-memory broker params
 	defaultMemoryBufferSize = "100"
@@ -57,6 +65,9 @@ const (
 type Config struct {
 	// Calculated attributes
 	ConfigHome string `yaml:"-"`
+	// CacheHome is the CRD cache directory, it is
+	// the home config directory for workspaces too.
+	CacheHome string `yaml:"-"`
 
 	// Persisted attributes
 	Context        string   `yaml:"context"`
@@ -67,6 +78,10 @@ type Config struct {
 
 type Docker struct {
 	StartTimeout string `yaml:"timeout"`
+	// Pull is the images pull policy: always, missing or never.
+	Pull string `yaml:"pull,omitempty"`
+	// Mirror replaces the TriggerMesh registry in the images references.
+	Mirror string `yaml:"mirror,omitempty"`
 }
 
 type TmConfig struct {
@@ -112,7 +127,7 @@ func New() (*Config, error) {
 }
 
 func (c *Config) createDefault() error {
-	if err := os.MkdirAll(c.ConfigHome, os.ModePerm); err != nil {
+	if err := os.MkdirAll(c.ConfigHome, file.DirPerm); err != nil {
 		return err
 	}
 	c.Context = defaultContext
@@ -130,21 +145,30 @@ func (c *Config) createDefault() error {
 }
 
 func latestOrDefaultTag(project, defaultVersion string) string {
-	r, err := http.Get("https://api.github.com/repos/triggermesh/" + project + "/releases/latest")
+	tag, err := LatestRelease(project)
 	if err != nil {
 		return defaultVersion
 	}
+	return tag
+}
+
+// LatestRelease returns the latest release tag of the TriggerMesh project on GitHub.
+func LatestRelease(project string) (string, error) {
+	r, err := http.Get("https://api.github.com/repos/triggermesh/" + project + "/releases/latest")
+	if err != nil {
+		return "", err
+	}
 	defer r.Body.Close()
 	if r.StatusCode != http.StatusOK {
-		return defaultVersion
+		return "", fmt.Errorf("latest %s release request failed: %s", project, r.Status)
 	}
 	var release struct {
 		TagName string `json:"tag_name"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&release); err != nil {
-		return defaultVersion
+		return "", fmt.Errorf("latest %s release: %w", project, err)
 	}
-	return release.TagName
+	return release.TagName, nil
 }
 
 func (c *Config) Save() error {
@@ -152,7 +176,7 @@ func (c *Config) Save() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(c.ConfigHome, defaultConfigFile), data, 0644)
+	return file.WriteAtomic(filepath.Join(c.ConfigHome, defaultConfigFile), data, file.PrivatePerm)
 }
 
 func Get(key string) (string, error) {
@@ -179,6 +203,54 @@ func Set(key, value string) error {
 	return c.Save()
 }
 
+// InitWorkspace creates the workspace in the directory with
+// the copy of the home config.
+func InitWorkspace(dir string) (*Config, error) {
+	c, err := loadConfig(HomeAbsPath())
+	if os.IsNotExist(err) {
+		if err := c.createDefault(); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	c.ConfigHome = filepath.Join(dir, WorkspaceDir)
+	c.Context = defaultContext
+	if err := os.MkdirAll(c.ConfigHome, file.DirPerm); err != nil {
+		return nil, err
+	}
+	return c, c.Save()
+}
+
+// FindWorkspace returns the nearest workspace directory walking up
+// from the working directory or empty string if there is none.
+func FindWorkspace() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		workspace := filepath.Join(dir, WorkspaceDir)
+		if stat, err := os.Stat(workspace); err == nil && stat.IsDir() {
+			return workspace
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ConfigHomePath returns the nearest workspace directory,
+// the home config directory if there is no workspace.
+func ConfigHomePath() string {
+	if workspace := FindWorkspace(); workspace != "" {
+		return workspace
+	}
+	return HomeAbsPath()
+}
+
 func HomeAbsPath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -246,8 +318,13 @@ func readValue(keys []string, t reflect.Type, v reflect.Value) string {
 }
 
 func loadDefaultConfig() (*Config, error) {
+	return loadConfig(ConfigHomePath())
+}
+
+func loadConfig(configHome string) (*Config, error) {
 	c := &Config{
-		ConfigHome: HomeAbsPath(),
+		ConfigHome: configHome,
+		CacheHome:  HomeAbsPath(),
 	}
 	configFile, err := os.ReadFile(filepath.Join(c.ConfigHome, defaultConfigFile))
 	if err != nil {
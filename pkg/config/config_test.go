@@ -0,0 +1,46 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindWorkspace(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	nested := filepath.Join(root, "project", "src", "pkg")
+	assert.NoError(t, os.MkdirAll(nested, os.ModePerm))
+
+	assert.NoError(t, os.Chdir(nested))
+	assert.Equal(t, "", FindWorkspace())
+	assert.Equal(t, HomeAbsPath(), ConfigHomePath())
+
+	workspace := filepath.Join(root, "project", WorkspaceDir)
+	assert.NoError(t, os.Mkdir(workspace, os.ModePerm))
+	assert.Equal(t, workspace, FindWorkspace())
+	assert.Equal(t, workspace, ConfigHomePath())
+}
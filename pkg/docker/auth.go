@@ -0,0 +1,163 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	dockerHubRegistry   = "docker.io"
+	dockerHubAuthServer = "https://index.docker.io/v1/"
+)
+
+// dockerConfig is the part of the Docker CLI config file with the registry credentials.
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// dockerConfigPath returns the Docker CLI config file path,
+// DOCKER_CONFIG variable takes precedence over the home directory.
+func dockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// registryAuth returns the encoded credentials of the image registry
+// from the Docker CLI config or an empty string if there are none.
+func registryAuth(image string) (string, error) {
+	data, err := os.ReadFile(dockerConfigPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("docker config: %w", err)
+	}
+	auth, err := config.lookup(registryHost(image))
+	if err != nil || auth == nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(auth)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(encoded), nil
+}
+
+func (c dockerConfig) lookup(registry string) (*types.AuthConfig, error) {
+	server := registry
+	if registry == dockerHubRegistry {
+		server = dockerHubAuthServer
+	}
+	if helper, set := c.CredHelpers[registry]; set {
+		return credentialHelper(helper, server)
+	}
+	for key, auth := range c.Auths {
+		if key != server && trimScheme(key) != registry {
+			continue
+		}
+		result := &types.AuthConfig{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			IdentityToken: auth.IdentityToken,
+			ServerAddress: key,
+		}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("%q auth decode: %w", key, err)
+			}
+			result.Username, result.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		if result.Username != "" || result.IdentityToken != "" {
+			return result, nil
+		}
+	}
+	if c.CredsStore != "" {
+		return credentialHelper(c.CredsStore, server)
+	}
+	return nil, nil
+}
+
+// credentialHelper runs the docker-credential-<helper> binary
+// to get the registry credentials.
+func credentialHelper(helper, server string) (*types.AuthConfig, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		// helpers exit with error if they have no credentials for the server
+		return nil, nil
+	}
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("credential helper %q output: %w", helper, err)
+	}
+	if creds.Username == "<token>" {
+		return &types.AuthConfig{IdentityToken: creds.Secret, ServerAddress: server}, nil
+	}
+	return &types.AuthConfig{Username: creds.Username, Password: creds.Secret, ServerAddress: server}, nil
+}
+
+// registryHost returns the registry domain of the image reference.
+func registryHost(image string) string {
+	domain, _, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(domain, ".:") && domain != "localhost") {
+		return dockerHubRegistry
+	}
+	if domain == "index.docker.io" {
+		return dockerHubRegistry
+	}
+	return domain
+}
+
+func trimScheme(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	return strings.TrimSuffix(server, "/")
+}
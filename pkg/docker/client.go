@@ -30,12 +30,14 @@ import (
 	"github.com/docker/docker/client"
 
 	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
 // time to wait for adapter init logs to show up.
 var initLogsWaitPeriod time.Duration = 2 * time.Second
 
 type imagePullEvent struct {
+	ID             string `json:"id"`
 	Status         string `json:"status"`
 	Error          string `json:"error"`
 	Progress       string `json:"progress"`
@@ -91,34 +93,6 @@ func (c *Container) Remove(ctx context.Context, client *client.Client) error {
 	})
 }
 
-func (c *Container) pullImage(ctx context.Context, client *client.Client) error {
-	reader, err := client.ImagePull(ctx, c.Image, types.ImagePullOptions{})
-	if err != nil {
-		return err
-	}
-	defer reader.Close()
-
-	d := json.NewDecoder(reader)
-	var e *imagePullEvent
-	var downloading bool
-	for {
-		if err := d.Decode(&e); err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-		if e.Status == "Downloading" {
-			downloading = true
-			fmt.Printf("\r%s", e.Progress)
-		}
-	}
-	if downloading {
-		fmt.Printf("\n")
-	}
-	return nil
-}
-
 func (c *Container) Start(ctx context.Context, client *client.Client, restart bool) (*Container, error) {
 	cc := container.Config{}
 	for _, opt := range c.CreateContainerOptions {
@@ -130,7 +104,8 @@ func (c *Container) Start(ctx context.Context, client *client.Client, restart bo
 		opt(&hc)
 	}
 
-	if err := c.pullImage(ctx, client); err != nil {
+	cc.Image = c.Image
+	if err := ensureImage(ctx, client, c.Image); err != nil {
 		return nil, fmt.Errorf("pulling image: %w", err)
 	}
 
@@ -152,6 +127,14 @@ func (c *Container) Start(ctx context.Context, client *client.Client, restart bo
 		return existingContainer, nil
 	}
 
+	for _, bindings := range hc.PortBindings {
+		for _, binding := range bindings {
+			if !pkg.PortAvailable(binding.HostPort) {
+				return nil, fmt.Errorf("host port %s is already in use", binding.HostPort)
+			}
+		}
+	}
+
 	resp, err := client.ContainerCreate(ctx, &cc, &hc, nil, nil, c.Name)
 	if err != nil {
 		return nil, fmt.Errorf("docker create: %w", err)
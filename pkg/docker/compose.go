@@ -22,4 +22,7 @@ type ComposeService struct {
 	Image         string   `json:"image"`
 	Ports         []string `json:"ports"`
 	Environment   []string `json:"environment"`
+	Volumes       []string `json:"volumes,omitempty"`
+	CPUs          string   `json:"cpus,omitempty"`
+	MemLimit      int64    `json:"mem_limit,omitempty"`
 }
@@ -17,7 +17,9 @@ limitations under the License.
 package docker
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
@@ -56,19 +58,40 @@ func WithEntrypoint(entrypoint []string) ContainerOption {
 	}
 }
 
+// WithUser runs the container as the "uid:gid" user.
+func WithUser(user string) ContainerOption {
+	return func(cc *container.Config) {
+		cc.User = user
+	}
+}
+
 func WithVolumeBind(bind string) HostOption {
 	return func(hc *container.HostConfig) {
-		hc.Binds = []string{bind}
+		hc.Binds = append(hc.Binds, bind)
 	}
 }
 
-func WithHostPortBinding(containerPort nat.Port) HostOption {
+// WithResources limits the container CPU, in units of 10^-9 CPUs,
+// and memory, in bytes. Zero value means no limit.
+func WithResources(nanoCPUs, memory int64) HostOption {
 	return func(hc *container.HostConfig) {
+		hc.NanoCPUs = nanoCPUs
+		hc.Memory = memory
+	}
+}
+
+// WithHostPortBinding binds the container port to the host port.
+// Random open port is used if the host port is empty.
+func WithHostPortBinding(containerPort nat.Port, hostPort string) HostOption {
+	return func(hc *container.HostConfig) {
+		if hostPort == "" {
+			hostPort = strconv.Itoa(pkg.OpenPort())
+		}
 		hc.PortBindings = nat.PortMap{
 			containerPort: []nat.PortBinding{
 				{
 					HostIP:   "0.0.0.0",
-					HostPort: strconv.Itoa(pkg.OpenPort()),
+					HostPort: hostPort,
 				},
 			},
 		}
@@ -81,6 +104,36 @@ func WithExtraHost() HostOption {
 	}
 }
 
+func WithRestartPolicy(policy container.RestartPolicy) HostOption {
+	return func(hc *container.HostConfig) {
+		hc.RestartPolicy = policy
+	}
+}
+
+// ParseRestartPolicy converts the restart policy annotation value,
+// one of "never", "always" or "on-failure[:max-retries]", into the Docker policy.
+func ParseRestartPolicy(value string) (container.RestartPolicy, error) {
+	name, retries, _ := strings.Cut(value, ":")
+	switch name {
+	case "", "never", "no":
+		return container.RestartPolicy{Name: "no"}, nil
+	case "always":
+		return container.RestartPolicy{Name: "always"}, nil
+	case "on-failure":
+		policy := container.RestartPolicy{Name: "on-failure"}
+		if retries == "" {
+			return policy, nil
+		}
+		maxRetries, err := strconv.Atoi(retries)
+		if err != nil || maxRetries < 0 {
+			return container.RestartPolicy{}, fmt.Errorf("invalid max retries value %q", retries)
+		}
+		policy.MaximumRetryCount = maxRetries
+		return policy, nil
+	}
+	return container.RestartPolicy{}, fmt.Errorf("unknown restart policy %q, must be one of never, always, on-failure[:max-retries]", value)
+}
+
 func WithErrorLoggingLevel() ContainerOption {
 	return func(cc *container.Config) {
 		cc.Env = append(cc.Env, errorLoggingLevel)
@@ -63,15 +63,29 @@ func TestWithVolumeBind(t *testing.T) {
 	hc := &container.HostConfig{}
 	WithVolumeBind(bind)(hc)
 	assert.Equal(t, []string{bind}, hc.Binds)
+	WithVolumeBind("baz:qux")(hc)
+	assert.Equal(t, []string{bind, "baz:qux"}, hc.Binds)
+}
+
+func TestWithResources(t *testing.T) {
+	hc := &container.HostConfig{}
+	WithResources(5e8, 512*1024*1024)(hc)
+	assert.Equal(t, int64(5e8), hc.NanoCPUs)
+	assert.Equal(t, int64(512*1024*1024), hc.Memory)
 }
 
 func TestWithHostPortBinding(t *testing.T) {
 	port, err := nat.NewPort("TCP", "8080")
 	assert.NoError(t, err)
 	hc := &container.HostConfig{}
-	WithHostPortBinding(port)(hc)
+	WithHostPortBinding(port, "")(hc)
 	assert.Len(t, hc.PortBindings[port], 1)
 	assert.Equal(t, "0.0.0.0", hc.PortBindings[port][0].HostIP)
+	assert.NotEmpty(t, hc.PortBindings[port][0].HostPort)
+
+	hc = &container.HostConfig{}
+	WithHostPortBinding(port, "18080")(hc)
+	assert.Equal(t, "18080", hc.PortBindings[port][0].HostPort)
 }
 
 func TestWithExtraHost(t *testing.T) {
@@ -81,6 +95,39 @@ func TestWithExtraHost(t *testing.T) {
 	assert.Equal(t, "host.docker.internal:host-gateway", hc.ExtraHosts[0])
 }
 
+func TestWithRestartPolicy(t *testing.T) {
+	policy := container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}
+	hc := &container.HostConfig{}
+	WithRestartPolicy(policy)(hc)
+	assert.Equal(t, policy, hc.RestartPolicy)
+}
+
+func TestParseRestartPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		expected container.RestartPolicy
+		wantErr  bool
+	}{
+		"":              {expected: container.RestartPolicy{Name: "no"}},
+		"never":         {expected: container.RestartPolicy{Name: "no"}},
+		"always":        {expected: container.RestartPolicy{Name: "always"}},
+		"on-failure":    {expected: container.RestartPolicy{Name: "on-failure"}},
+		"on-failure:5":  {expected: container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5}},
+		"on-failure:-1": {wantErr: true},
+		"sometimes":     {wantErr: true},
+	}
+	for value, tc := range testCases {
+		t.Run(value, func(t *testing.T) {
+			policy, err := ParseRestartPolicy(value)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
 func TestWithErrorLoggingLevel(t *testing.T) {
 	cc := &container.Config{}
 	WithErrorLoggingLevel()(cc)
@@ -0,0 +1,273 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+)
+
+// PullPolicy defines when the container images are pulled.
+type PullPolicy string
+
+const (
+	PullAlways  PullPolicy = "always"
+	PullMissing PullPolicy = "missing"
+	PullNever   PullPolicy = "never"
+)
+
+// imageConfig is the Docker section of the CLI config, including
+// the values overridden with the command line flags.
+var imageConfig = &config.Docker{}
+
+// SetConfig sets the config used to resolve and pull the images.
+func SetConfig(c *config.Docker) {
+	imageConfig = c
+}
+
+// ParsePullPolicy validates the pull policy value, empty value means "always".
+func ParsePullPolicy(value string) (PullPolicy, error) {
+	switch policy := PullPolicy(value); policy {
+	case "":
+		return PullAlways, nil
+	case PullAlways, PullMissing, PullNever:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown pull policy %q, expected one of: always, missing, never", value)
+}
+
+// MirrorImage replaces the TriggerMesh registry in the image reference
+// with the configured mirror. Other images are returned as is.
+func MirrorImage(image string) string {
+	mirror := strings.TrimSuffix(imageConfig.Mirror, "/")
+	if mirror == "" || !strings.HasPrefix(image, config.TriggerMeshRegistry+"/") {
+		return image
+	}
+	return mirror + strings.TrimPrefix(image, config.TriggerMeshRegistry)
+}
+
+// ensureImage makes the image available locally according to the pull policy.
+func ensureImage(ctx context.Context, client *client.Client, image string) error {
+	policy, err := ParsePullPolicy(imageConfig.Pull)
+	if err != nil {
+		return err
+	}
+	if policy != PullAlways {
+		_, _, err := client.ImageInspectWithRaw(ctx, image)
+		switch {
+		case err == nil:
+			return nil
+		case !isNotFound(err):
+			return fmt.Errorf("image %q inspect: %w", image, err)
+		case policy == PullNever:
+			return fmt.Errorf("image %q is not present locally and pull policy is %q", image, policy)
+		}
+	}
+	return pullImage(ctx, client, image)
+}
+
+func pullImage(ctx context.Context, client *client.Client, image string) error {
+	var downloading bool
+	err := pullImageEvents(ctx, client, image, func(e imagePullEvent) {
+		if e.Status == "Downloading" {
+			downloading = true
+			fmt.Printf("\r%s", e.Progress)
+		}
+	})
+	if downloading {
+		fmt.Printf("\n")
+	}
+	return err
+}
+
+// pullImageEvents pulls the image and passes the progress events to the callback.
+func pullImageEvents(ctx context.Context, client *client.Client, image string, callback func(imagePullEvent)) error {
+	auth, err := registryAuth(image)
+	if err != nil {
+		return fmt.Errorf("registry credentials: %w", err)
+	}
+	reader, err := client.ImagePull(ctx, image, types.ImagePullOptions{RegistryAuth: auth})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	d := json.NewDecoder(reader)
+	for {
+		var e imagePullEvent
+		if err := d.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if e.Error != "" {
+			return fmt.Errorf("%s", e.Error)
+		}
+		callback(e)
+	}
+}
+
+// PullImages pulls the images in parallel and prints their aggregated progress.
+func PullImages(ctx context.Context, client *client.Client, images []string) error {
+	progress := newPullProgress(len(images))
+	errs := make([]error, len(images))
+	var wg sync.WaitGroup
+	for i, image := range images {
+		wg.Add(1)
+		go func(i int, image string) {
+			defer wg.Done()
+			errs[i] = pullImageEvents(ctx, client, image, func(e imagePullEvent) {
+				progress.update(image, e)
+			})
+			progress.done()
+		}(i, image)
+	}
+	wg.Wait()
+	progress.finish()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", images[i], err))
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("pulling images:\n%s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// SaveImages writes the tar archive of the images.
+func SaveImages(ctx context.Context, client *client.Client, images []string, w io.Writer) error {
+	reader, err := client.ImageSave(ctx, images)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+// LoadImages loads the images from the tar archive created by SaveImages.
+func LoadImages(ctx context.Context, client *client.Client, r io.Reader) error {
+	resp, err := client.ImageLoad(ctx, r, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	d := json.NewDecoder(resp.Body)
+	for {
+		var e struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}
+		if err := d.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if e.Error != "" {
+			return fmt.Errorf("%s", e.Error)
+		}
+		if stream := strings.TrimSpace(e.Stream); stream != "" {
+			fmt.Println(stream)
+		}
+	}
+}
+
+func isNotFound(err error) bool {
+	return client.IsErrNotFound(err)
+}
+
+// pullProgress aggregates the download progress of the parallel pulls.
+type pullProgress struct {
+	mu        sync.Mutex
+	images    int
+	completed int
+	layers    map[string][2]int
+}
+
+func newPullProgress(images int) *pullProgress {
+	return &pullProgress{
+		images: images,
+		layers: make(map[string][2]int),
+	}
+}
+
+func (p *pullProgress) update(image string, e imagePullEvent) {
+	if e.ID == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := image + "@" + e.ID
+	switch e.Status {
+	case "Downloading":
+		p.layers[key] = [2]int{e.ProgressDetail.Current, e.ProgressDetail.Total}
+	case "Download complete", "Pull complete", "Already exists":
+		if layer, exists := p.layers[key]; exists {
+			p.layers[key] = [2]int{layer[1], layer[1]}
+		}
+	default:
+		return
+	}
+	p.print()
+}
+
+func (p *pullProgress) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed++
+	p.print()
+}
+
+func (p *pullProgress) finish() {
+	fmt.Printf("\n")
+}
+
+func (p *pullProgress) print() {
+	var current, total int
+	for _, layer := range p.layers {
+		current += layer[0]
+		total += layer[1]
+	}
+	fmt.Printf("\rPulling images: %d/%d done, %s/%s downloaded   ", p.completed, p.images, humanSize(current), humanSize(total))
+}
+
+func humanSize(bytes int) string {
+	const unit = 1000
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	size, exp := float64(bytes)/unit, 0
+	for ; size >= unit && exp < 3; exp++ {
+		size /= unit
+	}
+	return fmt.Sprintf("%.1f%cB", size, "kMGT"[exp])
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+)
+
+func TestParsePullPolicy(t *testing.T) {
+	policy, err := ParsePullPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, PullAlways, policy)
+
+	policy, err = ParsePullPolicy("missing")
+	assert.NoError(t, err)
+	assert.Equal(t, PullMissing, policy)
+
+	_, err = ParsePullPolicy("sometimes")
+	assert.Error(t, err)
+}
+
+func TestMirrorImage(t *testing.T) {
+	defer SetConfig(&config.Docker{})
+
+	SetConfig(&config.Docker{})
+	assert.Equal(t, "gcr.io/triggermesh/memory-broker:v1.4.0", MirrorImage("gcr.io/triggermesh/memory-broker:v1.4.0"))
+
+	SetConfig(&config.Docker{Mirror: "registry.example.com/mirror/"})
+	assert.Equal(t, "registry.example.com/mirror/memory-broker:v1.4.0", MirrorImage("gcr.io/triggermesh/memory-broker:v1.4.0"))
+	assert.Equal(t, "docker.io/library/nginx", MirrorImage("docker.io/library/nginx"))
+}
+
+func TestRegistryAuth(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+
+	auth, err := registryAuth("gcr.io/triggermesh/memory-broker")
+	assert.NoError(t, err)
+	assert.Empty(t, auth)
+
+	configFile := `{"auths": {
+		"registry.example.com": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("user:pass")) + `"},
+		"https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("hub:token")) + `"}
+	}}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(configFile), 0600))
+
+	testCases := map[string]string{
+		"registry.example.com/triggermesh/memory-broker:v1.4.0": "user",
+		"triggermesh/memory-broker":                             "hub",
+		"gcr.io/triggermesh/memory-broker":                      "",
+	}
+	for image, username := range testCases {
+		t.Run(image, func(t *testing.T) {
+			auth, err := registryAuth(image)
+			assert.NoError(t, err)
+			if username == "" {
+				assert.Empty(t, auth)
+				return
+			}
+			data, err := base64.URLEncoding.DecodeString(auth)
+			assert.NoError(t, err)
+			var authConfig types.AuthConfig
+			assert.NoError(t, json.Unmarshal(data, &authConfig))
+			assert.Equal(t, username, authConfig.Username)
+		})
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	assert.Equal(t, "512B", humanSize(512))
+	assert.Equal(t, "1.5kB", humanSize(1500))
+	assert.Equal(t, "250.0MB", humanSize(250*1000*1000))
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+
+	"github.com/triggermesh/tmctl/pkg/log"
+)
+
+const (
+	supervisorPollPeriod = time.Second
+	initialBackoff       = time.Second
+	maxBackoff           = time.Minute
+)
+
+// Supervisor watches the containers in the foreground and restarts
+// the crashed ones with backoff according to their restart policies.
+type Supervisor struct {
+	client     *client.Client
+	containers []*supervised
+}
+
+type supervised struct {
+	*Container
+
+	policy    container.RestartPolicy
+	retries   int
+	backoff   time.Duration
+	restartAt time.Time
+	startedAt time.Time
+	gaveUp    bool
+}
+
+func NewSupervisor(client *client.Client) *Supervisor {
+	return &Supervisor{
+		client: client,
+	}
+}
+
+// Add puts the container under supervision. Docker daemon restart policy
+// of the container is disabled until the supervisor exits.
+func (s *Supervisor) Add(ctx context.Context, c *Container) error {
+	if _, err := c.LookupHostConfig(ctx, s.client); err != nil {
+		return fmt.Errorf("container %q lookup: %w", c.Name, err)
+	}
+	policy := c.runtimeHostConfig.RestartPolicy
+	if !policy.IsNone() && policy.Name != "" {
+		if _, err := s.client.ContainerUpdate(ctx, c.ID, container.UpdateConfig{
+			RestartPolicy: container.RestartPolicy{Name: "no"},
+		}); err != nil {
+			return fmt.Errorf("container %q restart policy update: %w", c.Name, err)
+		}
+	}
+	s.containers = append(s.containers, &supervised{
+		Container: c,
+		policy:    policy,
+		backoff:   initialBackoff,
+		startedAt: time.Now(),
+	})
+	return nil
+}
+
+// Run blocks until the context is canceled, then restores the containers
+// restart policies so that Docker daemon continues to supervise them.
+func (s *Supervisor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(supervisorPollPeriod)
+	defer ticker.Stop()
+	defer s.restorePolicies()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			for _, c := range s.containers {
+				s.check(ctx, c)
+			}
+		}
+	}
+}
+
+func (s *Supervisor) check(ctx context.Context, c *supervised) {
+	if c.gaveUp {
+		return
+	}
+	info, err := s.client.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		// container is removed, e.g. by "tmctl stop"
+		return
+	}
+	if info.State.Running || info.State.Restarting {
+		if time.Since(c.startedAt) > maxBackoff {
+			c.backoff = initialBackoff
+		}
+		return
+	}
+	if c.restartAt.IsZero() {
+		log.Printf("%s crashed: %s", c.Name, s.crashReason(ctx, c, info.State))
+		if !c.shouldRestart(info.State.ExitCode) {
+			log.Printf("%s restart policy is %q, not restarting", c.Name, policyString(c.policy))
+			c.gaveUp = true
+			return
+		}
+		c.restartAt = time.Now().Add(c.backoff)
+		log.Printf("Restarting %s in %s", c.Name, c.backoff)
+		return
+	}
+	if time.Now().Before(c.restartAt) {
+		return
+	}
+	c.retries++
+	c.restartAt = time.Time{}
+	c.startedAt = time.Now()
+	if c.backoff *= 2; c.backoff > maxBackoff {
+		c.backoff = maxBackoff
+	}
+	if err := s.client.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+		log.Printf("Restarting %s: %v", c.Name, err)
+	}
+}
+
+func (s *Supervisor) crashReason(ctx context.Context, c *supervised, state *types.ContainerState) string {
+	reason := []string{fmt.Sprintf("exit code %d", state.ExitCode)}
+	if state.OOMKilled {
+		reason = append(reason, "out of memory")
+	}
+	if state.Error != "" {
+		reason = append(reason, state.Error)
+	}
+	logs, err := s.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       "1",
+	})
+	if err != nil {
+		return strings.Join(reason, ", ")
+	}
+	defer logs.Close()
+	if lines := readLogs(logs); len(lines) != 0 {
+		reason = append(reason, fmt.Sprintf("last log: %s", lines[len(lines)-1]))
+	}
+	return strings.Join(reason, ", ")
+}
+
+func (s *Supervisor) restorePolicies() {
+	for _, c := range s.containers {
+		if c.policy.IsNone() || c.policy.Name == "" {
+			continue
+		}
+		if _, err := s.client.ContainerUpdate(context.Background(), c.ID, container.UpdateConfig{
+			RestartPolicy: c.policy,
+		}); err != nil && !client.IsErrNotFound(err) {
+			log.Printf("Restoring %s restart policy: %v", c.Name, err)
+		}
+	}
+}
+
+func (c *supervised) shouldRestart(exitCode int) bool {
+	switch c.policy.Name {
+	case "always", "unless-stopped":
+		return true
+	case "on-failure":
+		return exitCode != 0 &&
+			(c.policy.MaximumRetryCount == 0 || c.retries < c.policy.MaximumRetryCount)
+	}
+	return false
+}
+
+func policyString(policy container.RestartPolicy) string {
+	switch policy.Name {
+	case "", "no":
+		return "never"
+	case "on-failure":
+		if policy.MaximumRetryCount != 0 {
+			return fmt.Sprintf("on-failure:%d", policy.MaximumRetryCount)
+		}
+	}
+	return policy.Name
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package file provides the atomic, locked and permission-safe
+// writes of the CLI configuration files.
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// PrivatePerm is the mode of the files that may contain secrets.
+	PrivatePerm os.FileMode = 0600
+	// DirPerm is the mode of the configuration directories.
+	DirPerm os.FileMode = 0700
+
+	lockSuffix = ".lock"
+)
+
+// Lock takes the exclusive lock of the file shared between the processes.
+// The lock is held on the separate "<path>.lock" file, so that the locked
+// file can be replaced. Returned function releases the lock.
+func Lock(path string) (func(), error) {
+	f, err := os.OpenFile(path+lockSuffix, os.O_RDWR|os.O_CREATE, PrivatePerm)
+	if err != nil {
+		return nil, fmt.Errorf("lock file: %w", err)
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock %q: %w", path, err)
+	}
+	return func() {
+		_ = unlock(f)
+		f.Close()
+	}, nil
+}
+
+// WriteAtomic writes the data to the temporary file and renames it
+// to the path, readers never see partially written file.
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Create creates the empty file if it does not exist.
+func Create(path string, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if os.IsExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Restrict removes the permission bits that are not in perm from
+// the existing file mode. It returns true if the mode was changed.
+// File modes are not enforced on Windows, it is no-op there.
+func Restrict(path string, perm os.FileMode) (bool, error) {
+	if runtime.GOOS == "windows" {
+		return false, nil
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	mode := stat.Mode().Perm()
+	if mode&^perm == 0 {
+		return false, nil
+	}
+	return true, os.Chmod(path, mode&perm)
+}
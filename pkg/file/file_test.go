@@ -0,0 +1,93 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0777))
+
+	assert.NoError(t, WriteAtomic(path, []byte("new"), PrivatePerm))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	stat, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, PrivatePerm, stat.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRestrict(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "broker.conf")
+	assert.NoError(t, os.WriteFile(path, nil, 0777))
+	assert.NoError(t, os.Chmod(path, 0777))
+
+	changed, err := Restrict(path, PrivatePerm)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	stat, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, PrivatePerm, stat.Mode().Perm())
+
+	changed, err = Restrict(path, PrivatePerm)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broker.conf")
+	unlock, err := Lock(path)
+	assert.NoError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := Lock(path)
+		assert.NoError(t, err)
+		close(locked)
+		unlock()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock is taken twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock is not released")
+	}
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wizard implements the terminal prompts that fill
+// the component spec following the CRD schema.
+package wizard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+	"sigs.k8s.io/yaml"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
+)
+
+const secretMask = "******"
+
+// Wizard prompts the spec values in the terminal.
+type Wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+	// readSecret reads the input without echo if the input is a terminal.
+	readSecret func() (string, error)
+}
+
+// New creates the wizard reading the answers from the input.
+func New(in io.Reader, out io.Writer) *Wizard {
+	w := &Wizard{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+	w.readSecret = w.readLine
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		w.readSecret = func() (string, error) {
+			value, err := term.ReadPassword(int(f.Fd()))
+			fmt.Fprintln(w.out)
+			return string(value), err
+		}
+	}
+	return w
+}
+
+// Spec prompts the spec fields that are not set in values yet.
+// Required fields are always prompted, optional ones on request.
+// The values are the "a.b.c" keys accepted by the create commands.
+func (w *Wizard) Spec(spec crd.Field, values map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+	if err := w.fields("", spec.Fields, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Choose prompts to pick one of the options by its number or value.
+func (w *Wizard) Choose(label string, options []string) (string, error) {
+	for i, option := range options {
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		fmt.Fprintf(w.out, "%s: ", label)
+		answer, err := w.readLine()
+		if err != nil {
+			return "", err
+		}
+		if i, err := strconv.Atoi(answer); err == nil && i > 0 && i <= len(options) {
+			return options[i-1], nil
+		}
+		for _, option := range options {
+			if option == answer {
+				return option, nil
+			}
+		}
+		fmt.Fprintf(w.out, "%q is not one of the options\n", answer)
+	}
+}
+
+// Confirm prompts the yes/no question.
+func (w *Wizard) Confirm(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(w.out, "%s [%s]: ", label, hint)
+		answer, err := w.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Input prompts the free-form value.
+func (w *Wizard) Input(label string) (string, error) {
+	fmt.Fprintf(w.out, "%s: ", label)
+	return w.readLine()
+}
+
+// Preview returns the YAML spec built from the values with masked secrets.
+func Preview(spec crd.Field, values map[string]string) (string, error) {
+	masked := make(map[string]string, len(values))
+	for k, v := range values {
+		if field, exists := lookup(spec, strings.Split(k, ".")); exists && field.Secret {
+			v = secretMask
+		}
+		masked[k] = v
+	}
+	out, err := yaml.Marshal(pkg.ParseArgs(masked))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (w *Wizard) fields(prefix string, fields []crd.Field, values map[string]string) error {
+	var optional []crd.Field
+	for _, field := range fields {
+		key := join(prefix, field.Name)
+		if field.Name == "adapterOverrides" || isSet(key, values) {
+			// adapter overrides are set with the runtime parameters
+			continue
+		}
+		if !field.Required {
+			optional = append(optional, field)
+			continue
+		}
+		if err := w.field(key, field, true, values); err != nil {
+			return err
+		}
+	}
+	if len(optional) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(optional))
+	for _, field := range optional {
+		names = append(names, field.Name)
+	}
+	scope := "spec"
+	if prefix != "" {
+		scope = prefix
+	}
+	configure, err := w.Confirm(fmt.Sprintf("Configure optional %s fields (%s)?", scope, strings.Join(names, ", ")), false)
+	if err != nil || !configure {
+		return err
+	}
+	for _, field := range optional {
+		if err := w.field(join(prefix, field.Name), field, false, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Wizard) field(key string, field crd.Field, required bool, values map[string]string) error {
+	if field.Type == "object" && len(field.Fields) != 0 {
+		if !required {
+			configure, err := w.Confirm(fmt.Sprintf("Configure %s?", key), false)
+			if err != nil || !configure {
+				return err
+			}
+		}
+		return w.fields(key, field.Fields, values)
+	}
+
+	if field.Description != "" {
+		fmt.Fprintf(w.out, "\n# %s\n", strings.ReplaceAll(field.Description, "\n", " "))
+	}
+	label := fmt.Sprintf("%s <%s>", key, field.Type)
+	switch {
+	case field.Default != "":
+		label += fmt.Sprintf(" [default: %s]", field.Default)
+	case !required:
+		label += " [optional]"
+	}
+	if hint := inputHint(field.Type); hint != "" {
+		label += " (" + hint + ")"
+	}
+
+	for {
+		var value string
+		var err error
+		switch {
+		case len(field.Enum) != 0:
+			options := field.Enum
+			if !required {
+				options = append([]string{"<skip>"}, options...)
+			}
+			if value, err = w.Choose(label, options); value == "<skip>" {
+				value = ""
+			}
+		case field.Secret:
+			fmt.Fprintf(w.out, "%s (hidden): ", label)
+			value, err = w.readSecret()
+		default:
+			value, err = w.Input(label)
+		}
+		if err != nil {
+			return err
+		}
+		value = strings.TrimSpace(value)
+		if value == "" && (!required || field.Default != "") {
+			return nil
+		}
+		if err := validate(field, value); err != nil {
+			fmt.Fprintf(w.out, "Invalid value: %v\n", err)
+			continue
+		}
+		values[key] = value
+		return nil
+	}
+}
+
+func (w *Wizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err == io.EOF {
+		return "", fmt.Errorf("input closed")
+	}
+	return strings.TrimSpace(line), err
+}
+
+func validate(field crd.Field, value string) error {
+	if value == "" {
+		return fmt.Errorf("value is required")
+	}
+	switch field.Type {
+	case "integer":
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+	case "boolean":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("expected true or false")
+		}
+	}
+	return nil
+}
+
+func inputHint(typ string) string {
+	switch {
+	case typ == "boolean":
+		return "true/false"
+	case strings.HasPrefix(typ, "[]object"):
+		return "YAML list"
+	case strings.HasPrefix(typ, "[]"):
+		return "comma separated"
+	case strings.HasPrefix(typ, "map["):
+		return "key:value,..."
+	case typ == "object":
+		return "YAML object"
+	}
+	return ""
+}
+
+// isSet returns true if the field or any of its nested fields is set.
+func isSet(key string, values map[string]string) bool {
+	for k := range values {
+		if k == key || strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func lookup(field crd.Field, path []string) (crd.Field, bool) {
+	for _, name := range path {
+		found := false
+		for _, nested := range field.Fields {
+			if nested.Name == name {
+				field, found = nested, true
+				break
+			}
+		}
+		if !found {
+			return crd.Field{}, false
+		}
+	}
+	return field, true
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
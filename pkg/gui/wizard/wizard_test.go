@@ -0,0 +1,75 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wizard
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+	"github.com/triggermesh/tmctl/test"
+)
+
+func TestSpec(t *testing.T) {
+	schema, err := crd.ServedSchema(test.CRD()["awss3source"])
+	assert.NoError(t, err)
+	spec, err := schema.Explain()
+	assert.NoError(t, err)
+
+	answers := strings.Join([]string{
+		"",                 // required arn, empty value is rejected
+		"arn:aws:s3:::foo", // arn
+		"y",                // configure optional spec fields
+		"y",                // configure auth
+		"y",                // configure optional auth fields
+		"y",                // configure auth.credentials
+		"y",                // configure optional credentials fields
+		"AKID",             // accessKeyID
+		"SECRET",           // secretAccessKey
+		"",                 // skip iamRole
+		"n",                // skip destination
+	}, "\n") + "\n"
+	w := New(strings.NewReader(answers), io.Discard)
+
+	values, err := w.Spec(spec, map[string]string{"eventTypes": "s3:ObjectCreated:*"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"arn":                              "arn:aws:s3:::foo",
+		"eventTypes":                       "s3:ObjectCreated:*",
+		"auth.credentials.accessKeyID":     "AKID",
+		"auth.credentials.secretAccessKey": "SECRET",
+	}, values)
+
+	preview, err := Preview(spec, values)
+	assert.NoError(t, err)
+	assert.Contains(t, preview, "arn:aws:s3:::foo")
+	assert.NotContains(t, preview, "SECRET")
+	assert.Contains(t, preview, secretMask)
+}
+
+func TestChoose(t *testing.T) {
+	w := New(strings.NewReader("3\nfoo\n2\n"), io.Discard)
+	value, err := w.Choose("method", []string{"GET", "POST"})
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", value)
+
+	_, err = w.Choose("method", []string{"GET", "POST"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package history keeps the snapshots of the broker manifest and
+// configuration taken after every change made by the CLI.
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/triggermesh/tmctl/pkg/file"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+const (
+	// Dir is the history directory in the broker configuration directory.
+	Dir = "history"
+
+	// MaxRevisions is the number of the revisions kept in the history.
+	MaxRevisions = 100
+
+	revisionFile = "revision.yaml"
+)
+
+// Files are the broker files stored in the revision.
+var Files = []string{triggermesh.ManifestFile, triggermesh.BrokerConfigFile}
+
+// Revision is the snapshot of the broker files.
+type Revision struct {
+	Number  int       `yaml:"-"`
+	Time    time.Time `yaml:"time"`
+	Command string    `yaml:"command,omitempty"`
+
+	dir string
+}
+
+// Path returns the path of the broker file stored in the revision.
+func (r Revision) Path(name string) string {
+	return filepath.Join(r.dir, name)
+}
+
+// Read returns the content of the broker file stored in the revision.
+func (r Revision) Read(name string) ([]byte, error) {
+	data, err := os.ReadFile(r.Path(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// session are the revisions created by the current process,
+// all changes made by one command are stored in one revision.
+var session = struct {
+	sync.Mutex
+	revisions map[string]int
+}{revisions: make(map[string]int)}
+
+// Record calls the write function changing the broker file and snapshots
+// the broker files in the revision of the current command. The state before
+// the first recorded change is stored in the initial revision.
+func Record(brokerHome string, write func() error) error {
+	if err := initial(brokerHome); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	if err := snapshot(brokerHome); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
+
+// List returns the broker revisions sorted by number.
+func List(brokerHome string) ([]Revision, error) {
+	entries, err := os.ReadDir(filepath.Join(brokerHome, Dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var revisions []Revision
+	for _, entry := range entries {
+		number, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		revision, err := read(brokerHome, number)
+		if err != nil {
+			// incomplete revision
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+	return revisions, nil
+}
+
+// Get returns the broker revision by number.
+func Get(brokerHome string, number int) (Revision, error) {
+	revision, err := read(brokerHome, number)
+	if os.IsNotExist(err) {
+		return Revision{}, fmt.Errorf("revision %d does not exist", number)
+	}
+	return revision, err
+}
+
+func read(brokerHome string, number int) (Revision, error) {
+	dir := filepath.Join(brokerHome, Dir, strconv.Itoa(number))
+	data, err := os.ReadFile(filepath.Join(dir, revisionFile))
+	if err != nil {
+		return Revision{}, err
+	}
+	var revision Revision
+	if err := yaml.Unmarshal(data, &revision); err != nil {
+		return Revision{}, fmt.Errorf("revision %d: %w", number, err)
+	}
+	revision.Number = number
+	revision.dir = dir
+	return revision, nil
+}
+
+// initial stores the current broker files if the history is empty.
+func initial(brokerHome string) error {
+	session.Lock()
+	_, recorded := session.revisions[brokerHome]
+	session.Unlock()
+	if recorded {
+		return nil
+	}
+	manifest, err := os.Stat(filepath.Join(brokerHome, triggermesh.ManifestFile))
+	if err != nil || manifest.Size() == 0 {
+		// new broker, nothing to keep
+		return nil
+	}
+	unlock, err := lock(brokerHome)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	revisions, err := List(brokerHome)
+	if err != nil || len(revisions) != 0 {
+		return err
+	}
+	return store(brokerHome, Revision{Number: 1, Time: time.Now()})
+}
+
+// snapshot stores the broker files in the revision of the current process.
+func snapshot(brokerHome string) error {
+	unlock, err := lock(brokerHome)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	session.Lock()
+	defer session.Unlock()
+	if number, recorded := session.revisions[brokerHome]; recorded {
+		if revision, err := read(brokerHome, number); err == nil {
+			return store(brokerHome, revision)
+		}
+	}
+	revisions, err := List(brokerHome)
+	if err != nil {
+		return err
+	}
+	revision := Revision{Number: 1, Time: time.Now(), Command: command()}
+	if len(revisions) != 0 {
+		revision.Number = revisions[len(revisions)-1].Number + 1
+	}
+	if err := store(brokerHome, revision); err != nil {
+		return err
+	}
+	session.revisions[brokerHome] = revision.Number
+	return prune(brokerHome, append(revisions, revision))
+}
+
+// store copies the broker files into the revision directory,
+// the revision file is written last to mark it complete.
+func store(brokerHome string, revision Revision) error {
+	dir := filepath.Join(brokerHome, Dir, strconv.Itoa(revision.Number))
+	if err := os.MkdirAll(dir, file.DirPerm); err != nil {
+		return err
+	}
+	for _, name := range Files {
+		data, err := os.ReadFile(filepath.Join(brokerHome, name))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), file.DirPerm); err != nil {
+			return err
+		}
+		if err := file.WriteAtomic(filepath.Join(dir, name), data, file.PrivatePerm); err != nil {
+			return err
+		}
+	}
+	data, err := yaml.Marshal(revision)
+	if err != nil {
+		return err
+	}
+	return file.WriteAtomic(filepath.Join(dir, revisionFile), data, file.PrivatePerm)
+}
+
+// prune removes the oldest revisions above the limit.
+func prune(brokerHome string, revisions []Revision) error {
+	for len(revisions) > MaxRevisions {
+		if err := os.RemoveAll(revisions[0].dir); err != nil {
+			return err
+		}
+		revisions = revisions[1:]
+	}
+	return nil
+}
+
+func lock(brokerHome string) (func(), error) {
+	if err := os.MkdirAll(filepath.Join(brokerHome, Dir), file.DirPerm); err != nil {
+		return nil, err
+	}
+	return file.Lock(filepath.Join(brokerHome, Dir, revisionFile))
+}
+
+// redacted replaces the command arguments that may contain secrets.
+const redacted = "***"
+
+// plainFlags are the flags whose values are kept in the history, values
+// of the other flags, e.g. the component spec attributes, may be secret.
+var plainFlags = map[string]bool{
+	"broker":         true,
+	"credentials":    true,
+	"dry-run":        true,
+	"eventTypes":     true,
+	"external":       true,
+	"from":           true,
+	"help":           true,
+	"interactive":    true,
+	"kind":           true,
+	"mount":          true,
+	"name":           true,
+	"overlay":        true,
+	"port":           true,
+	"provider":       true,
+	"resources":      true,
+	"restart":        true,
+	"restart-policy": true,
+	"selector":       true,
+	"source":         true,
+	"supervise":      true,
+	"target":         true,
+	"version":        true,
+	"wizard":         true,
+}
+
+// command returns the command line of the current process
+// with the secret values redacted.
+func command() string {
+	args := append([]string{filepath.Base(os.Args[0])}, redact(os.Args[1:])...)
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			args[i] = strconv.Quote(arg)
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// redact replaces the values of the flags that are not known to be plain
+// and the values of the "key=value" arguments.
+func redact(args []string) []string {
+	result := make([]string, len(args))
+	redactValue := false
+	for i, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "-"):
+			name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			redactValue = !plainFlags[name]
+			if hasValue && redactValue {
+				arg = arg[:strings.Index(arg, "=")+1] + redacted
+			}
+		case redactValue:
+			arg = redacted
+		case strings.Contains(arg, "="):
+			arg = arg[:strings.Index(arg, "=")+1] + redacted
+		}
+		result[i] = arg
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+func TestRecord(t *testing.T) {
+	brokerHome := t.TempDir()
+	manifest := filepath.Join(brokerHome, triggermesh.ManifestFile)
+	config := filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
+	assert.NoError(t, os.WriteFile(manifest, []byte("v1"), 0600))
+	assert.NoError(t, os.Mkdir(filepath.Join(brokerHome, triggermesh.BrokerConfigDir), 0700))
+	write := func(path, data string) func() error {
+		return func() error {
+			return os.WriteFile(path, []byte(data), 0600)
+		}
+	}
+
+	// one command changes both files
+	assert.NoError(t, Record(brokerHome, write(manifest, "v2")))
+	assert.NoError(t, Record(brokerHome, write(config, "triggers: {}")))
+
+	revisions, err := List(brokerHome)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Empty(t, revisions[0].Command, "Initial state is not stored")
+	data, err := revisions[0].Read(triggermesh.ManifestFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+	assert.NotEmpty(t, revisions[1].Command)
+	data, err = revisions[1].Read(triggermesh.ManifestFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+	data, err = revisions[1].Read(triggermesh.BrokerConfigFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "triggers: {}", string(data))
+
+	// next command
+	delete(session.revisions, brokerHome)
+	assert.Error(t, Record(brokerHome, func() error { return os.ErrPermission }))
+	assert.NoError(t, Record(brokerHome, write(manifest, "v3")))
+	revision, err := Get(brokerHome, 3)
+	assert.NoError(t, err)
+	data, err = revision.Read(triggermesh.ManifestFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", string(data))
+
+	_, err = Get(brokerHome, 4)
+	assert.Error(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, []string{
+		"create", "source", "awss3", "--name", "foo",
+		"--auth.credentials.secretAccessKey=***", "--env", "***", "***",
+	}, redact([]string{
+		"create", "source", "awss3", "--name", "foo",
+		"--auth.credentials.secretAccessKey=bar", "--env", "A=1", "B=2",
+	}))
+	assert.Equal(t, []string{"secret", "set", "foo-secret", "token=***"},
+		redact([]string{"secret", "set", "foo-secret", "token=bar"}))
+	assert.Equal(t, []string{"start", "--restart", "foo"},
+		redact([]string{"start", "--restart", "foo"}))
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package journal keeps the record of the components with the external
+// resources, such as the queues and the subscriptions, created by the CLI.
+// Every record is a manifest file with the component and its secrets stored
+// in the home CLI directory by workspace and broker, so that the resources
+// could be finalized even if the broker manifest or the whole workspace
+// is lost.
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/file"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+const (
+	// Dir is the journal directory in the home CLI configuration directory.
+	Dir = "journal"
+
+	// workspaceFile keeps the path of the configuration
+	// directory the journal records belong to.
+	workspaceFile = "workspace"
+	// homeWorkspace is the journal directory of the home
+	// configuration directory brokers.
+	homeWorkspace = "home"
+)
+
+// Entry is the journal record of the component.
+type Entry struct {
+	// Workspace is the configuration directory of the broker.
+	Workspace string
+	Broker    string
+	Time      time.Time
+	// Manifest has the component object and the secrets it uses.
+	Manifest *manifest.Manifest
+}
+
+// Component returns the component object of the entry.
+func (e Entry) Component() kubernetes.Object {
+	for _, object := range e.Manifest.Objects {
+		if object.Kind != "Secret" {
+			return object
+		}
+	}
+	return kubernetes.Object{}
+}
+
+// Record stores the component and the secrets it uses in the journal,
+// the values resolved from the environment are stored as references.
+func Record(configHome string, component triggermesh.Component, m *manifest.Manifest) error {
+	object, err := component.AsK8sObject()
+	if err != nil {
+		return fmt.Errorf("creating k8s object: %w", err)
+	}
+	object.Metadata.Namespace = ""
+	broker := object.Metadata.Labels[triggermesh.ContextLabel]
+	if err := os.MkdirAll(filepath.Join(workspaceDir(configHome), broker), file.DirPerm); err != nil {
+		return err
+	}
+	if err := file.WriteAtomic(filepath.Join(workspaceDir(configHome), workspaceFile), []byte(configHome), file.PrivatePerm); err != nil {
+		return err
+	}
+	entry := manifest.New(entryPath(configHome, broker, object.Kind, object.Metadata.Name))
+	entry.Objects = append(entry.Objects, m.Original(object))
+	// component may be not in the manifest yet, secrets are matched by its spec
+	spec, err := json.Marshal(object.Spec)
+	if err != nil {
+		return err
+	}
+	var plain map[string]interface{}
+	if err := json.Unmarshal(spec, &plain); err != nil {
+		return err
+	}
+	refs := manifest.SecretRefs(plain)
+	for _, secret := range m.Objects {
+		if _, used := refs[secret.Metadata.Name]; used && secret.Kind == "Secret" {
+			entry.Objects = append(entry.Objects, m.Original(secret))
+		}
+	}
+	return entry.WriteFile()
+}
+
+// Forget removes the component record from the journal.
+func Forget(configHome, broker, kind, name string) error {
+	path := entryPath(configHome, broker, kind, name)
+	for _, p := range []string{path, path + ".lock"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// broker and workspace directories are removed if they have no records left
+	_ = os.Remove(filepath.Dir(path))
+	if entries, err := os.ReadDir(workspaceDir(configHome)); err == nil &&
+		len(entries) == 1 && entries[0].Name() == workspaceFile {
+		_ = os.RemoveAll(workspaceDir(configHome))
+	}
+	return nil
+}
+
+// List returns the journal entries of all workspaces
+// sorted by workspace, broker and time.
+func List() ([]Entry, error) {
+	paths, err := Files()
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		workspace, err := os.ReadFile(filepath.Join(filepath.Dir(filepath.Dir(path)), workspaceFile))
+		if err != nil {
+			return nil, fmt.Errorf("journal entry %q workspace: %w", path, err)
+		}
+		m := manifest.New(path)
+		if err := m.Read(); err != nil {
+			return nil, fmt.Errorf("journal entry %q: %w", path, err)
+		}
+		entries = append(entries, Entry{
+			Workspace: string(workspace),
+			Broker:    filepath.Base(filepath.Dir(path)),
+			Time:      info.ModTime(),
+			Manifest:  m,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Workspace != entries[j].Workspace {
+			return entries[i].Workspace < entries[j].Workspace
+		}
+		if entries[i].Broker != entries[j].Broker {
+			return entries[i].Broker < entries[j].Broker
+		}
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// Files returns the paths of the journal entries of all workspaces.
+func Files() ([]string, error) {
+	return filepath.Glob(filepath.Join(config.HomeAbsPath(), Dir, "*", "*", "*.yaml"))
+}
+
+// workspaceDir returns the journal directory of the configuration directory.
+// Workspaces are keyed by the path hash, the directory name is prefixed
+// with the project name to be recognizable.
+func workspaceDir(configHome string) string {
+	home := config.HomeAbsPath()
+	key := homeWorkspace
+	if configHome != home {
+		sum := sha256.Sum256([]byte(configHome))
+		key = filepath.Base(filepath.Dir(configHome)) + "-" + hex.EncodeToString(sum[:])[:12]
+	}
+	return filepath.Join(home, Dir, key)
+}
+
+func entryPath(configHome, broker, kind, name string) string {
+	return filepath.Join(workspaceDir(configHome), broker, strings.ToLower(kind)+"-"+name+".yaml")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components/source"
+	"github.com/triggermesh/tmctl/test"
+)
+
+func TestRecord(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configHome := filepath.Join(t.TempDir(), "project", ".tmctl")
+	m := manifest.New(filepath.Join(configHome, "foo", "manifest.yaml"))
+	m.Objects = []kubernetes.Object{{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   kubernetes.Metadata{Name: "foo-awss3source-secret"},
+		Data:       map[string]string{"accessKeyID": "QVdTQUNDRVNTS0VZSUQ="},
+	}, {
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   kubernetes.Metadata{Name: "bar-secret"},
+		Data:       map[string]string{"token": "dG9rZW4="},
+	}}
+	s := source.New("foo-awss3source", "awss3source", "foo", "v1.27.0", test.CRD()["awss3source"], map[string]string{
+		"arn":        "arn:aws:s3:::dev",
+		"eventTypes": "s3:ObjectCreated:*",
+		"sink.uri":   "http://localhost",
+		"auth.credentials.accessKeyID.valueFromSecret.key":  "accessKeyID",
+		"auth.credentials.accessKeyID.valueFromSecret.name": "foo-awss3source-secret",
+	}, map[string]interface{}{"queueARN": "arn:aws:sqs:us-east-1:123456789012:dev"})
+
+	assert.NoError(t, Record(configHome, s, m))
+	files, err := Files()
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasPrefix(files[0], filepath.Join(config.HomeAbsPath(), Dir, "project-")),
+		"Journal is stored in the home directory")
+	assert.NoDirExists(t, filepath.Join(configHome, Dir))
+
+	entries, err := List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, configHome, entries[0].Workspace)
+	assert.Equal(t, "foo", entries[0].Broker)
+	assert.Equal(t, "AWSS3Source", entries[0].Component().Kind)
+	assert.Equal(t, "queueARN=arn:aws:sqs:us-east-1:123456789012:dev",
+		entries[0].Component().Metadata.Annotations["triggermesh.io/external-resources"])
+	assert.Len(t, entries[0].Manifest.Objects, 2, "Unrelated secrets are not recorded")
+	assert.Equal(t, "QVdTQUNDRVNTS0VZSUQ=", entries[0].Manifest.Objects[1].Data["accessKeyID"])
+
+	assert.NoError(t, Forget(configHome, "foo", "AWSS3Source", "foo-awss3source"))
+	entries, err = List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoDirExists(t, workspaceDir(configHome), "Empty workspace journal is removed")
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/log"
+	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+const contextLabel = "triggermesh.io/context"
+
+var (
+	// BrokerResources are the cluster brokers that can be imported.
+	BrokerResources = []schema.GroupVersionResource{
+		{Group: "eventing.triggermesh.io", Version: "v1alpha1", Resource: "redisbrokers"},
+		{Group: "eventing.triggermesh.io", Version: "v1alpha1", Resource: "memorybrokers"},
+		{Group: "eventing.knative.dev", Version: "v1", Resource: "brokers"},
+	}
+	// TriggerResources are the cluster triggers that can be imported.
+	TriggerResources = []schema.GroupVersionResource{
+		{Group: "eventing.triggermesh.io", Version: "v1alpha1", Resource: "triggers"},
+		{Group: "eventing.knative.dev", Version: "v1", Resource: "triggers"},
+	}
+	secretResource = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+)
+
+// ComponentResources returns the cluster resources of the TriggerMesh
+// sources, targets and transformations described by the CRDs.
+func ComponentResources(crds map[string]crd.CRD) []schema.GroupVersionResource {
+	var result []schema.GroupVersionResource
+	for _, c := range crds {
+		switch c.Spec.Group {
+		case "sources.triggermesh.io", "targets.triggermesh.io", "flow.triggermesh.io":
+		default:
+			continue
+		}
+		for _, v := range c.Spec.Versions {
+			if v.Served {
+				result = append(result, schema.GroupVersionResource{Group: c.Spec.Group, Version: v.Name, Resource: c.Spec.Names.Plural})
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+// FromCluster reads the broker, its triggers and the TriggerMesh components
+// from the cluster namespace and converts them into the local manifest
+// objects. Secrets referenced by the components are stored as the local
+// component secrets. If the broker name is empty, the only broker in
+// the namespace is imported or, if there are no brokers, the new local
+// broker is named after the namespace.
+func FromCluster(ctx context.Context, client dynamic.Interface, crds map[string]crd.CRD, namespace, broker string) ([]kubernetes.Object, error) {
+	broker, err := selectBroker(ctx, client, namespace, broker)
+	if err != nil {
+		return nil, err
+	}
+	objects := []kubernetes.Object{{
+		APIVersion: tmbroker.APIVersion,
+		Kind:       tmbroker.BrokerKind,
+		Metadata: kubernetes.Metadata{
+			Name:   broker,
+			Labels: map[string]string{contextLabel: broker},
+		},
+	}}
+
+	imported := make(map[string]struct{})
+	for _, gvr := range ComponentResources(crds) {
+		items, err := list(ctx, client, gvr, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			object, ok := localComponent(item, broker)
+			if !ok {
+				continue
+			}
+			secrets, err := localSecrets(ctx, client, namespace, broker, &object)
+			if err != nil {
+				return nil, fmt.Errorf("%s %q secrets: %w", object.Kind, object.Metadata.Name, err)
+			}
+			objects = append(objects, secrets...)
+			objects = append(objects, object)
+			imported[object.Metadata.Name] = struct{}{}
+		}
+	}
+
+	for _, gvr := range TriggerResources {
+		items, err := list(ctx, client, gvr, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if trigger, ok := localTrigger(item, broker, imported); ok {
+				objects = append(objects, trigger)
+			}
+		}
+	}
+	return objects, nil
+}
+
+func selectBroker(ctx context.Context, client dynamic.Interface, namespace, broker string) (string, error) {
+	var brokers []string
+	for _, gvr := range BrokerResources {
+		items, err := list(ctx, client, gvr, namespace)
+		if err != nil {
+			return "", err
+		}
+		for _, item := range items {
+			brokers = append(brokers, item.GetName())
+		}
+	}
+	switch {
+	case broker != "":
+		for _, b := range brokers {
+			if b == broker {
+				return broker, nil
+			}
+		}
+		return "", fmt.Errorf("broker %q not found in namespace %q", broker, namespace)
+	case len(brokers) == 0:
+		log.Printf("No brokers found in namespace %q, creating broker %q", namespace, namespace)
+		return namespace, nil
+	case len(brokers) > 1:
+		sort.Strings(brokers)
+		return "", fmt.Errorf("namespace %q has multiple brokers, select one with --broker: %s", namespace, strings.Join(brokers, ", "))
+	}
+	return brokers[0], nil
+}
+
+// list returns the namespace objects, missing resources are ignored.
+func list(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) ([]unstructured.Unstructured, error) {
+	l, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", gvr.Resource, err)
+	}
+	return l.Items, nil
+}
+
+// localComponent converts the cluster component. Sources sending events
+// to the other brokers are skipped, the rest of them are connected
+// to the local broker.
+func localComponent(item unstructured.Unstructured, broker string) (kubernetes.Object, bool) {
+	spec, _, _ := unstructured.NestedMap(item.Object, "spec")
+	object := kubernetes.Object{
+		APIVersion: item.GetAPIVersion(),
+		Kind:       item.GetKind(),
+		Metadata: kubernetes.Metadata{
+			Name:   item.GetName(),
+			Labels: map[string]string{contextLabel: broker},
+		},
+		Spec: spec,
+	}
+	if _, hasSink := spec["sink"]; !hasSink {
+		return object, true
+	}
+	if strings.HasPrefix(object.APIVersion, "sources.triggermesh.io/") {
+		ref, _, _ := unstructured.NestedStringMap(spec, "sink", "ref")
+		if strings.HasSuffix(ref["kind"], "Broker") && ref["name"] != broker {
+			return kubernetes.Object{}, false
+		}
+		if ref["name"] != broker {
+			log.Printf("%s %q sink is replaced with the broker", object.Kind, object.Metadata.Name)
+		}
+		spec["sink"] = map[string]interface{}{
+			"ref": map[string]interface{}{
+				"apiVersion": tmbroker.APIVersion,
+				"kind":       tmbroker.BrokerKind,
+				"name":       broker,
+			},
+		}
+		return object, true
+	}
+	// transformations and targets reply to the broker
+	log.Printf("%s %q sink is removed, replies are sent to the broker", object.Kind, object.Metadata.Name)
+	delete(spec, "sink")
+	return object, true
+}
+
+// localSecrets reads the secrets referenced in the object spec and
+// replaces the references with the local component secret keys.
+func localSecrets(ctx context.Context, client dynamic.Interface, namespace, broker string, object *kubernetes.Object) ([]kubernetes.Object, error) {
+	secretName := strings.ToLower(object.Metadata.Name) + "-secret"
+	data := make(map[string]string)
+	cache := make(map[string]map[string]interface{})
+	var rewrite func(spec map[string]interface{}) error
+	rewrite = func(spec map[string]interface{}) error {
+		for field, value := range spec {
+			nested, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, refKey := range []string{"valueFromSecret", "secretKeyRef"} {
+				ref, ok := nested[refKey].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _ := ref["name"].(string)
+				key, _ := ref["key"].(string)
+				secretData, cached := cache[name]
+				if !cached {
+					secret, err := client.Resource(secretResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+					if err != nil {
+						return fmt.Errorf("secret %q: %w", name, err)
+					}
+					secretData, _, _ = unstructured.NestedMap(secret.Object, "data")
+					cache[name] = secretData
+				}
+				value, exists := secretData[key].(string)
+				if !exists {
+					return fmt.Errorf("secret %q does not have key %q", name, key)
+				}
+				data[field] = value
+				nested[refKey] = map[string]interface{}{
+					"name": secretName,
+					"key":  field,
+				}
+			}
+			if err := rewrite(nested); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := rewrite(object.Spec); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return []kubernetes.Object{{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: kubernetes.Metadata{
+			Name:   secretName,
+			Labels: map[string]string{contextLabel: broker},
+		},
+		Type: "Opaque",
+		Data: data,
+	}}, nil
+}
+
+// localTrigger converts the TriggerMesh or Knative trigger of the broker.
+// Triggers with the subscribers that are not imported are skipped.
+func localTrigger(item unstructured.Unstructured, broker string, imported map[string]struct{}) (kubernetes.Object, bool) {
+	spec, _, _ := unstructured.NestedMap(item.Object, "spec")
+	var target map[string]interface{}
+	var filters []interface{}
+	if strings.HasPrefix(item.GetAPIVersion(), "eventing.knative.dev/") {
+		if name, _ := spec["broker"].(string); name != broker {
+			return kubernetes.Object{}, false
+		}
+		target, _, _ = unstructured.NestedMap(spec, "subscriber")
+		if attributes, set, _ := unstructured.NestedMap(spec, "filter", "attributes"); set {
+			filters = []interface{}{map[string]interface{}{"exact": attributes}}
+		}
+	} else {
+		if name, _, _ := unstructured.NestedString(spec, "broker", "name"); name != broker {
+			return kubernetes.Object{}, false
+		}
+		target, _, _ = unstructured.NestedMap(spec, "target")
+	}
+	if f, set, _ := unstructured.NestedSlice(spec, "filters"); set {
+		filters = f
+	}
+	ref, _, _ := unstructured.NestedStringMap(target, "ref")
+	if _, exists := imported[ref["name"]]; !exists {
+		log.Printf("Trigger %q is skipped, its subscriber is not imported", item.GetName())
+		return kubernetes.Object{}, false
+	}
+	localSpec := map[string]interface{}{
+		"broker": map[string]interface{}{
+			"group": "eventing.triggermesh.io",
+			"kind":  tmbroker.BrokerKind,
+			"name":  broker,
+		},
+		"target": map[string]interface{}{
+			"ref": map[string]interface{}{
+				"apiVersion": ref["apiVersion"],
+				"kind":       ref["kind"],
+				"name":       ref["name"],
+			},
+		},
+	}
+	if len(filters) != 0 {
+		localSpec["filters"] = filters
+	}
+	return kubernetes.Object{
+		APIVersion: tmbroker.APIVersion,
+		Kind:       tmbroker.TriggerKind,
+		Metadata: kubernetes.Metadata{
+			Name:   item.GetName(),
+			Labels: map[string]string{contextLabel: broker},
+		},
+		Spec: localSpec,
+	}, true
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/test"
+)
+
+func TestFromCluster(t *testing.T) {
+	crds := test.CRD()
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range append(append(ComponentResources(crds), BrokerResources...), TriggerResources...) {
+		listKinds[gvr] = strings.TrimSuffix(gvr.Resource, "s") + "List"
+	}
+
+	objects := []runtime.Object{
+		object("eventing.knative.dev/v1", "Broker", "default", nil),
+		object("v1", "Secret", "aws", map[string]interface{}{
+			"data": map[string]interface{}{"id": "QUtJRA==", "secret": "U0VDUkVU"},
+		}),
+		object("sources.triggermesh.io/v1alpha1", "AWSS3Source", "bucket", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"arn": "arn:aws:s3:::dev",
+				"auth": map[string]interface{}{
+					"credentials": map[string]interface{}{
+						"accessKeyID":     map[string]interface{}{"valueFromSecret": map[string]interface{}{"name": "aws", "key": "id"}},
+						"secretAccessKey": map[string]interface{}{"valueFromSecret": map[string]interface{}{"name": "aws", "key": "secret"}},
+					},
+				},
+				"sink": map[string]interface{}{"ref": map[string]interface{}{"apiVersion": "eventing.knative.dev/v1", "kind": "Broker", "name": "default"}},
+			},
+		}),
+		object("sources.triggermesh.io/v1alpha1", "AWSS3Source", "other-broker-source", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"arn":  "arn:aws:s3:::other",
+				"sink": map[string]interface{}{"ref": map[string]interface{}{"apiVersion": "eventing.knative.dev/v1", "kind": "Broker", "name": "other"}},
+			},
+		}),
+		object("targets.triggermesh.io/v1alpha1", "HTTPTarget", "webhook", map[string]interface{}{
+			"spec": map[string]interface{}{"endpoint": "https://example.com"},
+		}),
+		object("eventing.knative.dev/v1", "Trigger", "to-webhook", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"broker":     "default",
+				"filter":     map[string]interface{}{"attributes": map[string]interface{}{"type": "com.amazon.s3.objectcreated"}},
+				"subscriber": map[string]interface{}{"ref": map[string]interface{}{"apiVersion": "targets.triggermesh.io/v1alpha1", "kind": "HTTPTarget", "name": "webhook"}},
+			},
+		}),
+		object("eventing.knative.dev/v1", "Trigger", "to-service", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"broker":     "default",
+				"subscriber": map[string]interface{}{"ref": map[string]interface{}{"apiVersion": "v1", "kind": "Service", "name": "display"}},
+			},
+		}),
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	result, err := FromCluster(context.Background(), client, crds, "ns", "")
+	assert.NoError(t, err)
+
+	byName := make(map[string]kubernetes.Object, len(result))
+	for _, o := range result {
+		byName[o.Metadata.Name] = o
+	}
+	assert.Len(t, byName, 5)
+	assert.Equal(t, "RedisBroker", byName["default"].Kind)
+	assert.NotContains(t, byName, "other-broker-source")
+	assert.NotContains(t, byName, "to-service")
+
+	assert.Equal(t, map[string]string{"accessKeyID": "QUtJRA==", "secretAccessKey": "U0VDUkVU"}, byName["bucket-secret"].Data)
+	source := byName["bucket"]
+	assert.Equal(t, "bucket-secret", source.Spec["auth"].(map[string]interface{})["credentials"].(map[string]interface{})["accessKeyID"].(map[string]interface{})["valueFromSecret"].(map[string]interface{})["name"])
+	assert.Equal(t, "RedisBroker", source.Spec["sink"].(map[string]interface{})["ref"].(map[string]interface{})["kind"])
+
+	trigger := byName["to-webhook"]
+	assert.Equal(t, "default", trigger.Spec["broker"].(map[string]interface{})["name"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"exact": map[string]interface{}{"type": "com.amazon.s3.objectcreated"}}}, trigger.Spec["filters"])
+
+	_, err = FromCluster(context.Background(), client, crds, "ns", "missing")
+	assert.Error(t, err)
+}
+
+func object(apiVersion, kind, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: fields}
+	if u.Object == nil {
+		u.Object = map[string]interface{}{}
+	}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName(name)
+	u.SetNamespace("ns")
+	return u
+}
@@ -18,6 +18,7 @@ package load
 
 import (
 	"bufio"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -25,10 +26,16 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/dynamic"
 
 	"github.com/triggermesh/tmctl/cmd/describe"
 	cliconfig "github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/secrets"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
 	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
@@ -36,11 +43,79 @@ import (
 )
 
 // Import creates the integration from provided YAML manifest.
-func Import(from string, config *cliconfig.Config, crd map[string]crd.CRD) error {
+// The overlay manifest is merged over the imported one and the environment
+// variable references are resolved, placeholders are filled with the values
+// or prompted from the standard input.
+func Import(ctx context.Context, from, overlay string, config *cliconfig.Config, crd map[string]crd.CRD, values *Values) error {
 	m, err := getManifest(from)
 	if err != nil {
 		return fmt.Errorf("manifest %q: %w", from, err)
 	}
+	if err := m.Resolve(manifest.OverlayPath(from, overlay)); err != nil {
+		return fmt.Errorf("manifest %q: %w", from, err)
+	}
+	return importManifest(ctx, m, config, crd, values)
+}
+
+// ImportCluster creates the integration from the broker and the components
+// in the cluster namespace.
+func ImportCluster(ctx context.Context, client dynamic.Interface, namespace, broker string, config *cliconfig.Config, crd map[string]crd.CRD) error {
+	objects, err := FromCluster(ctx, client, crd, namespace, broker)
+	if err != nil {
+		return fmt.Errorf("namespace %q: %w", namespace, err)
+	}
+	m := manifest.New("")
+	m.Objects = objects
+	return importManifest(ctx, m, config, crd, nil)
+}
+
+// ImportPlatform creates the integration from the docker-compose file or
+// the DigitalOcean App Platform spec exported with the dump command.
+// Settings that could not be mapped to the components are reported.
+func ImportPlatform(ctx context.Context, from, format string, config *cliconfig.Config, crd map[string]crd.CRD, values *Values) error {
+	path, err := localPath(from)
+	if err != nil {
+		return fmt.Errorf("%s file %q: %w", format, from, err)
+	}
+	if path != from {
+		defer os.Remove(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s file %q: %w", format, from, err)
+	}
+	var objects []kubernetes.Object
+	var unmapped []string
+	switch format {
+	case FormatCompose:
+		objects, unmapped, err = FromCompose(data, crd)
+	case FormatDigitalOcean:
+		objects, unmapped, err = FromDigitalOcean(data, crd)
+	default:
+		return fmt.Errorf("format %q is not supported", format)
+	}
+	if err != nil {
+		return fmt.Errorf("%s file %q: %w", format, from, err)
+	}
+	for _, object := range objects {
+		if object.Metadata.Annotations[triggermesh.VersionAnnotation] == config.Triggermesh.ComponentsVersion {
+			delete(object.Metadata.Annotations, triggermesh.VersionAnnotation)
+		}
+	}
+	if len(unmapped) != 0 {
+		log.Printf("Some settings could not be mapped to the components:\n%s", strings.Join(unmapped, "\n"))
+	}
+	m := manifest.New("")
+	m.Objects = objects
+	return importManifest(ctx, m, config, crd, values)
+}
+
+func importManifest(ctx context.Context, m *manifest.Manifest, config *cliconfig.Config, crd map[string]crd.CRD, values *Values) error {
+	if values != nil && values.Strict {
+		if err := checkUnresolved(m, values); err != nil {
+			return err
+		}
+	}
 
 	contextName := ""
 	// create broker and its configs first
@@ -52,7 +127,7 @@ func Import(from string, config *cliconfig.Config, crd map[string]crd.CRD) error
 		if _, err := tmbroker.CreateBrokerConfig(config.ConfigHome, contextName); err != nil {
 			return fmt.Errorf("creating broker object: %w", err)
 		}
-		if _, err := tmbroker.New(contextName, config.Triggermesh.Broker); err != nil {
+		if _, err := tmbroker.New(contextName, config.ConfigHome, config.Triggermesh.Broker); err != nil {
 			return fmt.Errorf("creating broker object: %w", err)
 		}
 		break
@@ -61,12 +136,13 @@ func Import(from string, config *cliconfig.Config, crd map[string]crd.CRD) error
 	m.Path = filepath.Join(config.ConfigHome, contextName, triggermesh.ManifestFile)
 
 	// fill in user input, update broker config
+	input := &userInput{values: values}
 	for i, object := range m.Objects {
 		component, err := components.GetObject(object.Metadata.Name, config, m, crd)
 		if err != nil {
 			return err
 		}
-		filledSpec, err := parseUserInputTags(component.GetName(), component.GetKind(), component.GetSpec())
+		filledSpec, err := input.parseUserInputTags(component.GetName(), component.GetKind(), "", component.GetSpec())
 		if err != nil {
 			return err
 		}
@@ -93,29 +169,34 @@ func Import(from string, config *cliconfig.Config, crd map[string]crd.CRD) error
 		Config:   config,
 		Manifest: m,
 		CRD:      crd,
-	}).Describe()
+	}).Describe(ctx)
 
 	log.Printf("Done. Switching context to %q", contextName)
 	return cliconfig.Set("context", contextName)
 }
 
 func getManifest(from string) (*manifest.Manifest, error) {
-	_, err := os.Stat(from)
-	if os.IsNotExist(err) {
-		tempPath, err := fetch(from)
-		if err != nil {
-			return nil, err
-		}
-		defer os.Remove(tempPath)
-		m := manifest.New(tempPath)
-		return m, m.Read()
-	} else if err != nil {
+	path, err := localPath(from)
+	if err != nil {
 		return nil, err
 	}
-	m := manifest.New(from)
+	if path != from {
+		defer os.Remove(path)
+	}
+	m := manifest.New(path)
 	return m, m.Read()
 }
 
+// localPath returns the path of the local file or downloads it into
+// the temporary file if it does not exist.
+func localPath(from string) (string, error) {
+	_, err := os.Stat(from)
+	if os.IsNotExist(err) {
+		return fetch(from)
+	}
+	return from, err
+}
+
 func fetch(url string) (string, error) {
 	resp, err := http.Get(url)
 	if err != nil {
@@ -136,57 +217,115 @@ func fetch(url string) (string, error) {
 	return file.Name(), nil
 }
 
-func parseUserInputTags(name, kind string, spec map[string]interface{}) (map[string]interface{}, error) {
+// checkUnresolved returns the error listing the placeholders
+// that have no value.
+func checkUnresolved(m *manifest.Manifest, values *Values) error {
+	input := &userInput{values: values}
+	for _, object := range m.Objects {
+		spec := object.Spec
+		if object.Kind == "Secret" {
+			spec = make(map[string]interface{}, len(object.Data))
+			for k, v := range object.Data {
+				spec[k] = v
+			}
+		}
+		if _, err := input.parseUserInputTags(object.Metadata.Name, object.Kind, "", spec); err != nil {
+			return err
+		}
+	}
+	if len(input.unresolved) != 0 {
+		sort.Strings(input.unresolved)
+		return fmt.Errorf("unresolved values, use --set, --values or --from-env to provide them:\n%s",
+			strings.Join(input.unresolved, "\n"))
+	}
+	return nil
+}
+
+// userInput resolves the user input placeholders.
+type userInput struct {
+	values *Values
+	// unresolved are the placeholders without value in strict mode.
+	unresolved []string
+}
+
+func (u *userInput) parseUserInputTags(name, kind, prefix string, spec map[string]interface{}) (map[string]interface{}, error) {
 	filledSpec := make(map[string]interface{}, len(spec))
 	for key, value := range spec {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
 		switch v := value.(type) {
 		case string:
 			if v != triggermesh.UserInputTag {
 				filledSpec[key] = v
 				continue
 			}
-			fmt.Printf("%s/%s: ", name, key)
-			input, err := readStdin()
+			input, err := u.resolve(name, kind, path)
 			if err != nil {
 				return nil, err
 			}
-			if kind == "Secret" {
-				input = base64.StdEncoding.EncodeToString([]byte(input))
-			}
 			filledSpec[key] = input
 		case map[string]interface{}:
-			filled, err := parseUserInputTags(name, kind, v)
+			filled, err := u.parseUserInputTags(name, kind, path, v)
 			if err != nil {
 				return nil, err
 			}
 			filledSpec[key] = filled
 		case []interface{}:
 			var items []interface{}
-			for _, item := range v {
-				if itemObject, ok := item.(map[string]interface{}); ok {
-					filled, err := parseUserInputTags(name, kind, itemObject)
+			for i, item := range v {
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				switch itemValue := item.(type) {
+				case map[string]interface{}:
+					filled, err := u.parseUserInputTags(name, kind, itemPath, itemValue)
 					if err != nil {
 						return nil, err
 					}
 					items = append(items, filled)
-				} else if itemString, ok := item.(string); ok {
-					if itemString != triggermesh.UserInputTag {
+				case string:
+					if itemValue != triggermesh.UserInputTag {
+						items = append(items, itemValue)
 						continue
 					}
-					fmt.Printf("%s/%s: ", name, key)
-					input, err := readStdin()
+					input, err := u.resolve(name, kind, itemPath)
 					if err != nil {
 						return nil, err
 					}
 					items = append(items, input)
+				default:
+					items = append(items, item)
 				}
-				filledSpec[key] = items
 			}
+			filledSpec[key] = items
+		default:
+			filledSpec[key] = value
 		}
 	}
 	return filledSpec, nil
 }
 
+// resolve returns the placeholder value from the provided values
+// or, if strict mode is disabled, from the standard input.
+func (u *userInput) resolve(name, kind, path string) (string, error) {
+	input, exists := u.values.lookup(name, path)
+	if !exists {
+		if u.values != nil && u.values.Strict {
+			u.unresolved = append(u.unresolved, fmt.Sprintf("%s.%s (%s)", name, path, EnvName(name, path)))
+			return triggermesh.UserInputTag, nil
+		}
+		fmt.Printf("%s/%s: ", name, path)
+		var err error
+		if input, err = readStdin(); err != nil {
+			return "", err
+		}
+	}
+	if kind == "Secret" && !secrets.IsReference(input) {
+		input = base64.StdEncoding.EncodeToString([]byte(input))
+	}
+	return input, nil
+}
+
 func readStdin() (string, error) {
 	var line string
 	scn := bufio.NewScanner(os.Stdin)
@@ -0,0 +1,764 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/env"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components"
+	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/components/service"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+)
+
+// Import formats.
+const (
+	FormatManifest     = "manifest"
+	FormatCompose      = "compose"
+	FormatDigitalOcean = "digitalocean"
+)
+
+const (
+	brokerConfigEnv    = "BROKER_CONFIG"
+	sinkEnv            = "K_SINK"
+	adapterImageSuffix = "-adapter"
+
+	probePrefix        = "tmctlprobe"
+	probeARNPrefix     = "arn:aws:sqs:us-east-1:000000000000:" + probePrefix
+	probeIntegerOffset = 100000
+)
+
+var privateURL = regexp.MustCompile(`^\$\{([^.}]+)\.PRIVATE_URL\}`)
+
+// platformComponent is the container described in the exported platform spec.
+type platformComponent struct {
+	name string
+	// image is the full image reference, repository is its name
+	// without the registry and the tag, e.g. "awssqssource-adapter".
+	image      string
+	repository string
+	tag        string
+	env        []corev1.EnvVar
+	entrypoint []string
+
+	annotations map[string]string
+}
+
+// FromCompose converts the docker-compose file created with
+// "tmctl dump -p docker-compose" into the manifest objects.
+// The second value lists the settings that could not be mapped.
+func FromCompose(data []byte, crds map[string]crd.CRD) ([]kubernetes.Object, []string, error) {
+	var compose struct {
+		Services map[string]map[string]interface{} `json:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, nil, fmt.Errorf("compose file: %w", err)
+	}
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var report []string
+	var result []platformComponent
+	for _, name := range names {
+		raw := compose.Services[name]
+		// the environment may be written as the map too
+		if environment, ok := raw["environment"].(map[string]interface{}); ok {
+			var list []interface{}
+			for k, v := range environment {
+				list = append(list, fmt.Sprintf("%s=%v", k, v))
+			}
+			raw["environment"] = list
+		}
+		delete(raw, "ports")
+		jsn, err := json.Marshal(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("service %q: %w", name, err)
+		}
+		var s docker.ComposeService
+		if err := json.Unmarshal(jsn, &s); err != nil {
+			return nil, nil, fmt.Errorf("service %q: %w", name, err)
+		}
+		if s.ContainerName != "" {
+			name = s.ContainerName
+		}
+		c := platformComponent{
+			name:        name,
+			image:       s.Image,
+			entrypoint:  s.Entrypoint,
+			annotations: make(map[string]string),
+		}
+		c.repository, c.tag = splitImage(s.Image)
+		for _, e := range s.Environment {
+			k, v, _ := strings.Cut(e, "=")
+			c.env = append(c.env, corev1.EnvVar{Name: k, Value: v})
+		}
+		var resources []string
+		if s.CPUs != "" {
+			resources = append(resources, "cpu="+s.CPUs)
+		}
+		if s.MemLimit != 0 {
+			resources = append(resources, "memory="+resource.NewQuantity(s.MemLimit, resource.BinarySI).String())
+		}
+		if len(resources) != 0 {
+			c.annotations[triggermesh.ResourcesAnnotation] = strings.Join(resources, ",")
+		}
+		if len(s.Volumes) != 0 {
+			if mounts, err := adapter.ParseMounts(strings.Join(s.Volumes, "\n")); err == nil {
+				c.annotations[triggermesh.MountsAnnotation] = adapter.FormatMounts(mounts)
+			} else {
+				report = append(report, fmt.Sprintf("%s: volumes %s", name, strings.Join(s.Volumes, ", ")))
+			}
+		}
+		result = append(result, c)
+	}
+	objects, unmapped, err := fromPlatform(result, crds)
+	return objects, append(report, unmapped...), err
+}
+
+// FromDigitalOcean converts the App Platform spec created with
+// "tmctl dump -p digitalocean" into the manifest objects.
+// The second value lists the settings that could not be mapped.
+func FromDigitalOcean(data []byte, crds map[string]crd.CRD) ([]kubernetes.Object, []string, error) {
+	var app godo.AppSpec
+	if err := yaml.Unmarshal(data, &app); err != nil {
+		return nil, nil, fmt.Errorf("app spec: %w", err)
+	}
+	var report []string
+	var result []platformComponent
+	add := func(name string, image *godo.ImageSourceSpec, envs []*godo.AppVariableDefinition, command string) {
+		if image == nil {
+			report = append(report, fmt.Sprintf("%s: only image based components are supported", name))
+			return
+		}
+		if image.Repository == "" {
+			report = append(report, fmt.Sprintf("%s: image repository is not set", name))
+			return
+		}
+		c := platformComponent{
+			name:        name,
+			repository:  image.Repository,
+			tag:         image.Tag,
+			image:       doImage(image),
+			annotations: make(map[string]string),
+		}
+		if command != "" {
+			c.entrypoint = strings.Fields(command)
+		}
+		for _, e := range envs {
+			c.env = append(c.env, corev1.EnvVar{Name: e.Key, Value: e.Value})
+		}
+		result = append(result, c)
+	}
+	for _, s := range app.Services {
+		add(s.Name, s.Image, s.Envs, s.RunCommand)
+	}
+	for _, w := range app.Workers {
+		add(w.Name, w.Image, w.Envs, w.RunCommand)
+	}
+	for _, j := range app.Jobs {
+		report = append(report, fmt.Sprintf("%s: jobs are not supported", j.Name))
+	}
+	for _, s := range app.StaticSites {
+		report = append(report, fmt.Sprintf("%s: static sites are not supported", s.Name))
+	}
+	for _, d := range app.Databases {
+		report = append(report, fmt.Sprintf("%s: databases are not supported", d.Name))
+	}
+	for _, e := range app.Envs {
+		report = append(report, fmt.Sprintf("app variable %s", e.Key))
+	}
+	objects, unmapped, err := fromPlatform(result, crds)
+	return objects, append(report, unmapped...), err
+}
+
+// fromPlatform recognizes the broker, TriggerMesh adapters and user services
+// among the platform components and restores the broker triggers
+// from the static broker configuration.
+func fromPlatform(platformComponents []platformComponent, crds map[string]crd.CRD) ([]kubernetes.Object, []string, error) {
+	var report []string
+	var broker *platformComponent
+	for i, c := range platformComponents {
+		if c.repository != "memory-broker" && c.repository != "redis-broker" {
+			continue
+		}
+		if broker != nil {
+			return nil, nil, fmt.Errorf("multiple brokers found: %q, %q", broker.name, c.name)
+		}
+		broker = &platformComponents[i]
+	}
+	if broker == nil {
+		return nil, nil, fmt.Errorf("broker not found")
+	}
+	objects := []kubernetes.Object{{
+		APIVersion: tmbroker.APIVersion,
+		Kind:       tmbroker.BrokerKind,
+		Metadata: kubernetes.Metadata{
+			Name:   broker.name,
+			Labels: map[string]string{contextLabel: broker.name},
+		},
+	}}
+
+	var config tmbroker.Configuration
+	for _, e := range broker.env {
+		if e.Name != brokerConfigEnv {
+			report = append(report, fmt.Sprintf("%s: env %s", broker.name, e.Name))
+			continue
+		}
+		if err := json.Unmarshal([]byte(e.Value), &config); err != nil {
+			return nil, nil, fmt.Errorf("broker %q configuration: %w", broker.name, err)
+		}
+	}
+
+	imported := make(map[string]kubernetes.Object)
+	for _, c := range platformComponents {
+		if c.name == broker.name {
+			continue
+		}
+		var result []kubernetes.Object
+		var unmapped []string
+		var err error
+		if kinds := adapterKinds(c.repository, crds); len(kinds) != 0 {
+			result, unmapped, err = adapterObjects(c, kinds, broker.name)
+		} else {
+			result, unmapped, err = serviceObject(c, broker.name, config)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("component %q: %w", c.name, err)
+		}
+		report = append(report, unmapped...)
+		objects = append(objects, result...)
+		imported[c.name] = result[len(result)-1]
+	}
+
+	names := make([]string, 0, len(config.Triggers))
+	for name := range config.Triggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		trigger := config.Triggers[name]
+		target, exists := imported[destination(trigger.Target.URL)]
+		if !exists {
+			report = append(report, fmt.Sprintf("trigger %s: target %q is not imported", name, trigger.Target.URL))
+			continue
+		}
+		object, err := triggerObject(name, broker.name, trigger, target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("trigger %q: %w", name, err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, report, nil
+}
+
+// adapterKinds returns the kinds that run on the adapter image.
+func adapterKinds(repository string, crds map[string]crd.CRD) []crd.CRD {
+	if !strings.HasSuffix(repository, adapterImageSuffix) {
+		return nil
+	}
+	var result []crd.CRD
+	for _, c := range crds {
+		switch c.Spec.Group {
+		case "sources.triggermesh.io", "targets.triggermesh.io", "flow.triggermesh.io":
+		default:
+			continue
+		}
+		u := unstructured.Unstructured{}
+		u.SetKind(c.Spec.Names.Kind)
+		if r, _ := splitImage(adapter.Image(u, "")); r == repository {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Spec.Names.Kind < result[j].Spec.Names.Kind
+	})
+	return result
+}
+
+// adapterObjects restores the TriggerMesh component and its secret.
+// When several kinds share the adapter image, the kind that maps most of
+// the environment variables is selected.
+func adapterObjects(c platformComponent, kinds []crd.CRD, broker string) ([]kubernetes.Object, []string, error) {
+	var best *adapterSpec
+	for _, kind := range kinds {
+		mapping, err := probeEnv(kind, c.name)
+		if err != nil {
+			continue
+		}
+		spec := mapping.spec(c.env)
+		if best == nil || spec.score() > best.score() ||
+			(spec.score() == best.score() && strings.ToLower(kind.Spec.Names.Kind)+adapterImageSuffix == c.repository) {
+			best = &spec
+		}
+	}
+	if best == nil {
+		return nil, nil, fmt.Errorf("image %q: adapter environment is not supported", c.image)
+	}
+
+	var report []string
+	annotations := c.annotations
+	if c.tag != "" && c.tag != "latest" {
+		annotations[triggermesh.VersionAnnotation] = c.tag
+	}
+	var override []corev1.EnvVar
+	for _, e := range best.unmapped {
+		if e.Name == sinkEnv && best.kind.Spec.Group == "sources.triggermesh.io" {
+			if destination(e.Value) != broker {
+				report = append(report, fmt.Sprintf("%s: sink %q is replaced with the broker", c.name, e.Value))
+			}
+			continue
+		}
+		override = append(override, e)
+		report = append(report, fmt.Sprintf("%s: env %s is kept as the adapter override", c.name, e.Name))
+	}
+	if len(override) != 0 {
+		annotations[triggermesh.EnvAnnotation] = adapter.FormatEnv(override)
+	}
+	if len(c.entrypoint) != 0 {
+		report = append(report, fmt.Sprintf("%s: entrypoint %s", c.name, strings.Join(c.entrypoint, " ")))
+	}
+
+	for _, path := range best.requestRequired() {
+		report = append(report, fmt.Sprintf("%s: %s is not found in the environment", c.name, path))
+	}
+
+	secrets, err := crd.ExtractSecrets(c.name, *best.schema, best.values)
+	if err != nil {
+		return nil, nil, err
+	}
+	if best.kind.Spec.Group == "sources.triggermesh.io" {
+		best.values["sink"] = map[string]interface{}{
+			"ref": map[string]interface{}{
+				"apiVersion": tmbroker.APIVersion,
+				"kind":       tmbroker.BrokerKind,
+				"name":       broker,
+			},
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	object := kubernetes.Object{
+		APIVersion: best.apiVersion,
+		Kind:       best.kind.Spec.Names.Kind,
+		Metadata: kubernetes.Metadata{
+			Name:        c.name,
+			Labels:      map[string]string{contextLabel: broker},
+			Annotations: annotations,
+		},
+		Spec: best.values,
+	}
+	if len(secrets) == 0 {
+		return []kubernetes.Object{object}, report, nil
+	}
+	for k, v := range secrets {
+		// keep the placeholders of the dumps without secrets
+		if decoded, _ := base64.StdEncoding.DecodeString(v); string(decoded) == triggermesh.UserInputTag {
+			secrets[k] = triggermesh.UserInputTag
+		}
+	}
+	return []kubernetes.Object{{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: kubernetes.Metadata{
+			Name:   strings.ToLower(c.name) + "-secret",
+			Labels: map[string]string{contextLabel: broker},
+		},
+		Type: "Opaque",
+		Data: secrets,
+	}, object}, report, nil
+}
+
+// serviceObject restores the user service. Services sending events to the
+// broker are sources, the rest of them are targets.
+func serviceObject(c platformComponent, broker string, config tmbroker.Configuration) ([]kubernetes.Object, []string, error) {
+	var report []string
+	role := service.Consumer
+	params := make(map[string]string, len(c.env))
+	for _, e := range c.env {
+		if e.Name == sinkEnv && destination(e.Value) == broker {
+			role = service.Producer
+			continue
+		}
+		params[e.Name] = e.Value
+	}
+	if len(c.entrypoint) != 0 {
+		report = append(report, fmt.Sprintf("%s: entrypoint %s", c.name, strings.Join(c.entrypoint, " ")))
+	}
+	if len(c.annotations) == 0 {
+		c.annotations = nil
+	}
+	object, err := components.Annotate(service.New(c.name, c.image, broker, role, params), c.annotations).AsK8sObject()
+	if err != nil {
+		return nil, nil, err
+	}
+	return []kubernetes.Object{object}, report, nil
+}
+
+func triggerObject(name, broker string, trigger tmbroker.LocalTriggerSpec, target kubernetes.Object) (kubernetes.Object, error) {
+	spec := map[string]interface{}{
+		"broker": map[string]interface{}{
+			"group": "eventing.triggermesh.io",
+			"kind":  tmbroker.BrokerKind,
+			"name":  broker,
+		},
+		"target": map[string]interface{}{
+			"ref": map[string]interface{}{
+				"apiVersion": target.APIVersion,
+				"kind":       target.Kind,
+				"name":       target.Metadata.Name,
+			},
+		},
+	}
+	if len(trigger.Filters) != 0 {
+		jsn, err := json.Marshal(trigger.Filters)
+		if err != nil {
+			return kubernetes.Object{}, err
+		}
+		var filters []interface{}
+		if err := json.Unmarshal(jsn, &filters); err != nil {
+			return kubernetes.Object{}, err
+		}
+		spec["filters"] = filters
+	}
+	return kubernetes.Object{
+		APIVersion: tmbroker.APIVersion,
+		Kind:       tmbroker.TriggerKind,
+		Metadata: kubernetes.Metadata{
+			Name:   name,
+			Labels: map[string]string{contextLabel: broker},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// destination returns the component name from the exported URL, either
+// "http://<name>:8080" or "${<name>.PRIVATE_URL}/<path>".
+func destination(value string) string {
+	if match := privateURL.FindStringSubmatch(value); len(match) == 2 {
+		return match[1]
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// splitImage returns the image name without the registry and the tag.
+func splitImage(image string) (string, string) {
+	name := image[strings.LastIndex(image, "/")+1:]
+	repository, tag, _ := strings.Cut(name, ":")
+	return repository, tag
+}
+
+func doImage(image *godo.ImageSourceSpec) string {
+	ref := image.Repository
+	if image.Registry != "" {
+		ref = image.Registry + "/" + ref
+	}
+	if image.RegistryType == godo.ImageSourceSpecRegistryType_DOCR {
+		ref = "registry.digitalocean.com/" + ref
+	}
+	if image.Tag != "" {
+		ref += ":" + image.Tag
+	}
+	return ref
+}
+
+// envField is the spec field that the adapter environment variable is built from.
+type envField struct {
+	path   []string
+	format string
+	secret bool
+}
+
+// envMapping is the adapter environment of the kind. It is learned by
+// building the environment from the specs with one field set to the
+// probe value at a time.
+type envMapping struct {
+	kind       crd.CRD
+	apiVersion string
+	schema     *crd.Schema
+	fields     map[string]envField
+	// defaults are the variables of the empty spec.
+	defaults map[string]string
+}
+
+// adapterSpec is the spec restored from the environment.
+type adapterSpec struct {
+	*envMapping
+	values   map[string]interface{}
+	mapped   int
+	unmapped []corev1.EnvVar
+}
+
+func (s adapterSpec) score() int {
+	return s.mapped - len(s.unmapped)
+}
+
+type probe struct {
+	value    interface{}
+	expected string
+	format   string
+}
+
+func probeEnv(c crd.CRD, name string) (*envMapping, error) {
+	schema, err := crd.ServedSchema(c)
+	if err != nil {
+		return nil, err
+	}
+	root, err := schema.Explain()
+	if err != nil {
+		return nil, err
+	}
+	m := &envMapping{
+		kind:     c,
+		schema:   schema,
+		fields:   make(map[string]envField),
+		defaults: make(map[string]string),
+	}
+	for _, v := range c.Spec.Versions {
+		if v.Served {
+			m.apiVersion = c.Spec.Group + "/" + v.Name
+			break
+		}
+	}
+	defaults, err := m.build(name, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range defaults {
+		if e.ValueFrom == nil {
+			m.defaults[e.Name] = e.Value
+		}
+	}
+
+	var leaves []envField
+	collectLeaves(root.Fields, nil, &leaves)
+	for i, leaf := range leaves {
+		for _, p := range probes(leaf, i) {
+			spec := map[string]interface{}{}
+			if err := unstructured.SetNestedField(spec, p.value, leaf.path...); err != nil {
+				continue
+			}
+			if leaf.secret {
+				if _, err := crd.ExtractSecrets(name, *schema, spec); err != nil {
+					continue
+				}
+			}
+			envs, err := m.build(name, spec)
+			if err != nil {
+				continue
+			}
+			found := false
+			for _, e := range envs {
+				if _, claimed := m.fields[e.Name]; claimed {
+					continue
+				}
+				var match bool
+				if leaf.secret {
+					match = e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil &&
+						e.ValueFrom.SecretKeyRef.Key == leaf.path[len(leaf.path)-1]
+				} else {
+					def, set := m.defaults[e.Name]
+					match = e.ValueFrom == nil && e.Value == p.expected && (!set || def != p.expected)
+				}
+				if match {
+					m.fields[e.Name] = envField{path: leaf.path, format: p.format, secret: leaf.secret}
+					found = true
+				}
+			}
+			if found {
+				break
+			}
+		}
+	}
+	return m, nil
+}
+
+// build returns the adapter environment of the spec.
+func (m *envMapping) build(name string, spec map[string]interface{}) (envs []corev1.EnvVar, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("adapter environment: %v", r)
+		}
+	}()
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion(m.apiVersion)
+	u.SetKind(m.kind.Spec.Names.Kind)
+	u.SetName(name)
+	u.SetNamespace(triggermesh.Namespace)
+	if err := unstructured.SetNestedField(u.Object, spec, "spec"); err != nil {
+		return nil, err
+	}
+	return env.Build(u)
+}
+
+// spec converts the environment variables into the spec values.
+func (m *envMapping) spec(envs []corev1.EnvVar) adapterSpec {
+	result := adapterSpec{
+		envMapping: m,
+		values:     make(map[string]interface{}),
+	}
+	for _, e := range envs {
+		if def, set := m.defaults[e.Name]; set && def == e.Value {
+			continue
+		}
+		field, exists := m.fields[e.Name]
+		if !exists {
+			result.unmapped = append(result.unmapped, e)
+			continue
+		}
+		value, err := field.parse(e.Value)
+		if err != nil {
+			result.unmapped = append(result.unmapped, e)
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if err := unstructured.SetNestedField(result.values, value, field.path...); err != nil {
+			result.unmapped = append(result.unmapped, e)
+			continue
+		}
+		result.mapped++
+	}
+	return result
+}
+
+// requestRequired sets the missing required string fields to the user input
+// placeholders and returns the paths of all missing required fields.
+func (s adapterSpec) requestRequired() []string {
+	root, err := s.schema.Explain()
+	if err != nil {
+		return nil
+	}
+	var missing []string
+	var walk func(fields []crd.Field, values map[string]interface{}, prefix string)
+	walk = func(fields []crd.Field, values map[string]interface{}, prefix string) {
+		for _, f := range fields {
+			path := prefix + "." + f.Name
+			value, set := values[f.Name]
+			if f.Type == "object" && !f.Secret {
+				nested, ok := value.(map[string]interface{})
+				if !set && f.Required {
+					nested, ok = map[string]interface{}{}, true
+					values[f.Name] = nested
+				}
+				if ok {
+					walk(f.Fields, nested, path)
+				}
+				continue
+			}
+			if set || !f.Required {
+				continue
+			}
+			switch {
+			case f.Secret, f.Type == "string":
+				values[f.Name] = triggermesh.UserInputTag
+			case f.Type == "[]string":
+				values[f.Name] = []interface{}{triggermesh.UserInputTag}
+			}
+			missing = append(missing, path)
+		}
+	}
+	walk(root.Fields, s.values, "spec")
+	return missing
+}
+
+func (f envField) parse(value string) (interface{}, error) {
+	switch f.format {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "list":
+		var list []interface{}
+		for _, item := range strings.Split(value, ",") {
+			list = append(list, item)
+		}
+		return list, nil
+	case "json":
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+func collectLeaves(fields []crd.Field, prefix []string, leaves *[]envField) {
+	for _, f := range fields {
+		path := append(append([]string{}, prefix...), f.Name)
+		if f.Type == "object" && len(f.Fields) != 0 && !f.Secret {
+			collectLeaves(f.Fields, path, leaves)
+			continue
+		}
+		*leaves = append(*leaves, envField{path: path, format: f.Type, secret: f.Secret})
+	}
+}
+
+// probes returns the values of the field that can be found
+// in the environment, ordered by their uniqueness.
+func probes(leaf envField, i int) []probe {
+	marker := probePrefix + strconv.Itoa(i)
+	switch {
+	case leaf.secret, leaf.format == "string":
+		return []probe{
+			{value: marker, expected: marker, format: "string"},
+			{value: probeARNPrefix + strconv.Itoa(i), expected: probeARNPrefix + strconv.Itoa(i), format: "string"},
+		}
+	case leaf.format == "integer":
+		n := int64(probeIntegerOffset + i)
+		return []probe{{value: n, expected: strconv.FormatInt(n, 10), format: "integer"}}
+	case leaf.format == "boolean":
+		return []probe{{value: true, expected: "true", format: "boolean"}}
+	case leaf.format == "[]string":
+		return []probe{
+			{value: []interface{}{marker}, expected: marker, format: "list"},
+			{value: []interface{}{marker}, expected: `["` + marker + `"]`, format: "json"},
+		}
+	case strings.HasPrefix(leaf.format, "[]"):
+		return []probe{{value: []interface{}{}, expected: "[]", format: "json"}}
+	case strings.HasPrefix(leaf.format, "map["), leaf.format == "object":
+		return []probe{{value: map[string]interface{}{}, expected: "{}", format: "json"}}
+	}
+	return nil
+}
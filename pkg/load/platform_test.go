@@ -0,0 +1,107 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/test"
+)
+
+const compose = `services:
+  foo:
+    container_name: foo
+    entrypoint: [/memory-broker, start]
+    environment:
+    - 'BROKER_CONFIG={"triggers":{"to-http":{"filters":[{"exact":{"type":"com.amazon.s3.objectcreated"}}],"target":{"url":"http://foo-httptarget:8080"}},"to-display":{"target":{"url":"http://display:8080"}},"to-missing":{"target":{"url":"http://missing:8080"}}}}'
+    image: gcr.io/triggermesh/memory-broker:v1.1.0
+  foo-awss3source:
+    container_name: foo-awss3source
+    cpus: "0.5"
+    environment:
+    - AWS_ACCESS_KEY_ID=AKID
+    - AWS_SECRET_ACCESS_KEY=SECRET
+    - ARN=
+    - SQS_MESSAGE_PROCESSOR=s3
+    - K_SINK=http://foo:8080
+    - DEBUG=true
+    image: gcr.io/triggermesh/awssqssource-adapter:v1.26.0
+  foo-httptarget:
+    container_name: foo-httptarget
+    environment:
+      HTTP_URL: http://example.com
+      HTTP_METHOD: GET
+    image: gcr.io/triggermesh/httptarget-adapter:v1.26.0
+  foo-transformation:
+    container_name: foo-transformation
+    environment:
+    - TRANSFORMATION_CONTEXT=null
+    - 'TRANSFORMATION_DATA=[{"operation":"add","paths":[{"key":"foo","value":"bar"}]}]'
+    image: gcr.io/triggermesh/transformation-adapter:v1.26.0
+  display:
+    container_name: display
+    environment:
+    - FOO=bar
+    image: docker.io/n3wscott/sockeye:v0.7.0
+`
+
+func TestFromCompose(t *testing.T) {
+	objects, report, err := FromCompose([]byte(compose), test.CRD())
+	assert.NoError(t, err)
+
+	byName := make(map[string]kubernetes.Object, len(objects))
+	for _, o := range objects {
+		assert.Equal(t, "foo", o.Metadata.Labels[contextLabel])
+		byName[o.Metadata.Name] = o
+	}
+	assert.Len(t, objects, 8)
+	assert.Equal(t, "RedisBroker", byName["foo"].Kind)
+
+	source := byName["foo-awss3source"]
+	assert.Equal(t, "AWSS3Source", source.Kind)
+	assert.Equal(t, triggermesh.UserInputTag, source.Spec["arn"])
+	assert.Equal(t, "foo", source.Spec["sink"].(map[string]interface{})["ref"].(map[string]interface{})["name"])
+	assert.Equal(t, "cpu=0.5", source.Metadata.Annotations[triggermesh.ResourcesAnnotation])
+	assert.Equal(t, "DEBUG=true", source.Metadata.Annotations[triggermesh.EnvAnnotation])
+	assert.Equal(t, "v1.26.0", source.Metadata.Annotations[triggermesh.VersionAnnotation])
+	assert.Equal(t, map[string]string{"accessKeyID": "QUtJRA==", "secretAccessKey": "U0VDUkVU"}, byName["foo-awss3source-secret"].Data)
+
+	assert.Equal(t, "http://example.com", byName["foo-httptarget"].Spec["endpoint"])
+	assert.Equal(t, "GET", byName["foo-httptarget"].Spec["method"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"operation": "add",
+		"paths":     []interface{}{map[string]interface{}{"key": "foo", "value": "bar"}},
+	}}, byName["foo-transformation"].Spec["data"])
+
+	assert.Equal(t, "Service", byName["display"].Kind)
+	assert.Equal(t, "target", byName["display"].Metadata.Labels["triggermesh.io/role"])
+
+	assert.Equal(t, "foo-httptarget", byName["to-http"].Spec["target"].(map[string]interface{})["ref"].(map[string]interface{})["name"])
+	assert.Len(t, byName["to-http"].Spec["filters"], 1)
+	assert.Equal(t, "display", byName["to-display"].Spec["target"].(map[string]interface{})["ref"].(map[string]interface{})["name"])
+
+	assert.ElementsMatch(t, []string{
+		"foo-awss3source: env DEBUG is kept as the adapter override",
+		"foo-awss3source: spec.arn is not found in the environment",
+		"foo-awss3source: spec.eventTypes is not found in the environment",
+		`trigger to-missing: target "http://missing:8080" is not imported`,
+	}, report)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var envNameReplacer = regexp.MustCompile("[^A-Z0-9]+")
+
+// Values are the user input placeholders replacements.
+// Values set with Set take precedence over the values file,
+// environment variables are used for the rest of placeholders.
+type Values struct {
+	// FromEnv enables the lookup of the <COMPONENT>_<PATH> environment variables.
+	FromEnv bool
+	// Strict disables the prompt for the unresolved placeholders.
+	Strict bool
+
+	set  map[string]string
+	file map[string]string
+}
+
+// ReadFile reads the values file where the top-level keys are
+// the component names and the nested keys are the spec paths.
+func (v *Values) ReadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("values file %q: %w", path, err)
+	}
+	if v.file == nil {
+		v.file = make(map[string]string)
+	}
+	for component, value := range values {
+		flatten(component, value, v.file)
+	}
+	return nil
+}
+
+// Set parses the "component.path=value" expression.
+func (v *Values) Set(expression string) error {
+	kv := strings.SplitN(expression, "=", 2)
+	if len(kv) != 2 || !strings.Contains(kv[0], ".") {
+		return fmt.Errorf("%q is not in component.key=value format", expression)
+	}
+	if v.set == nil {
+		v.set = make(map[string]string)
+	}
+	v.set[kv[0]] = kv[1]
+	return nil
+}
+
+// lookup returns the value of the component spec path.
+func (v *Values) lookup(component, path string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	key := component + "." + path
+	if value, set := v.set[key]; set {
+		return value, true
+	}
+	if value, set := v.file[key]; set {
+		return value, true
+	}
+	if v.FromEnv {
+		return os.LookupEnv(EnvName(component, path))
+	}
+	return "", false
+}
+
+// EnvName returns the environment variable name of the component spec path:
+// the upper-cased component name and path with non-alphanumeric characters
+// replaced by underscores.
+func EnvName(component, path string) string {
+	return strings.Trim(envNameReplacer.ReplaceAllString(strings.ToUpper(component+"_"+path), "_"), "_")
+}
+
+func flatten(prefix string, value interface{}, result map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, nested := range v {
+			flatten(prefix+"."+k, nested, result)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), nested, result)
+		}
+	case nil:
+		result[prefix] = ""
+	default:
+		result[prefix] = fmt.Sprintf("%v", v)
+	}
+}
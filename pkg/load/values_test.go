@@ -0,0 +1,66 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+func TestValues(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	assert.NoError(t, os.WriteFile(valuesFile, []byte(`
+foo-httptarget:
+  endpoint: https://file.example.com
+  headers:
+    token: from-file
+`), 0600))
+	t.Setenv("FOO_HTTPTARGET_METHOD", "POST")
+	t.Setenv("FOO_SECRET_PASSWORD", "pass")
+
+	values := &Values{FromEnv: true, Strict: true}
+	assert.NoError(t, values.ReadFile(valuesFile))
+	assert.NoError(t, values.Set("foo-httptarget.endpoint=https://set.example.com"))
+	assert.Error(t, values.Set("endpoint"))
+
+	input := &userInput{values: values}
+	spec, err := input.parseUserInputTags("foo-httptarget", "HTTPTarget", "", map[string]interface{}{
+		"endpoint": triggermesh.UserInputTag,
+		"method":   triggermesh.UserInputTag,
+		"headers": map[string]interface{}{
+			"token": triggermesh.UserInputTag,
+		},
+		"eventTypes": []interface{}{"io.triggermesh.sample", triggermesh.UserInputTag},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://set.example.com", spec["endpoint"])
+	assert.Equal(t, "POST", spec["method"])
+	assert.Equal(t, "from-file", spec["headers"].(map[string]interface{})["token"])
+	assert.Equal(t, []interface{}{"io.triggermesh.sample", triggermesh.UserInputTag}, spec["eventTypes"])
+	assert.Equal(t, []string{"foo-httptarget.eventTypes[1] (FOO_HTTPTARGET_EVENTTYPES_1)"}, input.unresolved)
+
+	spec, err = input.parseUserInputTags("foo-secret", "Secret", "", map[string]interface{}{
+		"password": triggermesh.UserInputTag,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "cGFzcw==", spec["password"])
+}
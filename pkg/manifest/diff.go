@@ -0,0 +1,199 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+)
+
+// Object change types.
+const (
+	Added    = "+"
+	Removed  = "-"
+	Modified = "~"
+)
+
+// ObjectDiff is the change of the manifest object.
+type ObjectDiff struct {
+	Change string
+	Kind   string
+	Name   string
+	Fields []FieldDiff
+}
+
+func (d ObjectDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", d.Change, d.Kind, d.Name)
+	for _, field := range d.Fields {
+		fmt.Fprintf(&b, "\n\t%s", field)
+	}
+	return b.String()
+}
+
+// FieldDiff is the changed object field. From and To are JSON-encoded
+// values, empty if the field does not exist.
+type FieldDiff struct {
+	Path string
+	From string
+	To   string
+	// Secret values are not printed.
+	Secret bool
+}
+
+func (d FieldDiff) String() string {
+	switch {
+	case d.From == "":
+		if d.Secret {
+			return fmt.Sprintf("%s: added", d.Path)
+		}
+		return fmt.Sprintf("%s: %s", d.Path, d.To)
+	case d.To == "":
+		return fmt.Sprintf("%s: removed", d.Path)
+	case d.Secret:
+		return fmt.Sprintf("%s: changed", d.Path)
+	}
+	return fmt.Sprintf("%s: %s -> %s", d.Path, d.From, d.To)
+}
+
+// Diff compares the manifest objects by kind and name. Modified objects
+// are listed with the changed fields, secret values are masked.
+func Diff(from, to []kubernetes.Object) ([]ObjectDiff, error) {
+	key := func(o kubernetes.Object) string {
+		return o.Kind + "/" + o.Metadata.Name
+	}
+	toObjects := make(map[string]kubernetes.Object, len(to))
+	for _, o := range to {
+		toObjects[key(o)] = o
+	}
+	fromObjects := make(map[string]struct{}, len(from))
+
+	var result []ObjectDiff
+	for _, o := range from {
+		fromObjects[key(o)] = struct{}{}
+		d := ObjectDiff{Kind: o.Kind, Name: o.Metadata.Name}
+		n, exists := toObjects[key(o)]
+		if !exists {
+			d.Change = Removed
+			result = append(result, d)
+			continue
+		}
+		fields, err := DiffValues(o, n)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key(o), err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if o.Kind == "Secret" {
+			for i := range fields {
+				fields[i].Secret = strings.HasPrefix(fields[i].Path, "data.")
+			}
+		}
+		d.Change = Modified
+		d.Fields = fields
+		result = append(result, d)
+	}
+	for _, o := range to {
+		if _, exists := fromObjects[key(o)]; !exists {
+			result = append(result, ObjectDiff{Change: Added, Kind: o.Kind, Name: o.Metadata.Name})
+		}
+	}
+	return result, nil
+}
+
+// DiffValues returns the changed fields of two JSON-compatible values.
+func DiffValues(from, to interface{}) ([]FieldDiff, error) {
+	fromFields, err := flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flatten(to)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]struct{}, len(fromFields)+len(toFields))
+	for path := range fromFields {
+		paths[path] = struct{}{}
+	}
+	for path := range toFields {
+		paths[path] = struct{}{}
+	}
+	var result []FieldDiff
+	for path := range paths {
+		if fromFields[path] != toFields[path] {
+			result = append(result, FieldDiff{Path: path, From: fromFields[path], To: toFields[path]})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+// flatten returns the JSON-encoded leaf values of the value by their paths.
+func flatten(value interface{}) (map[string]string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	return result, flattenInto(result, "", generic)
+}
+
+func flattenInto(result map[string]string, prefix string, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) != 0 {
+			for key, item := range v {
+				path := key
+				if prefix != "" {
+					path = prefix + "." + key
+				}
+				if err := flattenInto(result, path, item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if len(v) != 0 {
+			for i, item := range v {
+				if err := flattenInto(result, fmt.Sprintf("%s[%d]", prefix, i), item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case nil:
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	result[prefix] = string(data)
+	return nil
+}
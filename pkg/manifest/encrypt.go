@@ -0,0 +1,149 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"os"
+
+	"filippo.io/age"
+
+	"github.com/triggermesh/tmctl/pkg/file"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/secrets"
+)
+
+// ciphertext is the encrypted secret value read from the manifest file.
+type ciphertext struct {
+	plain     string
+	encrypted string
+}
+
+// decryptSecrets replaces the encrypted secret values with the plain ones.
+// Values encrypted with the current key are returned, so that unchanged
+// values are written back as is. Values that cannot be decrypted are kept.
+func decryptSecrets(objects []kubernetes.Object) (map[string]ciphertext, error) {
+	ciphertexts := make(map[string]ciphertext)
+	var keys []*age.X25519Identity
+	for _, object := range objects {
+		if object.Kind != "Secret" {
+			continue
+		}
+		for k, v := range object.Data {
+			if !secrets.IsEncrypted(v) {
+				continue
+			}
+			if keys == nil {
+				var err error
+				if keys, err = secrets.Keys(); err != nil {
+					return nil, err
+				}
+			}
+			plain, index, err := secrets.Decrypt(keys, v)
+			if err != nil {
+				continue
+			}
+			object.Data[k] = plain
+			if index == 0 {
+				ciphertexts[secretKey(object, k)] = ciphertext{plain: plain, encrypted: v}
+			}
+		}
+	}
+	return ciphertexts, nil
+}
+
+// encryptSecret returns the secret object with the values encrypted
+// by the current key, new ciphertexts are added to the map. References
+// are not encrypted. Object is returned as is if encryption is disabled.
+func encryptSecret(object kubernetes.Object, ciphertexts map[string]ciphertext) (kubernetes.Object, error) {
+	if object.Kind != "Secret" || len(object.Data) == 0 {
+		return object, nil
+	}
+	key, err := secrets.Key()
+	if err != nil || key == nil {
+		return object, err
+	}
+	data := make(map[string]string, len(object.Data))
+	for k, v := range object.Data {
+		if secrets.IsEncrypted(v) || secrets.IsReference(v) {
+			data[k] = v
+			continue
+		}
+		if c, exists := ciphertexts[secretKey(object, k)]; exists && c.plain == v {
+			data[k] = c.encrypted
+			continue
+		}
+		if data[k], err = secrets.Encrypt(key, v); err != nil {
+			return object, fmt.Errorf("encrypting %q: %w", object.Metadata.Name, err)
+		}
+		ciphertexts[secretKey(object, k)] = ciphertext{plain: v, encrypted: data[k]}
+	}
+	object.Data = data
+	return object, nil
+}
+
+// EncryptFile encrypts the secret values of the manifest file with the
+// current key. Unlike Write, the change is not recorded in the history.
+func EncryptFile(path string) error {
+	unlock, err := file.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	m := New(path)
+	if m.Objects, m.ciphertexts, err = parseYAML(path); err != nil {
+		return err
+	}
+	for _, object := range m.Objects {
+		for k, v := range object.Data {
+			if secrets.IsEncrypted(v) {
+				return fmt.Errorf("secret %q: %s: %w", object.Metadata.Name, k, secrets.ErrNoKey)
+			}
+		}
+	}
+	output, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	return file.WriteAtomic(path, output, file.PrivatePerm)
+}
+
+// ReencryptFile replaces the encrypted values in the manifest file with
+// the ones encrypted by the current key keeping the rest of the file.
+func ReencryptFile(path string) error {
+	unlock, err := file.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !secrets.Encrypted.Match(data) {
+		return nil
+	}
+	output, err := secrets.Reencrypt(data)
+	if err != nil {
+		return err
+	}
+	return file.WriteAtomic(path, output, file.PrivatePerm)
+}
+
+func secretKey(object kubernetes.Object, key string) string {
+	return objectKey(object) + "/" + key
+}
@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 
 	"gopkg.in/yaml.v3"
 	kyaml "sigs.k8s.io/yaml"
 
+	"github.com/triggermesh/tmctl/pkg/file"
+	"github.com/triggermesh/tmctl/pkg/history"
 	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 )
@@ -35,6 +39,11 @@ type Manifest struct {
 	mut     sync.Mutex
 	Path    string
 	Objects []kubernetes.Object
+
+	// resolved are the objects with the references replaced by Resolve.
+	resolved map[string]resolvedObject
+	// ciphertexts are the encrypted secret values by the decrypted ones.
+	ciphertexts map[string]ciphertext
 }
 
 func New(path string) *Manifest {
@@ -43,10 +52,13 @@ func New(path string) *Manifest {
 	}
 }
 
+// Read parses the manifest file. The lock is not needed, the file
+// is always replaced atomically and the changes made with Add,
+// Remove and SetAnnotation are applied to the file read under lock.
 func (m *Manifest) Read() error {
 	m.mut.Lock()
 	defer m.mut.Unlock()
-	o, err := parseYAML(m.Path)
+	o, ciphertexts, err := parseYAML(m.Path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("manifest does not exist, please create the broker")
@@ -54,64 +66,271 @@ func (m *Manifest) Read() error {
 		return err
 	}
 	m.Objects = o
+	m.resolved = nil
+	m.ciphertexts = ciphertexts
 	return nil
 }
 
+// Write replaces the manifest file atomically under the
+// lock shared with the other CLI processes and records the
+// change in the broker history. Secret values are encrypted
+// if the encryption key is set.
 func (m *Manifest) Write() error {
-	var output []byte
+	output, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	unlock, err := file.Lock(m.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return history.Record(filepath.Dir(m.Path), func() error {
+		return file.WriteAtomic(m.Path, output, file.PrivatePerm)
+	})
+}
+
+// WriteFile replaces the manifest file like Write, but the change
+// is not recorded in the history.
+func (m *Manifest) WriteFile() error {
+	output, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	unlock, err := file.Lock(m.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return file.WriteAtomic(m.Path, output, file.PrivatePerm)
+}
+
+func (m *Manifest) marshal() ([]byte, error) {
+	var objects []kubernetes.Object
 	for _, object := range m.Objects {
+		if object, write := m.restore(object); write {
+			objects = append(objects, object)
+		}
+	}
+	return m.marshalObjects(objects)
+}
+
+// marshalObjects encrypts the secrets and marshals the objects
+// that are already restored to their original values.
+func (m *Manifest) marshalObjects(objects []kubernetes.Object) ([]byte, error) {
+	if m.ciphertexts == nil {
+		m.ciphertexts = make(map[string]ciphertext)
+	}
+	var output []byte
+	for _, object := range objects {
+		object, err := encryptSecret(object, m.ciphertexts)
+		if err != nil {
+			return nil, err
+		}
 		body, err := kyaml.Marshal(object)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		body = append([]byte("---\n"), body...)
 		output = append(output, body...)
 	}
-	if err := os.WriteFile(m.Path, output, os.ModePerm); err != nil {
+	return output, nil
+}
+
+// update applies the change to the objects of the manifest file and writes
+// it back. The file is read again under the lock held for the whole
+// read-modify-write, so that the changes made by the other CLI processes
+// since the manifest has been read are not overwritten.
+func (m *Manifest) update(change func([]kubernetes.Object) []kubernetes.Object) error {
+	unlock, err := file.Lock(m.Path)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer unlock()
+	objects, ciphertexts, err := parseYAML(m.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if m.ciphertexts == nil {
+		m.ciphertexts = make(map[string]ciphertext, len(ciphertexts))
+	}
+	for k, v := range ciphertexts {
+		m.ciphertexts[k] = v
+	}
+	output, err := m.marshalObjects(change(objects))
+	if err != nil {
+		return err
+	}
+	return history.Record(filepath.Dir(m.Path), func() error {
+		return file.WriteAtomic(m.Path, output, file.PrivatePerm)
+	})
 }
 
 func (m *Manifest) Add(object triggermesh.Component) (bool, error) {
-	m.mut.Lock()
-	defer m.mut.Unlock()
 	k8sObject, err := object.AsK8sObject()
 	if err != nil {
 		return false, fmt.Errorf("creating k8s object: %w", err)
 	}
+	return m.AddObject(k8sObject)
+}
+
+// AddObject adds the object to the manifest or replaces the existing one
+// with the same kind and name. True is returned if the manifest changed.
+func (m *Manifest) AddObject(k8sObject kubernetes.Object) (bool, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
 	k8sObject.Metadata.Namespace = "" // local manifest should not set namespace
+	found := false
 	for i, o := range m.Objects {
 		if matchObjects(k8sObject, o) {
 			if reflect.DeepEqual(k8sObject, o) {
 				return false, nil
 			}
 			m.Objects[i] = k8sObject
-			return true, m.Write()
+			found = true
+			break
 		}
 	}
-	m.Objects = append(m.Objects, k8sObject)
-	return true, m.Write()
+	if !found {
+		m.Objects = append(m.Objects, k8sObject)
+	}
+	original, write := m.restore(k8sObject)
+	if !write {
+		// overlay objects are never written
+		return true, nil
+	}
+	return true, m.update(func(objects []kubernetes.Object) []kubernetes.Object {
+		for i, o := range objects {
+			if matchObjects(original, o) {
+				objects[i] = original
+				return objects
+			}
+		}
+		return append(objects, original)
+	})
 }
 
 func (m *Manifest) Remove(name, kind string) error {
 	m.mut.Lock()
 	defer m.mut.Unlock()
-	objects := make([]kubernetes.Object, 0, len(m.Objects))
+	m.Objects = removeObject(m.Objects, name, kind)
+	return m.update(func(objects []kubernetes.Object) []kubernetes.Object {
+		return removeObject(objects, name, kind)
+	})
+}
+
+// SetAnnotation sets the annotation on the manifest object with the given
+// kind and name and writes the manifest, the rest of the object is unchanged.
+func (m *Manifest) SetAnnotation(kind, name, key, value string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if !setAnnotation(m.Objects, kind, name, key, value) {
+		return fmt.Errorf("component %q not found", name)
+	}
+	return m.update(func(objects []kubernetes.Object) []kubernetes.Object {
+		setAnnotation(objects, kind, name, key, value)
+		return objects
+	})
+}
+
+// Select returns the objects with the given names that match the selector.
+// Empty names list selects all objects. Selector keys are compared with
+// the object kind, labels and annotations.
+func (m *Manifest) Select(names []string, selector map[string]string) ([]kubernetes.Object, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var result []kubernetes.Object
+	for _, name := range names {
+		found := false
+		for _, o := range m.Objects {
+			if o.Metadata.Name == name {
+				found = true
+				if matchSelector(o, selector) {
+					result = append(result, o)
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("component %q not found", name)
+		}
+	}
+	if len(names) != 0 {
+		return result, nil
+	}
 	for _, o := range m.Objects {
-		if o.Metadata.Name == name && o.Kind == kind {
+		if matchSelector(o, selector) {
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+// SecretUsers returns the objects that reference the secret in their spec.
+func (m *Manifest) SecretUsers(secret string) []kubernetes.Object {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	var result []kubernetes.Object
+	for _, o := range m.Objects {
+		if o.Kind == "Secret" || o.Spec == nil {
 			continue
 		}
-		objects = append(objects, o)
+		if _, uses := SecretRefs(o.Spec)[secret]; uses {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// SecretRefs returns the names of the secrets referenced in the spec
+// by the "valueFromSecret" and "secretKeyRef" attributes.
+func SecretRefs(spec map[string]interface{}) map[string]struct{} {
+	refs := make(map[string]struct{})
+	collectSecretRefs(spec, refs)
+	return refs
+}
+
+func collectSecretRefs(value interface{}, refs map[string]struct{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if key == "valueFromSecret" || key == "secretKeyRef" {
+				if ref, ok := nested.(map[string]interface{}); ok {
+					if name, ok := ref["name"].(string); ok {
+						refs[name] = struct{}{}
+					}
+				}
+				continue
+			}
+			collectSecretRefs(nested, refs)
+		}
+	case []interface{}:
+		for _, nested := range v {
+			collectSecretRefs(nested, refs)
+		}
 	}
-	m.Objects = objects
-	return m.Write()
 }
 
-func parseYAML(path string) ([]kubernetes.Object, error) {
+// ParseSelector converts comma-separated "key=value" pairs into a map.
+func ParseSelector(selector string) (map[string]string, error) {
+	result := make(map[string]string)
+	if selector == "" {
+		return result, nil
+	}
+	for _, pair := range strings.Split(selector, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("malformed selector %q, must be key=value", pair)
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
+// parseYAML reads the manifest objects and decrypts the secret values.
+func parseYAML(path string) ([]kubernetes.Object, map[string]ciphertext, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
 
@@ -127,11 +346,39 @@ func parseYAML(path string) ([]kubernetes.Object, error) {
 			break
 		}
 		if err != nil {
-			return []kubernetes.Object{}, err
+			return []kubernetes.Object{}, nil, err
 		}
 		result = append(result, *o)
 	}
-	return result, nil
+	ciphertexts, err := decryptSecrets(result)
+	return result, ciphertexts, err
+}
+
+func removeObject(objects []kubernetes.Object, name, kind string) []kubernetes.Object {
+	result := make([]kubernetes.Object, 0, len(objects))
+	for _, o := range objects {
+		if o.Metadata.Name == name && o.Kind == kind {
+			continue
+		}
+		result = append(result, o)
+	}
+	return result
+}
+
+func setAnnotation(objects []kubernetes.Object, kind, name, key, value string) bool {
+	for i, o := range objects {
+		if o.Kind != kind || o.Metadata.Name != name {
+			continue
+		}
+		annotations := make(map[string]string, len(o.Metadata.Annotations)+1)
+		for k, v := range o.Metadata.Annotations {
+			annotations[k] = v
+		}
+		annotations[key] = value
+		objects[i].Metadata.Annotations = annotations
+		return true
+	}
+	return false
 }
 
 func matchObjects(a, b kubernetes.Object) bool {
@@ -139,3 +386,16 @@ func matchObjects(a, b kubernetes.Object) bool {
 		(a.Kind == b.Kind) &&
 		(a.Metadata.Name == b.Metadata.Name)
 }
+
+func matchSelector(o kubernetes.Object, selector map[string]string) bool {
+	for key, value := range selector {
+		switch {
+		case key == "kind" && strings.EqualFold(o.Kind, value):
+		case o.Metadata.Labels[key] == value && value != "":
+		case o.Metadata.Annotations[key] == value && value != "":
+		default:
+			return false
+		}
+	}
+	return true
+}
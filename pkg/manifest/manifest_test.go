@@ -17,10 +17,14 @@ limitations under the License.
 package manifest
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/secrets"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/components/service"
 	"github.com/triggermesh/tmctl/test"
 )
@@ -29,9 +33,10 @@ func TestRead(t *testing.T) {
 	m := New("wrong/path")
 	assert.Error(t, m.Read())
 
-	m = New(test.Manifest())
+	path := test.Manifest(t)
+	m = New(path)
 	assert.NoError(t, m.Read())
-	assert.Lenf(t, m.Objects, 7, "Test manifest %q len is incorrect", test.Manifest())
+	assert.Lenf(t, m.Objects, 7, "Test manifest %q len is incorrect", path)
 
 	existingComponent := service.New("sockeye", "docker.io/n3wscott/sockeye:v0.7.0", "foo", service.Consumer, nil)
 	updatedComponent := service.New("sockeye", "docker.io/n3wscott/sockeye:v0.7.0", "foo", service.Consumer, map[string]string{
@@ -57,5 +62,220 @@ func TestRead(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, changed, "Restoring original component didn't update the manifest")
 
-	assert.Lenf(t, m.Objects, 7, "Test manifest %q objects len differs after test", test.Manifest())
+	assert.Lenf(t, m.Objects, 7, "Test manifest %q objects len differs after test", path)
+}
+
+func TestSelect(t *testing.T) {
+	m := New(test.Manifest(t))
+	assert.NoError(t, m.Read())
+
+	objects, err := m.Select([]string{"sockeye", "foo-transformation"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	_, err = m.Select([]string{"does-not-exist"}, nil)
+	assert.Error(t, err)
+
+	objects, err = m.Select(nil, map[string]string{"triggermesh.io/role": "target"})
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "sockeye", objects[0].Metadata.Name)
+
+	objects, err = m.Select(nil, map[string]string{"kind": "transformation"})
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	objects, err = m.Select([]string{"sockeye"}, map[string]string{"kind": "transformation"})
+	assert.NoError(t, err)
+	assert.Len(t, objects, 0)
+}
+
+func TestSecretUsers(t *testing.T) {
+	m := New(test.Manifest(t))
+	assert.NoError(t, m.Read())
+
+	users := m.SecretUsers("foo-awss3source-secret")
+	assert.Len(t, users, 1)
+	assert.Equal(t, "foo-awss3source", users[0].Metadata.Name)
+	assert.Empty(t, m.SecretUsers("does-not-exist"))
+
+	// attributes named "name" are not the secret references
+	assert.Equal(t, map[string]struct{}{"foo-secret": {}}, SecretRefs(map[string]interface{}{
+		"name": "bar-secret",
+		"env": []interface{}{map[string]interface{}{
+			"name":      "TOKEN",
+			"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "foo-secret", "key": "token"}},
+		}},
+		"bucket": map[string]interface{}{"name": "baz-secret"},
+	}))
+}
+
+func TestParseSelector(t *testing.T) {
+	selector, err := ParseSelector("kind=awss3source,triggermesh.io/role=target")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"kind":                "awss3source",
+		"triggermesh.io/role": "target",
+	}, selector)
+
+	_, err = ParseSelector("kind")
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`---
+apiVersion: targets.triggermesh.io/v1alpha1
+kind: HTTPTarget
+metadata:
+  name: foo-httptarget
+spec:
+  endpoint: https://${HOST}/api
+  method: ${METHOD:-GET}
+  body: $${HOST}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: foo-httptarget-secret
+data:
+  token: ${TOKEN}
+`), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.dev.yaml"), []byte(`---
+kind: HTTPTarget
+metadata:
+  name: foo-httptarget
+spec:
+  method: POST
+`), 0600))
+	t.Setenv("HOST", "example.com")
+
+	m := New(path)
+	assert.NoError(t, m.Read())
+	assert.Error(t, m.Resolve(""), "TOKEN is not set")
+
+	t.Setenv("TOKEN", "secret")
+	assert.NoError(t, m.Read())
+	assert.NoError(t, m.Resolve(OverlayPath(path, "dev")))
+	assert.Equal(t, "https://example.com/api", m.Objects[0].Spec["endpoint"])
+	assert.Equal(t, "POST", m.Objects[0].Spec["method"])
+	assert.Equal(t, "${HOST}", m.Objects[0].Spec["body"])
+	assert.Equal(t, "c2VjcmV0", m.Objects[1].Data["token"])
+
+	m.Objects[0].Metadata.Annotations = map[string]string{"triggermesh.io/host-port": "8080"}
+	m.Objects[0].Spec["skipVerify"] = true
+	assert.NoError(t, m.Write())
+
+	stored := New(path)
+	assert.NoError(t, stored.Read())
+	assert.Equal(t, "https://${HOST}/api", stored.Objects[0].Spec["endpoint"])
+	assert.Equal(t, "${METHOD:-GET}", stored.Objects[0].Spec["method"])
+	assert.Equal(t, true, stored.Objects[0].Spec["skipVerify"])
+	assert.Equal(t, "8080", stored.Objects[0].Metadata.Annotations["triggermesh.io/host-port"])
+	assert.Equal(t, "${TOKEN}", stored.Objects[1].Data["token"])
+}
+
+func TestDiff(t *testing.T) {
+	from := New(test.Manifest(t))
+	assert.NoError(t, from.Read())
+	to := New(test.Manifest(t))
+	assert.NoError(t, to.Read())
+
+	changes, err := Diff(from.Objects, to.Objects)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	to.Objects[0].Metadata.Labels["foo"] = "bar"
+	to.Objects = append(to.Objects[:1], to.Objects[2:]...)
+	to.Objects = append(to.Objects, kubernetes.Object{
+		Kind:     "Secret",
+		Metadata: kubernetes.Metadata{Name: "foo-secret"},
+		Data:     map[string]string{"token": "c2VjcmV0"},
+	})
+	changes, err = Diff(from.Objects, to.Objects)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, Modified, changes[0].Change)
+	assert.Equal(t, []FieldDiff{{Path: "metadata.labels.foo", To: `"bar"`}}, changes[0].Fields)
+	assert.Equal(t, Removed, changes[1].Change)
+	assert.Equal(t, from.Objects[1].Metadata.Name, changes[1].Name)
+	assert.Equal(t, Added, changes[2].Change)
+
+	fields, err := DiffValues(to.Objects[len(to.Objects)-1], kubernetes.Object{
+		Kind:     "Secret",
+		Metadata: kubernetes.Metadata{Name: "foo-secret"},
+		Data:     map[string]string{"token": "Zm9v"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "data.token", fields[0].Path)
+}
+
+func TestEncryptSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secrets.SetKeyFile(filepath.Join(dir, secrets.KeyFile))
+	defer secrets.SetKeyFile("")
+	assert.NoError(t, secrets.Rotate())
+	assert.NoError(t, secrets.Finish())
+
+	path := filepath.Join(dir, "manifest.yaml")
+	m := New(path)
+	m.Objects = []kubernetes.Object{{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   kubernetes.Metadata{Name: "foo-secret"},
+		Data:       map[string]string{"token": "c2VjcmV0"},
+	}}
+	assert.NoError(t, m.Write())
+	encrypted, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "c2VjcmV0")
+
+	stored := New(path)
+	assert.NoError(t, stored.Read())
+	assert.Equal(t, "c2VjcmV0", stored.Objects[0].Data["token"])
+
+	// unchanged values are not re-encrypted
+	assert.NoError(t, stored.Write())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(encrypted), string(data))
+}
+
+func TestConcurrentUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	assert.NoError(t, os.WriteFile(path, nil, 0600))
+
+	first, second := New(path), New(path)
+	assert.NoError(t, first.Read())
+	assert.NoError(t, second.Read())
+
+	_, err := first.Add(service.New("first", "triggermesh/image", "foo", service.Consumer, nil))
+	assert.NoError(t, err)
+	// second manifest is stale, the first change must be kept
+	_, err = second.Add(service.New("second", "triggermesh/image", "foo", service.Consumer, nil))
+	assert.NoError(t, err)
+	assert.NoError(t, second.SetAnnotation("Service", "second", "key", "value"))
+
+	stored := New(path)
+	assert.NoError(t, stored.Read())
+	assert.Len(t, stored.Objects, 2)
+	assert.Equal(t, "value", stored.Objects[1].Metadata.Annotations["key"])
+
+	assert.NoError(t, first.Remove("second", "Service"))
+	assert.NoError(t, stored.Read())
+	assert.Len(t, stored.Objects, 1)
+	assert.Equal(t, "first", stored.Objects[0].Metadata.Name)
+
+	// stale object is put back without dropping the other objects
+	_, err = second.Add(service.New("third", "triggermesh/image", "foo", service.Consumer, nil))
+	assert.NoError(t, err)
+	changed, err := first.AddObject(stored.Objects[0])
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	_, err = second.AddObject(stored.Objects[0])
+	assert.NoError(t, err)
+	assert.NoError(t, stored.Read())
+	assert.Len(t, stored.Objects, 2)
 }
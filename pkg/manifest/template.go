@@ -0,0 +1,321 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+)
+
+// variable matches the escaped "$${" sequence, ${VAR} and ${VAR:-default} references.
+var variable = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// resolvedObject is the manifest object before and after the resolution.
+type resolvedObject struct {
+	// original is nil for the objects that exist in the overlay only.
+	original *kubernetes.Object
+	resolved kubernetes.Object
+}
+
+// OverlayPath returns the overlay manifest path. The overlay is either
+// the file path or the name, e.g. "dev" for the "manifest.dev.yaml" file
+// next to the manifest.
+func OverlayPath(manifestPath, overlay string) string {
+	if overlay == "" {
+		return ""
+	}
+	if ext := filepath.Ext(overlay); ext == ".yaml" || ext == ".yml" {
+		return overlay
+	}
+	ext := filepath.Ext(manifestPath)
+	return strings.TrimSuffix(manifestPath, ext) + "." + overlay + ext
+}
+
+// Resolve merges the overlay manifest, if set, over the manifest objects and
+// replaces the ${VAR} and ${VAR:-default} references with the environment
+// variables in the specs, secrets data and annotations. Secret values with
+// references are base64-encoded after the substitution. Write keeps
+// the references and the values that were not overridden by the overlay.
+func (m *Manifest) Resolve(overlayPath string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	originals := make([]kubernetes.Object, len(m.Objects))
+	for i, object := range m.Objects {
+		originals[i] = copyObject(object)
+	}
+	objects := make([]kubernetes.Object, len(m.Objects))
+	copy(objects, m.Objects)
+	if overlayPath != "" {
+		overlay, _, err := parseYAML(overlayPath)
+		if err != nil {
+			return fmt.Errorf("overlay %q: %w", overlayPath, err)
+		}
+		objects = mergeOverlay(objects, overlay)
+	}
+
+	var undefined []string
+	for i := range objects {
+		objects[i] = expandObject(copyObject(objects[i]), &undefined)
+	}
+	if len(undefined) != 0 {
+		sort.Strings(undefined)
+		return fmt.Errorf("environment variables are not set: %s", strings.Join(unique(undefined), ", "))
+	}
+
+	m.resolved = make(map[string]resolvedObject, len(objects))
+	for i, object := range objects {
+		r := resolvedObject{resolved: copyObject(object)}
+		if i < len(originals) {
+			r.original = &originals[i]
+		}
+		m.resolved[objectKey(object)] = r
+	}
+	m.Objects = objects
+	return nil
+}
+
+// restore returns the object to be written to the manifest file: the values
+// that did not change since the resolution are replaced with the original
+// ones. Objects from the overlay are not written.
+func (m *Manifest) restore(object kubernetes.Object) (kubernetes.Object, bool) {
+	r, exists := m.resolved[objectKey(object)]
+	if !exists {
+		return object, true
+	}
+	if r.original == nil {
+		return object, false
+	}
+	object = copyObject(object)
+	object.Spec = restoreMap(object.Spec, r.resolved.Spec, r.original.Spec)
+	object.Data = toStringMap(restoreMap(toInterfaceMap(object.Data), toInterfaceMap(r.resolved.Data), toInterfaceMap(r.original.Data)))
+	object.Metadata.Annotations = toStringMap(restoreMap(
+		toInterfaceMap(object.Metadata.Annotations),
+		toInterfaceMap(r.resolved.Metadata.Annotations),
+		toInterfaceMap(r.original.Metadata.Annotations)))
+	object.Metadata.Labels = toStringMap(restoreMap(
+		toInterfaceMap(object.Metadata.Labels),
+		toInterfaceMap(r.resolved.Metadata.Labels),
+		toInterfaceMap(r.original.Metadata.Labels)))
+	return object, true
+}
+
+// Original returns the object as it is written to the manifest file,
+// with the resolved references replaced back by the original ones.
+func (m *Manifest) Original(object kubernetes.Object) kubernetes.Object {
+	object, _ = m.restore(object)
+	return object
+}
+
+func restoreMap(current, resolved, original map[string]interface{}) map[string]interface{} {
+	if current == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(current))
+	for k, value := range current {
+		resolvedValue, wasResolved := resolved[k]
+		originalValue, wasOriginal := original[k]
+		if wasResolved && reflect.DeepEqual(value, resolvedValue) {
+			if wasOriginal {
+				result[k] = originalValue
+			}
+			continue
+		}
+		currentMap, ok1 := value.(map[string]interface{})
+		resolvedMap, ok2 := resolvedValue.(map[string]interface{})
+		originalMap, ok3 := originalValue.(map[string]interface{})
+		if ok1 && ok2 && ok3 {
+			result[k] = restoreMap(currentMap, resolvedMap, originalMap)
+			continue
+		}
+		result[k] = value
+	}
+	return result
+}
+
+func mergeOverlay(objects, overlay []kubernetes.Object) []kubernetes.Object {
+	for _, o := range overlay {
+		merged := false
+		for i, object := range objects {
+			if object.Kind != o.Kind || object.Metadata.Name != o.Metadata.Name {
+				continue
+			}
+			object = copyObject(object)
+			object.Spec = mergeMap(object.Spec, o.Spec)
+			object.Data = toStringMap(mergeMap(toInterfaceMap(object.Data), toInterfaceMap(o.Data)))
+			object.Metadata.Annotations = toStringMap(mergeMap(toInterfaceMap(object.Metadata.Annotations), toInterfaceMap(o.Metadata.Annotations)))
+			object.Metadata.Labels = toStringMap(mergeMap(toInterfaceMap(object.Metadata.Labels), toInterfaceMap(o.Metadata.Labels)))
+			objects[i] = object
+			merged = true
+			break
+		}
+		if !merged {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+func mergeMap(base, overlay map[string]interface{}) map[string]interface{} {
+	if base == nil && overlay == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range overlay {
+		baseMap, ok1 := result[k].(map[string]interface{})
+		overlayMap, ok2 := v.(map[string]interface{})
+		if ok1 && ok2 {
+			result[k] = mergeMap(baseMap, overlayMap)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func expandObject(object kubernetes.Object, undefined *[]string) kubernetes.Object {
+	if object.Spec != nil {
+		object.Spec = expandValue(object.Spec, undefined).(map[string]interface{})
+	}
+	for k, v := range object.Data {
+		if expanded := expand(v, undefined); expanded != v {
+			object.Data[k] = base64.StdEncoding.EncodeToString([]byte(expanded))
+		}
+	}
+	for k, v := range object.Metadata.Annotations {
+		object.Metadata.Annotations[k] = expand(v, undefined)
+	}
+	return object
+}
+
+func expandValue(value interface{}, undefined *[]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return expand(v, undefined)
+	case map[string]interface{}:
+		for k, nested := range v {
+			v[k] = expandValue(nested, undefined)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = expandValue(nested, undefined)
+		}
+	}
+	return value
+}
+
+func expand(value string, undefined *[]string) string {
+	return variable.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		groups := variable.FindStringSubmatch(match)
+		if env, set := os.LookupEnv(groups[1]); set {
+			return env
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		*undefined = append(*undefined, groups[1])
+		return match
+	})
+}
+
+func objectKey(object kubernetes.Object) string {
+	return object.APIVersion + "/" + object.Kind + "/" + object.Metadata.Name
+}
+
+func copyObject(object kubernetes.Object) kubernetes.Object {
+	if object.Spec != nil {
+		object.Spec = copyValue(object.Spec).(map[string]interface{})
+	}
+	object.Data = copyStringMap(object.Data)
+	object.Metadata.Annotations = copyStringMap(object.Metadata.Annotations)
+	object.Metadata.Labels = copyStringMap(object.Metadata.Labels)
+	return object
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			result[k] = copyValue(nested)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, nested := range v {
+			result[i] = copyValue(nested)
+		}
+		return result
+	}
+	return value
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func toStringMap(m map[string]interface{}) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = fmt.Sprintf("%v", v)
+	}
+	return result
+}
+
+func unique(values []string) []string {
+	var result []string
+	for i, v := range values {
+		if i == 0 || values[i-1] != v {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -76,5 +76,14 @@ func PrintStatus(kind string, object triggermesh.Component, eventSourcesFilter,
 	fmt.Print(result)
 }
 
+// PrintPlan prints the external resources that the component
+// initialization would create or modify.
+func PrintPlan(object triggermesh.Component, changes []string) {
+	fmt.Printf("%s (%s):\n", object.GetName(), object.GetKind())
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+}
+
 // func Draw() {}
 // func Dump() {}
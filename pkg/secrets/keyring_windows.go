@@ -0,0 +1,83 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const credTypeGeneric = 1
+
+var (
+	advapi32     = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead = advapi32.NewProc("CredReadW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+// credential is the CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// keyring returns the generic credential "<service>:<user>" from
+// the Windows Credential Manager.
+func keyring(service, user string) (string, error) {
+	target, err := windows.UTF16PtrFromString(service + ":" + user)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ret, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		return "", fmt.Errorf("credential manager: %w", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return decodeBlob(blob), nil
+}
+
+// decodeBlob returns the credential value. Credentials stored with
+// cmdkey or the Control Panel are UTF-16 encoded, such ASCII values
+// are detected by the zero high bytes.
+func decodeBlob(blob []byte) string {
+	if len(blob) == 0 || len(blob)%2 != 0 {
+		return string(blob)
+	}
+	chars := make([]uint16, 0, len(blob)/2)
+	for i := 0; i < len(blob); i += 2 {
+		if blob[i+1] != 0 {
+			return string(blob)
+		}
+		chars = append(chars, uint16(blob[i]))
+	}
+	return string(utf16.Decode(chars))
+}
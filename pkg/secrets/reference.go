@@ -0,0 +1,85 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prefixes of the secret values that reference the external sources.
+// References are stored in the manifest as is and resolved when
+// the secret value is used.
+const (
+	EnvReference     = "env:"
+	FileReference    = "file:"
+	KeyringReference = "keyring:"
+)
+
+// IsReference returns true if the secret value references
+// the external source. Base64-encoded values never match.
+func IsReference(value string) bool {
+	for _, prefix := range []string{EnvReference, FileReference, KeyringReference} {
+		if strings.HasPrefix(value, prefix) && len(value) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the plain value of the reference:
+// env:NAME is the environment variable, file:PATH is the file content
+// without the trailing newline and keyring:SERVICE/USER is the password
+// from the OS keyring.
+func Resolve(reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, EnvReference):
+		name := strings.TrimPrefix(reference, EnvReference)
+		value, set := os.LookupEnv(name)
+		if !set {
+			return "", fmt.Errorf("%q: environment variable is not set", reference)
+		}
+		return value, nil
+	case strings.HasPrefix(reference, FileReference):
+		path := strings.TrimPrefix(reference, FileReference)
+		if strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("%q: %w", reference, err)
+			}
+			path = filepath.Join(home, strings.TrimPrefix(path, "~/"))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%q: %w", reference, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(reference, KeyringReference):
+		service, user, found := strings.Cut(strings.TrimPrefix(reference, KeyringReference), "/")
+		if !found || service == "" || user == "" {
+			return "", fmt.Errorf("%q: keyring reference must be keyring:<service>/<user>", reference)
+		}
+		value, err := keyring(service, user)
+		if err != nil {
+			return "", fmt.Errorf("%q: %w", reference, err)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("%q is not a secret reference", reference)
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secrets encrypts the manifest secret values at rest with age
+// (https://age-encryption.org) using the X25519 identity. Every value
+// is a regular binary age file stored as "age:<base64>", so it can be
+// decrypted with the age tool as well:
+//
+//	echo <base64> | base64 -d | age -d -i ~/.triggermesh/cli/secrets.key
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/triggermesh/tmctl/pkg/file"
+)
+
+const (
+	// KeyEnv is the environment variable with the age identity,
+	// it takes precedence over the key file.
+	KeyEnv = "TMCTL_SECRETS_KEY"
+	// KeyFile is the name of the key file in the CLI home directory.
+	KeyFile = "secrets.key"
+
+	// previousKeySuffix marks the key replaced by the unfinished rotation.
+	previousKeySuffix = ".old"
+
+	valuePrefix = "age:"
+)
+
+// ErrNoKey is returned when the encrypted value cannot be decrypted
+// with any of the available keys.
+var ErrNoKey = errors.New("secret value is encrypted, set " + KeyEnv + " or create the key with \"tmctl secrets rotate-key\"")
+
+// Encrypted matches the encrypted values in the text: the base64-encoded
+// age files that start with the "age-encryption.org/v1" header.
+var Encrypted = regexp.MustCompile(valuePrefix + base64.StdEncoding.EncodeToString([]byte("age-encryption.org/v1")) + `[A-Za-z0-9+/]*={0,2}`)
+
+var encryptedValue = regexp.MustCompile("^(?:" + Encrypted.String() + ")$")
+
+var keyFile string
+
+// SetKeyFile sets the path of the key file.
+func SetKeyFile(path string) {
+	keyFile = path
+}
+
+// KeyFilePath returns the path of the key file.
+func KeyFilePath() string {
+	return keyFile
+}
+
+// Key returns the identity used to encrypt the values, nil if encryption
+// is not enabled.
+func Key() (*age.X25519Identity, error) {
+	if env, set := os.LookupEnv(KeyEnv); set {
+		return parseKey([]byte(env))
+	}
+	return readKey(keyFile)
+}
+
+// Keys returns all identities that can decrypt the values: the current
+// one and the one replaced by the unfinished rotation.
+func Keys() ([]*age.X25519Identity, error) {
+	var keys []*age.X25519Identity
+	for _, read := range []func() (*age.X25519Identity, error){
+		Key,
+		func() (*age.X25519Identity, error) { return readKey(keyFile) },
+		func() (*age.X25519Identity, error) { return readKey(previousKeyFile()) },
+	} {
+		key, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if key != nil && !contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// IsEncrypted returns true if the value is encrypted.
+func IsEncrypted(value string) bool {
+	return encryptedValue.MatchString(value)
+}
+
+// Encrypt encrypts the value to the identity recipient.
+func Encrypt(key *age.X25519Identity, value string) (string, error) {
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, key.Recipient())
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, value); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return valuePrefix + base64.StdEncoding.EncodeToString(out.Bytes()), nil
+}
+
+// Decrypt decrypts the value with the first identity that fits. The index
+// of the identity is returned along with the plain value.
+func Decrypt(keys []*age.X25519Identity, encrypted string) (string, int, error) {
+	if !encryptedValue.MatchString(encrypted) {
+		return "", 0, fmt.Errorf("value is not encrypted")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, valuePrefix))
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	for i, key := range keys {
+		r, err := age.Decrypt(bytes.NewReader(data), key)
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			continue
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("malformed encrypted value: %w", err)
+		}
+		plain, err := io.ReadAll(r)
+		if err != nil {
+			return "", 0, fmt.Errorf("malformed encrypted value: %w", err)
+		}
+		return string(plain), i, nil
+	}
+	return "", 0, ErrNoKey
+}
+
+// Reencrypt replaces the encrypted values in the text with the ones
+// encrypted by the current key.
+func Reencrypt(text []byte) ([]byte, error) {
+	keys, err := Keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return text, nil
+	}
+	var result error
+	out := Encrypted.ReplaceAllFunc(text, func(value []byte) []byte {
+		plain, _, err := Decrypt(keys, string(value))
+		if err == nil {
+			var encrypted string
+			if encrypted, err = Encrypt(keys[0], plain); err == nil {
+				return []byte(encrypted)
+			}
+		}
+		if result == nil {
+			result = err
+		}
+		return value
+	})
+	return out, result
+}
+
+// Rotate generates the new key and keeps the current one until
+// Finish is called. Unfinished rotation is resumed without
+// generating the new key.
+func Rotate() error {
+	if _, set := os.LookupEnv(KeyEnv); set {
+		return fmt.Errorf("the key is set by %s, unset it to rotate the key file", KeyEnv)
+	}
+	if keyFile == "" {
+		return fmt.Errorf("key file is not set")
+	}
+	if _, err := os.Stat(previousKeyFile()); err == nil {
+		return nil
+	}
+	current, err := os.ReadFile(keyFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read key: %w", err)
+	}
+	if err == nil {
+		if err := file.WriteAtomic(previousKeyFile(), current, file.PrivatePerm); err != nil {
+			return fmt.Errorf("write key: %w", err)
+		}
+	}
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+	encoded := fmt.Sprintf("# public key: %s\n%s\n", key.Recipient(), key)
+	if err := file.WriteAtomic(keyFile, []byte(encoded), file.PrivatePerm); err != nil {
+		return fmt.Errorf("write key: %w", err)
+	}
+	return nil
+}
+
+// Finish removes the key replaced by the rotation.
+func Finish() error {
+	if err := os.Remove(previousKeyFile()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func previousKeyFile() string {
+	return keyFile + previousKeySuffix
+}
+
+func readKey(path string) (*age.X25519Identity, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	key, err := parseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("key file %q: %w", path, err)
+	}
+	return key, nil
+}
+
+// parseKey parses the identity in the age-keygen format,
+// comments and empty lines are skipped.
+func parseKey(data []byte) (*age.X25519Identity, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("malformed key: expected one age identity, got %d", len(lines))
+	}
+	key, err := age.ParseX25519Identity(lines[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed key: %w", err)
+	}
+	return key, nil
+}
+
+func contains(keys []*age.X25519Identity, key *age.X25519Identity) bool {
+	for _, k := range keys {
+		if k.String() == key.String() {
+			return true
+		}
+	}
+	return false
+}
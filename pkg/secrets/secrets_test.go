@@ -0,0 +1,101 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	SetKeyFile(filepath.Join(t.TempDir(), KeyFile))
+	defer SetKeyFile("")
+	assert.NoError(t, Rotate())
+	key, err := Key()
+	assert.NoError(t, err)
+	assert.NotNil(t, key)
+
+	encrypted, err := Encrypt(key, "c2VjcmV0")
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.False(t, IsEncrypted("c2VjcmV0"))
+
+	assert.True(t, strings.HasPrefix(encrypted, "age:YWdlLWVuY3J5cHRpb24ub3JnL3Yx"), "Value is the base64-encoded age file")
+	assert.False(t, IsEncrypted("age:c2VjcmV0"))
+
+	plain, index, err := Decrypt([]*age.X25519Identity{key}, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "c2VjcmV0", plain)
+	assert.Equal(t, 0, index)
+
+	// values encrypted with the previous key are readable during the rotation
+	assert.NoError(t, Rotate())
+	keys, err := Keys()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	reencrypted, err := Reencrypt([]byte("token: " + encrypted + "\n"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(reencrypted), encrypted)
+
+	assert.NoError(t, Finish())
+	_, _, err = Decrypt(keys[:1], encrypted)
+	assert.ErrorIs(t, err, ErrNoKey)
+	plain, _, err = Decrypt(keys[:1], string(reencrypted[len("token: "):len(reencrypted)-1]))
+	assert.NoError(t, err)
+	assert.Equal(t, "c2VjcmV0", plain)
+
+	// age-keygen output is accepted
+	identity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	t.Setenv(KeyEnv, "# created: 2023-01-01T00:00:00Z\n# public key: "+identity.Recipient().String()+"\n"+identity.String()+"\n")
+	key, err = Key()
+	assert.NoError(t, err)
+	assert.Equal(t, identity.String(), key.String())
+
+	t.Setenv(KeyEnv, "malformed")
+	_, err = Key()
+	assert.Error(t, err)
+	assert.Error(t, Rotate())
+}
+
+func TestResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0600))
+	t.Setenv("TMCTL_TEST_TOKEN", "from-env")
+
+	assert.True(t, IsReference("env:TMCTL_TEST_TOKEN"))
+	assert.False(t, IsReference("env:"))
+	assert.False(t, IsReference("c2VjcmV0"))
+
+	value, err := Resolve("env:TMCTL_TEST_TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+
+	value, err = Resolve("file:" + path)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", value)
+
+	_, err = Resolve("env:TMCTL_TEST_UNSET")
+	assert.Error(t, err)
+	_, err = Resolve("keyring:service")
+	assert.Error(t, err)
+}
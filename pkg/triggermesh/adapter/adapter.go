@@ -19,23 +19,33 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	"github.com/triggermesh/tmctl/pkg/config"
 	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/ce"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/env"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
 const (
-	registry    = "gcr.io/triggermesh"
+	registry    = config.TriggerMeshRegistry
 	adapterPort = "8080/tcp"
 )
 
+// Image returns the adapter image of the component,
+// the TriggerMesh registry is replaced with the configured mirror.
 func Image(object unstructured.Unstructured, version string) string {
+	return docker.MirrorImage(image(object, version))
+}
+
+func image(object unstructured.Unstructured, version string) string {
 	// components with custom images
 	switch object.GetKind() {
 	case "AWSS3Source",
@@ -44,7 +54,8 @@ func Image(object unstructured.Unstructured, version string) string {
 	case "AzureServiceBusTopicSource",
 		"AzureServiceBusQueueSource":
 		return fmt.Sprintf("%s/azureservicebussource-adapter:%s", registry, version)
-	case "AzureBlobStorageSource":
+	case "AzureActivityLogsSource",
+		"AzureBlobStorageSource":
 		return fmt.Sprintf("%s/azureeventhubssource-adapter:%s", registry, version)
 	case "GoogleCloudAuditLogsSource",
 		"GoogleCloudStorageSource",
@@ -60,11 +71,37 @@ func RuntimeParams(object unstructured.Unstructured, image string, additionalEnv
 		docker.WithPort(adapterPort),
 		// docker.WithErrorLoggingLevel(),
 	}
+	hostPort, err := HostPort(object.GetAnnotations())
+	if err != nil {
+		return nil, nil, err
+	}
 	ho := []docker.HostOption{
-		docker.WithHostPortBinding(adapterPort),
+		docker.WithHostPortBinding(adapterPort, hostPort),
 		docker.WithExtraHost(),
 	}
 
+	if policy, set := object.GetAnnotations()[triggermesh.RestartPolicyAnnotation]; set {
+		restartPolicy, err := docker.ParseRestartPolicy(policy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("restart policy: %w", err)
+		}
+		ho = append(ho, docker.WithRestartPolicy(restartPolicy))
+	}
+
+	overrides, err := ParseOverrides(object.GetAnnotations())
+	if err != nil {
+		return nil, nil, err
+	}
+	ho = append(ho, overrides.HostOptions()...)
+
+	var credentials Overrides
+	if NativeCredentials(object.GetAnnotations()) {
+		if credentials, err = CredentialsOverrides(object); err != nil {
+			return nil, nil, fmt.Errorf("native credentials: %w", err)
+		}
+		ho = append(ho, credentials.HostOptions()...)
+	}
+
 	finalEnv := []corev1.EnvVar{}
 
 	if object.GetKind() != "RedisBroker" &&
@@ -95,10 +132,42 @@ func RuntimeParams(object unstructured.Unstructured, image string, additionalEnv
 	if set {
 		finalEnv = append(finalEnv, corev1.EnvVar{Name: "K_SINK", Value: sinkURI})
 	}
+	finalEnv = credentials.MergeEnv(finalEnv)
+	endpoints, err := EndpointsEnv(object.GetAnnotations())
+	if err != nil {
+		return nil, nil, fmt.Errorf("endpoints annotation: %w", err)
+	}
+	for _, env := range endpoints {
+		finalEnv = mergeEnv(finalEnv, env)
+	}
+	finalEnv = overrides.MergeEnv(finalEnv)
 	co = append(co, docker.WithEnv(envsToString(finalEnv)))
 	return co, ho, nil
 }
 
+// HostPort returns the host port persisted in the annotations or
+// an empty string if the port is not assigned yet.
+func HostPort(annotations map[string]string) (string, error) {
+	port, set := annotations[triggermesh.HostPortAnnotation]
+	if !set {
+		return "", nil
+	}
+	if _, err := pkg.ParsePort(port); err != nil {
+		return "", fmt.Errorf("host port annotation: %w", err)
+	}
+	return port, nil
+}
+
+// ComposePorts returns the docker-compose port mapping of the adapter,
+// persisted host port is preferred over the random one.
+func ComposePorts(annotations map[string]string) []string {
+	hostPort, _ := HostPort(annotations)
+	if hostPort == "" {
+		hostPort = strconv.Itoa(pkg.OpenPort())
+	}
+	return []string{hostPort + ":8080"}
+}
+
 func envsToString(envs []corev1.EnvVar) []string {
 	var result []string
 	for _, env := range envs {
@@ -115,6 +184,14 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 	return reconciler.Finalize(ctx, object, secrets)
 }
 
+func Plan(object unstructured.Unstructured) ([]string, error) {
+	return reconciler.Plan(object)
+}
+
+func ExternalResourceExists(ctx context.Context, object unstructured.Unstructured, secrets map[string]string, key, value string) (bool, error) {
+	return reconciler.Exists(ctx, object, secrets, key, value)
+}
+
 func EventAttributes(object unstructured.Unstructured) (ce.EventAttributes, error) {
 	attributes, err := ce.Attributes(object)
 	if err != nil {
@@ -17,11 +17,18 @@ limitations under the License.
 package adapter
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/kubernetes"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
 )
 
 // var testObjects = map[string]struct {
@@ -103,6 +110,235 @@ func TestRuntimeParams(t *testing.T) {
 	}
 }
 
+func TestRuntimeParamsRestartPolicy(t *testing.T) {
+	object := newUnstructured(t, "test-target", "CloudEventsTarget", "targets.triggermesh.io/v1alpha1", map[string]interface{}{})
+	object.SetAnnotations(map[string]string{triggermesh.RestartPolicyAnnotation: "on-failure:3"})
+	_, ho, err := RuntimeParams(object, "registry/image", nil)
+	assert.NoError(t, err)
+
+	hc := &container.HostConfig{}
+	for _, opt := range ho {
+		opt(hc)
+	}
+	assert.Equal(t, container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}, hc.RestartPolicy)
+
+	object.SetAnnotations(map[string]string{triggermesh.RestartPolicyAnnotation: "sometimes"})
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+}
+
+func TestRuntimeParamsHostPort(t *testing.T) {
+	object := newUnstructured(t, "test-target", "CloudEventsTarget", "targets.triggermesh.io/v1alpha1", map[string]interface{}{})
+	object.SetAnnotations(map[string]string{triggermesh.HostPortAnnotation: "18080"})
+	_, ho, err := RuntimeParams(object, "registry/image", nil)
+	assert.NoError(t, err)
+
+	hc := &container.HostConfig{}
+	for _, opt := range ho {
+		opt(hc)
+	}
+	assert.Equal(t, "18080", hc.PortBindings["8080/tcp"][0].HostPort)
+	assert.Equal(t, []string{"18080:8080"}, ComposePorts(object.GetAnnotations()))
+
+	object.SetAnnotations(map[string]string{triggermesh.HostPortAnnotation: "80800"})
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+}
+
+func TestRuntimeParamsOverrides(t *testing.T) {
+	object := newUnstructured(t, "test-target", "CloudEventsTarget", "targets.triggermesh.io/v1alpha1", map[string]interface{}{})
+	object.SetAnnotations(map[string]string{
+		triggermesh.ResourcesAnnotation: "cpu=500m,memory=512Mi",
+		triggermesh.EnvAnnotation:       "NO_PROXY=localhost,127.0.0.1\nadditional-env=override",
+		triggermesh.MountsAnnotation:    "/tmp/data:/data:ro\n/tmp/cache:/cache",
+	})
+	co, ho, err := RuntimeParams(object, "registry/image", map[string]string{
+		"additional-env": "value",
+	})
+	assert.NoError(t, err)
+
+	cc := &container.Config{}
+	hc := &container.HostConfig{}
+	for _, opt := range co {
+		opt(cc)
+	}
+	for _, opt := range ho {
+		opt(hc)
+	}
+	assert.Contains(t, cc.Env, "NO_PROXY=localhost,127.0.0.1")
+	assert.Contains(t, cc.Env, "additional-env=override")
+	assert.NotContains(t, cc.Env, "additional-env=value")
+	assert.Equal(t, int64(5e8), hc.NanoCPUs)
+	assert.Equal(t, int64(512*1024*1024), hc.Memory)
+	assert.Equal(t, []string{"/tmp/data:/data:ro", "/tmp/cache:/cache"}, hc.Binds)
+
+	object.SetAnnotations(map[string]string{triggermesh.ResourcesAnnotation: "gpu=1"})
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+
+	object.SetAnnotations(map[string]string{triggermesh.MountsAnnotation: "/tmp/data:data"})
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+}
+
+func TestParseMounts(t *testing.T) {
+	mounts, err := ParseMounts("C:\\data:/data:ro\n/tmp/a,b:/ab\n/tmp/c:/c:rw")
+	assert.NoError(t, err)
+	assert.Equal(t, []Mount{
+		{Host: "C:\\data", Container: "/data", ReadOnly: true},
+		{Host: "/tmp/a,b", Container: "/ab"},
+		{Host: "/tmp/c", Container: "/c"},
+	}, mounts)
+	assert.Equal(t, "C:\\data:/data:ro\n/tmp/a,b:/ab\n/tmp/c:/c", FormatMounts(mounts))
+
+	for _, bind := range []string{"/data", ":/data", "/tmp/data:/data:x", "/tmp/data:data", "C:\\data:ro"} {
+		_, err := ParseMounts(bind)
+		assert.Error(t, err, bind)
+	}
+}
+
+func TestRuntimeParamsNativeCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_PROFILE", "dev")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	assert.NoError(t, os.Mkdir(filepath.Join(home, ".aws"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "credentials"), nil, 0600))
+
+	object := newUnstructured(t, "test-source", "AWSS3Source", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
+		"arn": "arn:aws:s3:::dev",
+	})
+	object.SetAnnotations(map[string]string{triggermesh.CredentialsAnnotation: triggermesh.NativeCredentials})
+	co, ho, err := RuntimeParams(object, "registry/image", nil)
+	assert.NoError(t, err)
+
+	cc := &container.Config{}
+	hc := &container.HostConfig{}
+	for _, opt := range co {
+		opt(cc)
+	}
+	for _, opt := range ho {
+		opt(hc)
+	}
+	assert.Contains(t, cc.Env, "AWS_PROFILE=dev")
+	assert.Contains(t, cc.Env, "AWS_SHARED_CREDENTIALS_FILE=/var/run/tmctl/credentials/aws/credentials")
+	assert.NotContains(t, cc.Env, "AWS_ACCESS_KEY_ID=AKID")
+	assert.Equal(t, []string{filepath.Join(home, ".aws", "credentials") + ":/var/run/tmctl/credentials/aws/credentials:ro"}, hc.Binds)
+
+	object.SetKind("AzureEventHubsSource")
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.ErrorIs(t, err, ErrAzureNativeCredentials, "Adapter has no spec credentials")
+	azure := newUnstructured(t, "test-source", "AzureEventHubsSource", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
+		"auth": map[string]interface{}{
+			"servicePrincipal": map[string]interface{}{
+				"tenantID": map[string]interface{}{"value": "tenant"},
+			},
+		},
+	})
+	azure.SetAnnotations(object.GetAnnotations())
+	_, ho, err = RuntimeParams(azure, "registry/image", nil)
+	assert.NoError(t, err, "Adapter uses the spec credentials")
+	hc = &container.HostConfig{}
+	for _, opt := range ho {
+		opt(hc)
+	}
+	assert.Empty(t, hc.Binds)
+
+	object.SetKind("GoogleCloudPubSubSource")
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err, "application default credentials do not exist")
+
+	object.SetKind("CloudEventsTarget")
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+}
+
+func TestRuntimeParamsEndpoints(t *testing.T) {
+	object := newUnstructured(t, "test-source", "GoogleCloudStorageSource", "sources.triggermesh.io/v1alpha1", map[string]interface{}{})
+	object.SetAnnotations(map[string]string{
+		triggermesh.EndpointsAnnotation: "aws=http://localhost:4566,pubsub=127.0.0.1:8085,storage=gcs:4443",
+	})
+	co, _, err := RuntimeParams(object, "registry/image", nil)
+	assert.NoError(t, err)
+
+	cc := &container.Config{}
+	for _, opt := range co {
+		opt(cc)
+	}
+	assert.Contains(t, cc.Env, "AWS_ENDPOINT_URL=http://host.docker.internal:4566")
+	assert.Contains(t, cc.Env, "PUBSUB_EMULATOR_HOST=host.docker.internal:8085")
+	assert.Contains(t, cc.Env, "STORAGE_EMULATOR_HOST=gcs:4443")
+
+	object.SetAnnotations(map[string]string{triggermesh.EndpointsAnnotation: "aws=localstack:4566"})
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+
+	object.SetAnnotations(map[string]string{triggermesh.EndpointsAnnotation: "sqs=http://localhost:4566"})
+	_, _, err = RuntimeParams(object, "registry/image", nil)
+	assert.Error(t, err)
+}
+
+func TestImageMirror(t *testing.T) {
+	defer docker.SetConfig(&config.Docker{})
+	object := newUnstructured(t, "test-source", "AWSS3Source", "sources.triggermesh.io/v1alpha1", nil)
+
+	docker.SetConfig(&config.Docker{Mirror: "registry.example.com/mirror"})
+	assert.Equal(t, "registry.example.com/mirror/awssqssource-adapter:v1.26.0", Image(object, "v1.26.0"))
+}
+
+func TestAzureActivityLogsSource(t *testing.T) {
+	object := newUnstructured(t, "test-source", "AzureActivityLogsSource", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
+		"subscriptionID": "sub",
+		"destination": map[string]interface{}{
+			"eventHubs": map[string]interface{}{
+				"namespaceID":   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.EventHub/namespaces/ns",
+				"consumerGroup": "tmctl",
+			},
+		},
+	})
+	assert.Equal(t, "gcr.io/triggermesh/azureeventhubssource-adapter:v1.26.0", Image(object, "v1.26.0"))
+
+	co, _, err := RuntimeParams(object, "registry/image", nil)
+	assert.NoError(t, err)
+	cc := &container.Config{}
+	for _, opt := range co {
+		opt(cc)
+	}
+	assert.Contains(t, cc.Env, "EVENTHUB_RESOURCE_ID=/subscriptions/sub/resourceGroups/rg/providers/Microsoft.EventHub/namespaces/ns/eventhubs/insights-activity-logs")
+	assert.Contains(t, cc.Env, "EVENTHUB_NAMESPACE=ns")
+	assert.Contains(t, cc.Env, "EVENTHUB_NAME=insights-activity-logs")
+	assert.Contains(t, cc.Env, "EVENTHUB_CONSUMER_GROUP=tmctl")
+	assert.Contains(t, cc.Env, "CE_SOURCE=/subscriptions/sub")
+	assert.Contains(t, cc.Env, "CE_TYPE=com.microsoft.azure.monitor.activity-log")
+}
+
+func TestOverridesExport(t *testing.T) {
+	overrides, err := ParseOverrides(map[string]string{
+		triggermesh.ResourcesAnnotation: "cpu=1,memory=1Gi",
+		triggermesh.EnvAnnotation:       "FOO=bar",
+		triggermesh.MountsAnnotation:    "/tmp/data:/data",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu=1,memory=1Gi", FormatResources(overrides.Resources))
+
+	deployment := overrides.ApplyToDeployment(kubernetes.CreateDeployment("test", "registry/image", nil))
+	adapter := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "1Gi", adapter.Resources.Limits.Memory().String())
+	assert.Equal(t, "FOO", adapter.Env[0].Name)
+	assert.Equal(t, "/data", adapter.VolumeMounts[0].MountPath)
+	assert.Equal(t, "/tmp/data", deployment.Spec.Template.Spec.Volumes[0].HostPath.Path)
+
+	compose := &docker.ComposeService{Environment: []string{"FOO=baz"}}
+	overrides.ApplyToComposeService(compose)
+	assert.Equal(t, []string{"FOO=bar"}, compose.Environment)
+	assert.Equal(t, "1", compose.CPUs)
+	assert.Equal(t, int64(1024*1024*1024), compose.MemLimit)
+	assert.Equal(t, []string{"/tmp/data:/data"}, compose.Volumes)
+}
+
 func TestEventAttributes(t *testing.T) {
 	source := newUnstructured(t, "test-source", "AWSS3Source", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
 		"arn":         "arn:aws:s3:::dev",
@@ -113,3 +349,37 @@ func TestEventAttributes(t *testing.T) {
 	assert.Equal(t, "arn:aws:s3:::dev", attributes.ProducedEventSource)
 	assert.Equal(t, "com.amazon.s3.testevent", attributes.ProducedEventTypes[0])
 }
+
+func TestPlan(t *testing.T) {
+	s3 := newUnstructured(t, "test-source", "AWSS3Source", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
+		"arn": "arn:aws:s3:::dev",
+	})
+	changes, err := Plan(s3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`create SQS queue "s3-events_dev"`,
+		`modify S3 bucket "dev" event notifications`,
+	}, changes)
+
+	storage := newUnstructured(t, "test-source", "GoogleCloudStorageSource", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
+		"bucket": "dev",
+		"pubsub": map[string]interface{}{"topic": "projects/test/topics/dev"},
+	})
+	changes, err = Plan(storage)
+	assert.NoError(t, err)
+	assert.Equal(t, `use    Pub/Sub topic "projects/test/topics/dev"`, changes[0])
+
+	queue := newUnstructured(t, "test-source", "AzureServiceBusQueueSource", "sources.triggermesh.io/v1alpha1", map[string]interface{}{
+		"queueID": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ServiceBus/namespaces/ns/queues/q",
+	})
+	changes, err = Plan(queue)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`create Service Bus queue "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ServiceBus/namespaces/ns/queues/q" if it does not exist`,
+	}, changes)
+
+	webhook := newUnstructured(t, "test-source", "WebhookSource", "sources.triggermesh.io/v1alpha1", map[string]interface{}{})
+	changes, err = Plan(webhook)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+// credentialsDir is the container directory with the developer credentials.
+const credentialsDir = "/var/run/tmctl/credentials"
+
+// Cloud providers with the native credentials chains.
+const (
+	ProviderAWS   = "aws"
+	ProviderAzure = "azure"
+	ProviderGCP   = "gcp"
+)
+
+// NativeCredentials returns true if the component uses the developer
+// credentials chain instead of the secrets.
+func NativeCredentials(annotations map[string]string) bool {
+	return annotations[triggermesh.CredentialsAnnotation] == triggermesh.NativeCredentials
+}
+
+// Provider returns the cloud provider of the component kind,
+// empty string if the kind is not a cloud component.
+func Provider(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "AWS"):
+		return ProviderAWS
+	case strings.HasPrefix(kind, "Azure"):
+		return ProviderAzure
+	case strings.HasPrefix(kind, "GoogleCloud"):
+		return ProviderGCP
+	}
+	return ""
+}
+
+// ErrAzureNativeCredentials is returned for the Azure components with the
+// native credentials and no spec credentials: the Azure CLI login is used
+// to reconcile the external resources on the host, but the adapters cannot
+// read it without the "az" binary that is not in the adapter images.
+var ErrAzureNativeCredentials = errors.New("Azure adapters cannot use the Azure CLI login, set the spec auth credentials for the adapter")
+
+// CredentialsOverrides returns the read-only mounts and the variables
+// that pass the developer credentials of the component cloud provider
+// into the container: AWS shared credentials and config files with the
+// selected profile or GCP Application Default Credentials. Static keys
+// from the environment are never passed, they would be visible in the
+// container configuration. Azure adapters keep using the spec credentials.
+func CredentialsOverrides(object unstructured.Unstructured) (Overrides, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Overrides{}, err
+	}
+	var o Overrides
+	switch kind := object.GetKind(); Provider(kind) {
+	case ProviderAWS:
+		dir := path.Join(credentialsDir, ProviderAWS)
+		for _, f := range []struct{ env, host, name string }{
+			{"AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, ".aws", "credentials"), "credentials"},
+			{"AWS_CONFIG_FILE", filepath.Join(home, ".aws", "config"), "config"},
+		} {
+			host := f.host
+			if value := os.Getenv(f.env); value != "" {
+				host = value
+			}
+			if !exists(host) {
+				continue
+			}
+			file := path.Join(dir, f.name)
+			o.Mounts = append(o.Mounts, Mount{Host: host, Container: file, ReadOnly: true})
+			o.Env = append(o.Env, corev1.EnvVar{Name: f.env, Value: file})
+		}
+		if len(o.Mounts) == 0 {
+			return Overrides{}, fmt.Errorf("AWS shared credentials are not found in ~/.aws, run \"aws configure\"")
+		}
+		profile := os.Getenv("AWS_PROFILE")
+		if profile == "" {
+			profile = "default"
+		}
+		o.Env = append(o.Env,
+			corev1.EnvVar{Name: "AWS_PROFILE", Value: profile},
+			corev1.EnvVar{Name: "AWS_SDK_LOAD_CONFIG", Value: "1"})
+		o.Env = append(o.Env, hostEnv("AWS_REGION", "AWS_DEFAULT_REGION")...)
+	case ProviderGCP:
+		host := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		if host == "" {
+			host = filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+			if runtime.GOOS == "windows" {
+				host = filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+			}
+		}
+		if !exists(host) {
+			return Overrides{}, fmt.Errorf("application default credentials are not found, run \"gcloud auth application-default login\"")
+		}
+		file := path.Join(credentialsDir, ProviderGCP, "credentials.json")
+		o.Mounts = append(o.Mounts, Mount{Host: host, Container: file, ReadOnly: true})
+		o.Env = append(o.Env, corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: file})
+		o.Env = append(o.Env, hostEnv("GOOGLE_CLOUD_PROJECT")...)
+	case ProviderAzure:
+		if auth, _, _ := unstructured.NestedMap(object.Object, "spec", "auth"); len(auth) == 0 {
+			return Overrides{}, ErrAzureNativeCredentials
+		}
+	default:
+		return Overrides{}, fmt.Errorf("%s does not support native credentials", kind)
+	}
+	return o, nil
+}
+
+// hostEnv returns the variables that are set in the CLI environment.
+func hostEnv(names ...string) []corev1.EnvVar {
+	var result []corev1.EnvVar
+	for _, name := range names {
+		if value, set := os.LookupEnv(name); set {
+			result = append(result, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	return result
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
+)
+
+const dockerHost = "host.docker.internal"
+
+// endpointsEnv are the variables that override the service endpoints
+// in the adapter SDKs.
+var endpointsEnv = map[string]string{
+	pkg.EndpointAWS:     "AWS_ENDPOINT_URL",
+	pkg.EndpointPubSub:  "PUBSUB_EMULATOR_HOST",
+	pkg.EndpointStorage: "STORAGE_EMULATOR_HOST",
+}
+
+// EndpointsEnv returns the adapter variables of the endpoint overrides,
+// loopback hosts are replaced with the Docker host.
+func EndpointsEnv(annotations map[string]string) ([]corev1.EnvVar, error) {
+	endpoints, err := pkg.ParseEndpoints(annotations[triggermesh.EndpointsAnnotation])
+	if err != nil {
+		return nil, err
+	}
+	var envs []corev1.EnvVar
+	for service, endpoint := range endpoints {
+		envs = append(envs, corev1.EnvVar{Name: endpointsEnv[service], Value: containerEndpoint(endpoint)})
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+	return envs, nil
+}
+
+// containerEndpoint replaces the loopback host of the endpoint
+// with the address of the Docker host.
+func containerEndpoint(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil || !isLoopback(u.Hostname()) {
+			return endpoint
+		}
+		if port := u.Port(); port != "" {
+			u.Host = net.JoinHostPort(dockerHost, port)
+		} else {
+			u.Host = dockerHost
+		}
+		return u.String()
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil || !isLoopback(host) {
+		return endpoint
+	}
+	return net.JoinHostPort(dockerHost, port)
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
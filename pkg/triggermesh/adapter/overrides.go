@@ -0,0 +1,310 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/godo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+)
+
+// Overrides are the container parameters that the user set
+// on the component with the annotations.
+type Overrides struct {
+	Resources corev1.ResourceList
+	Env       []corev1.EnvVar
+	Mounts    []Mount
+}
+
+// Mount is the host path bound to the container path.
+type Mount struct {
+	Host      string
+	Container string
+	ReadOnly  bool
+}
+
+// ParseOverrides reads the resources, env and mounts annotations.
+func ParseOverrides(annotations map[string]string) (Overrides, error) {
+	var o Overrides
+	var err error
+	if value, set := annotations[triggermesh.ResourcesAnnotation]; set {
+		if o.Resources, err = ParseResources(value); err != nil {
+			return Overrides{}, fmt.Errorf("resources annotation: %w", err)
+		}
+	}
+	if value, set := annotations[triggermesh.EnvAnnotation]; set {
+		if o.Env, err = ParseEnv(value); err != nil {
+			return Overrides{}, fmt.Errorf("env annotation: %w", err)
+		}
+	}
+	if value, set := annotations[triggermesh.MountsAnnotation]; set {
+		if o.Mounts, err = ParseMounts(value); err != nil {
+			return Overrides{}, fmt.Errorf("mounts annotation: %w", err)
+		}
+	}
+	return o, nil
+}
+
+// ParseResources converts "cpu=1,memory=512Mi" string into the resource list.
+func ParseResources(value string) (corev1.ResourceList, error) {
+	resources := corev1.ResourceList{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, quantity, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("%q is not a name=quantity pair", item)
+		}
+		switch corev1.ResourceName(name) {
+		case corev1.ResourceCPU, corev1.ResourceMemory:
+		default:
+			return nil, fmt.Errorf("unsupported resource %q, expected \"cpu\" or \"memory\"", name)
+		}
+		q, err := resource.ParseQuantity(quantity)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", name, err)
+		}
+		if q.Sign() <= 0 {
+			return nil, fmt.Errorf("resource %q must be positive", name)
+		}
+		resources[corev1.ResourceName(name)] = q
+	}
+	return resources, nil
+}
+
+// FormatResources is the reverse of ParseResources.
+func FormatResources(resources corev1.ResourceList) string {
+	var result []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, set := resources[name]; set {
+			result = append(result, fmt.Sprintf("%s=%s", name, q.String()))
+		}
+	}
+	return strings.Join(result, ",")
+}
+
+// ParseEnv reads the newline separated KEY=VALUE variables.
+func ParseEnv(value string) ([]corev1.EnvVar, error) {
+	var envs []corev1.EnvVar
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		name, val, found := strings.Cut(line, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("%q is not a KEY=VALUE pair", line)
+		}
+		envs = mergeEnv(envs, corev1.EnvVar{Name: name, Value: val})
+	}
+	return envs, nil
+}
+
+// FormatEnv is the reverse of ParseEnv.
+func FormatEnv(envs []corev1.EnvVar) string {
+	result := make([]string, 0, len(envs))
+	for _, env := range envs {
+		result = append(result, env.Name+"="+env.Value)
+	}
+	return strings.Join(result, "\n")
+}
+
+// ParseMounts reads the newline separated "host:container[:ro|rw]" binds.
+// Binds are parsed from the right so that host paths may contain colons,
+// e.g. Windows drive letters.
+func ParseMounts(value string) ([]Mount, error) {
+	var mounts []Mount
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		mount, err := parseMount(line)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+func parseMount(bind string) (Mount, error) {
+	var mount Mount
+	rest, last, found := cutLast(bind, ":")
+	if !found {
+		return mount, fmt.Errorf("%q is not a host:container[:mode] bind", bind)
+	}
+	if !strings.HasPrefix(last, "/") {
+		switch last {
+		case "ro":
+			mount.ReadOnly = true
+		case "rw":
+		default:
+			return mount, fmt.Errorf("unsupported mount mode %q, expected \"ro\" or \"rw\"", last)
+		}
+		if rest, last, found = cutLast(rest, ":"); !found {
+			return mount, fmt.Errorf("%q is not a host:container[:mode] bind", bind)
+		}
+	}
+	if rest == "" {
+		return mount, fmt.Errorf("%q is not a host:container[:mode] bind", bind)
+	}
+	if !path.IsAbs(last) {
+		return mount, fmt.Errorf("container path %q must be absolute", last)
+	}
+	mount.Host, mount.Container = rest, last
+	return mount, nil
+}
+
+// cutLast is strings.Cut around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// FormatMounts is the reverse of ParseMounts.
+func FormatMounts(mounts []Mount) string {
+	result := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		result = append(result, m.String())
+	}
+	return strings.Join(result, "\n")
+}
+
+func (m Mount) String() string {
+	if m.ReadOnly {
+		return m.Host + ":" + m.Container + ":ro"
+	}
+	return m.Host + ":" + m.Container
+}
+
+// HostOptions returns the Docker host config options of the overrides.
+func (o Overrides) HostOptions() []docker.HostOption {
+	var ho []docker.HostOption
+	if len(o.Resources) != 0 {
+		var nanoCPUs, memory int64
+		if cpu, set := o.Resources[corev1.ResourceCPU]; set {
+			nanoCPUs = cpu.MilliValue() * 1e6
+		}
+		if mem, set := o.Resources[corev1.ResourceMemory]; set {
+			memory = mem.Value()
+		}
+		ho = append(ho, docker.WithResources(nanoCPUs, memory))
+	}
+	for _, m := range o.Mounts {
+		ho = append(ho, docker.WithVolumeBind(m.String()))
+	}
+	return ho
+}
+
+// MergeEnv sets the env overrides on top of the variables list.
+func (o Overrides) MergeEnv(envs []corev1.EnvVar) []corev1.EnvVar {
+	for _, env := range o.Env {
+		envs = mergeEnv(envs, env)
+	}
+	return envs
+}
+
+// MergeDigitalOceanEnv sets the env overrides on top of the App Platform variables.
+// Resources and mounts are not carried, App Platform instances have fixed sizes
+// and no host volumes.
+func (o Overrides) MergeDigitalOceanEnv(envs []*godo.AppVariableDefinition) []*godo.AppVariableDefinition {
+	for _, env := range o.Env {
+		replaced := false
+		for _, e := range envs {
+			if e.Key == env.Name {
+				e.Value = env.Value
+				replaced = true
+			}
+		}
+		if !replaced {
+			envs = append(envs, &godo.AppVariableDefinition{Key: env.Name, Value: env.Value})
+		}
+	}
+	return envs
+}
+
+// ApplyToDeployment sets the env, resource limits and host path volumes
+// on the adapter container of the deployment.
+func (o Overrides) ApplyToDeployment(deployment appsv1.Deployment) appsv1.Deployment {
+	podSpec := &deployment.Spec.Template.Spec
+	container := &podSpec.Containers[0]
+	container.Env = o.MergeEnv(container.Env)
+	if len(o.Resources) != 0 {
+		container.Resources.Limits = o.Resources.DeepCopy()
+	}
+	for i, m := range o.Mounts {
+		name := "mount-" + strconv.Itoa(i)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: m.Host},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: m.Container,
+			ReadOnly:  m.ReadOnly,
+		})
+	}
+	return deployment
+}
+
+// ApplyToComposeService sets the env, resource limits and volumes on the service.
+func (o Overrides) ApplyToComposeService(service *docker.ComposeService) {
+	for _, env := range o.Env {
+		replaced := false
+		for i, e := range service.Environment {
+			if strings.HasPrefix(e, env.Name+"=") {
+				service.Environment[i] = env.Name + "=" + env.Value
+				replaced = true
+			}
+		}
+		if !replaced {
+			service.Environment = append(service.Environment, env.Name+"="+env.Value)
+		}
+	}
+	if cpu, set := o.Resources[corev1.ResourceCPU]; set {
+		service.CPUs = strconv.FormatFloat(float64(cpu.MilliValue())/1000, 'f', -1, 64)
+	}
+	if mem, set := o.Resources[corev1.ResourceMemory]; set {
+		service.MemLimit = mem.Value()
+	}
+	for _, m := range o.Mounts {
+		service.Volumes = append(service.Volumes, m.String())
+	}
+}
+
+func mergeEnv(envs []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i := range envs {
+		if envs[i].Name == env.Name {
+			envs[i] = env
+			return envs
+		}
+	}
+	return append(envs, env)
+}
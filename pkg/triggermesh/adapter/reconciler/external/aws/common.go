@@ -16,7 +16,16 @@ limitations under the License.
 
 package aws
 
-import "fmt"
+import (
+	"fmt"
+
+	awscore "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
 
 const (
 	awsAccessKeyEnv = "accessKeyID"
@@ -34,3 +43,58 @@ func readSecret(secrets map[string]string) (string, string, error) {
 	}
 	return accessKey, secretKey, nil
 }
+
+// readCredentials returns the static credentials from the secrets or,
+// if the secrets are nil, the credentials of the default chain:
+// environment, shared config profiles and instance roles.
+func readCredentials(secrets map[string]string) (*credentials.Credentials, error) {
+	if secrets == nil {
+		sess, err := session.NewSessionWithOptions(session.Options{
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("default credentials chain: %w", err)
+		}
+		return sess.Config.Credentials, nil
+	}
+	accessKey, secretKey, err := readSecret(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewStaticCredentials(accessKey, secretKey, ""), nil
+}
+
+// newConfig returns the client config with the credentials and the
+// endpoint override, S3 buckets are addressed by path on custom endpoints.
+func newConfig(secrets map[string]string, endpoint string) (*awscore.Config, error) {
+	creds, err := readCredentials(secrets)
+	if err != nil {
+		return nil, err
+	}
+	config := awscore.NewConfig().WithCredentials(creds)
+	if endpoint != "" {
+		config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	return config, nil
+}
+
+// QueueExists checks if the SQS queue with the given ARN exists.
+func QueueExists(secrets map[string]string, endpoint, queueARN string) (bool, error) {
+	queue, err := arn.Parse(queueARN)
+	if err != nil {
+		return false, fmt.Errorf("parsing queue ARN: %w", err)
+	}
+	config, err := newConfig(secrets, endpoint)
+	if err != nil {
+		return false, fmt.Errorf("secrets read: %w", err)
+	}
+	sess := session.Must(session.NewSession(config.WithRegion(queue.Region)))
+	_, err = sqs.New(sess).GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName:              &queue.Resource,
+		QueueOwnerAWSAccountId: &queue.AccountID,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == sqs.ErrCodeQueueDoesNotExist {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -19,8 +19,6 @@ package aws
 import (
 	"fmt"
 
-	awscore "github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/eventbridge"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -28,20 +26,13 @@ import (
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
 )
 
-func EBClient(src *sourcesv1alpha1.AWSEventBridgeSource, secrets map[string]string) (*eventbridge.EventBridge, *sqs.SQS, error) {
-	accessKey, secretKey, err := readSecret(secrets)
+func EBClient(src *sourcesv1alpha1.AWSEventBridgeSource, secrets map[string]string, endpoint string) (*eventbridge.EventBridge, *sqs.SQS, error) {
+	config, err := newConfig(secrets, endpoint)
 	if err != nil {
 		return nil, nil, fmt.Errorf("secrets read: %w", err)
 	}
-	creds := &credentials.Value{
-		AccessKeyID:     accessKey,
-		SecretAccessKey: secretKey,
-	}
 
-	sess := session.Must(session.NewSession(awscore.NewConfig().
-		WithRegion(src.Spec.ARN.Region).
-		WithCredentials(credentials.NewStaticCredentialsFromCreds(*creds)),
-	))
+	sess := session.Must(session.NewSession(config.WithRegion(src.Spec.ARN.Region)))
 
 	return eventbridge.New(sess), sqs.New(sess), nil
 }
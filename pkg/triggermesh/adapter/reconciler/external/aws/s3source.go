@@ -20,7 +20,6 @@ import (
 	"fmt"
 
 	awscore "github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -31,17 +30,12 @@ import (
 
 const defaultS3Region = "us-east-1"
 
-func S3Client(src *sourcesv1alpha1.AWSS3Source, secrets map[string]string) (*s3.S3, *sqs.SQS, error) {
-	accessKey, secretKey, err := readSecret(secrets)
+func S3Client(src *sourcesv1alpha1.AWSS3Source, secrets map[string]string, endpoint string) (*s3.S3, *sqs.SQS, error) {
+	config, err := newConfig(secrets, endpoint)
 	if err != nil {
 		return nil, nil, fmt.Errorf("secrets read: %w", err)
 	}
-	sess := session.Must(session.NewSession(awscore.NewConfig()))
-	creds := &credentials.Value{
-		AccessKeyID:     accessKey,
-		SecretAccessKey: secretKey,
-	}
-	region, err := determineS3Region(src, creds)
+	region, err := determineS3Region(src, config)
 	if err != nil {
 		return nil, nil, fmt.Errorf("determining suitable S3 region: %w", err)
 	}
@@ -49,7 +43,7 @@ func S3Client(src *sourcesv1alpha1.AWSS3Source, secrets map[string]string) (*s3.
 		src.Spec.ARN.Region = region
 	}
 
-	accID, err := determineBucketOwnerAccount(src, creds)
+	accID, err := determineBucketOwnerAccount(src, config)
 	if err != nil {
 		return nil, nil, fmt.Errorf("determining bucket's owner: %w", err)
 	}
@@ -57,14 +51,11 @@ func S3Client(src *sourcesv1alpha1.AWSS3Source, secrets map[string]string) (*s3.
 		src.Spec.ARN.AccountID = accID
 	}
 
-	sess.Config.
-		WithRegion(src.Spec.ARN.Region).
-		WithCredentials(credentials.NewStaticCredentialsFromCreds(*creds))
-
+	sess := session.Must(session.NewSession(config.Copy().WithRegion(src.Spec.ARN.Region)))
 	return s3.New(sess), sqs.New(sess), nil
 }
 
-func determineS3Region(src *sourcesv1alpha1.AWSS3Source, creds *credentials.Value) (string, error) {
+func determineS3Region(src *sourcesv1alpha1.AWSS3Source, config *awscore.Config) (string, error) {
 	if src.Spec.ARN.Region != "" {
 		return src.Spec.ARN.Region, nil
 	}
@@ -75,7 +66,7 @@ func determineS3Region(src *sourcesv1alpha1.AWSS3Source, creds *credentials.Valu
 		}
 	}
 
-	region, err := getBucketRegion(src.Spec.ARN.Resource, creds)
+	region, err := getBucketRegion(src.Spec.ARN.Resource, config)
 	if err != nil {
 		return "", fmt.Errorf("getting location of bucket %q: %w", src.Spec.ARN.Resource, err)
 	}
@@ -84,11 +75,8 @@ func determineS3Region(src *sourcesv1alpha1.AWSS3Source, creds *credentials.Valu
 }
 
 // getBucketRegion retrieves the region the provided bucket resides in.
-func getBucketRegion(bucketName string, creds *credentials.Value) (string, error) {
-	sess := session.Must(session.NewSession(awscore.NewConfig().
-		WithRegion(defaultS3Region).
-		WithCredentials(credentials.NewStaticCredentialsFromCreds(*creds)),
-	))
+func getBucketRegion(bucketName string, config *awscore.Config) (string, error) {
+	sess := session.Must(session.NewSession(config.Copy().WithRegion(defaultS3Region)))
 
 	resp, err := s3.New(sess).GetBucketLocation(&s3.GetBucketLocationInput{
 		Bucket: &bucketName,
@@ -103,7 +91,7 @@ func getBucketRegion(bucketName string, creds *credentials.Value) (string, error
 	return defaultS3Region, nil
 }
 
-func determineBucketOwnerAccount(src *sourcesv1alpha1.AWSS3Source, creds *credentials.Value) (string, error) {
+func determineBucketOwnerAccount(src *sourcesv1alpha1.AWSS3Source, config *awscore.Config) (string, error) {
 	if src.Spec.ARN.AccountID != "" {
 		return src.Spec.ARN.AccountID, nil
 	}
@@ -114,7 +102,7 @@ func determineBucketOwnerAccount(src *sourcesv1alpha1.AWSS3Source, creds *creden
 		}
 	}
 
-	accID, err := getCallerAccountID(creds)
+	accID, err := getCallerAccountID(config)
 	if err != nil {
 		return "", fmt.Errorf("getting ID of caller: %w", err)
 	}
@@ -123,10 +111,8 @@ func determineBucketOwnerAccount(src *sourcesv1alpha1.AWSS3Source, creds *creden
 }
 
 // getCallerAccountID retrieves the account ID of the caller.
-func getCallerAccountID(creds *credentials.Value) (string, error) {
-	sess := session.Must(session.NewSession(awscore.NewConfig().
-		WithCredentials(credentials.NewStaticCredentialsFromCreds(*creds)),
-	))
+func getCallerAccountID(config *awscore.Config) (string, error) {
+	sess := session.Must(session.NewSession(config.Copy()))
 
 	resp, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
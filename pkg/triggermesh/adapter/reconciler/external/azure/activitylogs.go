@@ -17,7 +17,9 @@ limitations under the License.
 package azure
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -49,3 +51,92 @@ func ActivityLogsClient(src *v1alpha1.AzureActivityLogsSource, authorizer autore
 	diagSettingsCli.Authorizer = authorizer
 	return eventCatCli, diagSettingsCli, nil
 }
+
+// defaultSASPolicyName is the Event Hubs namespace policy used
+// by the diagnostic settings if the source spec does not set one.
+const defaultSASPolicyName = "RootManageSharedAccessKey"
+
+// EnsureDiagnosticSettings creates or updates the diagnostic settings of the
+// Azure subscription that stream the Activity Logs into the Event Hubs
+// namespace and returns the settings resource ID.
+func EnsureDiagnosticSettings(ctx context.Context, src *v1alpha1.AzureActivityLogsSource,
+	eventCatCli insights.EventCategoriesClient, diagSettingsCli insights.DiagnosticSettingsClient) (string, error) {
+	availCatList, err := eventCatCli.List(ctx)
+	if err != nil {
+		return "", fmt.Errorf("listing event categories: %w", err)
+	}
+	logSettings, err := logSettings(src.Spec.Categories, availCatList)
+	if err != nil {
+		return "", err
+	}
+	sasPolicyName := defaultSASPolicyName
+	if name := src.Spec.Destination.EventHubs.SASPolicy; name != nil && *name != "" {
+		sasPolicyName = *name
+	}
+	sasPolicyID := src.Spec.Destination.EventHubs.NamespaceID.String() + "/authorizationRules/" + sasPolicyName
+	var eventHubName *string
+	if name := src.Spec.Destination.EventHubs.HubName; name != nil && *name != "" {
+		eventHubName = name
+	}
+	restCtx, cancel := context.WithTimeout(ctx, ActivityLogsCrudTimeout)
+	defer cancel()
+	settings, err := diagSettingsCli.CreateOrUpdate(restCtx, subscriptionResourceID(src.Spec.SubscriptionID), insights.DiagnosticSettingsResource{
+		DiagnosticSettings: &insights.DiagnosticSettings{
+			EventHubAuthorizationRuleID: &sasPolicyID,
+			EventHubName:                eventHubName,
+			Logs:                        &logSettings,
+		},
+	}, ActivityLogsDiagsName(src))
+	if err != nil {
+		return "", fmt.Errorf("creating diagnostic settings: %w", err)
+	}
+	if settings.ID == nil {
+		return "", nil
+	}
+	return *settings.ID, nil
+}
+
+// EnsureNoDiagnosticSettings removes the Activity Logs diagnostic settings.
+func EnsureNoDiagnosticSettings(ctx context.Context, src *v1alpha1.AzureActivityLogsSource, diagSettingsCli insights.DiagnosticSettingsClient) error {
+	restCtx, cancel := context.WithTimeout(ctx, ActivityLogsCrudTimeout)
+	defer cancel()
+	_, err := diagSettingsCli.Delete(restCtx, subscriptionResourceID(src.Spec.SubscriptionID), ActivityLogsDiagsName(src))
+	if err != nil && !IsNotFoundErr(err) {
+		return fmt.Errorf("deleting diagnostic settings: %w", err)
+	}
+	return nil
+}
+
+// logSettings enables the log categories from the source spec,
+// all available categories are enabled if the spec has none.
+func logSettings(categories []string, available insights.EventCategoryCollection) ([]insights.LogSettings, error) {
+	desired := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		desired[category] = true
+	}
+	var result []insights.LogSettings
+	enabled := 0
+	if available.Value != nil {
+		for _, category := range *available.Value {
+			if category.Value == nil {
+				continue
+			}
+			enable := len(categories) == 0 || desired[*category.Value]
+			if enable {
+				enabled++
+			}
+			result = append(result, insights.LogSettings{
+				Category: category.Value,
+				Enabled:  &enable,
+			})
+		}
+	}
+	if enabled == 0 {
+		return nil, fmt.Errorf("spec does not contain any valid log category")
+	}
+	return result, nil
+}
+
+func subscriptionResourceID(subscriptionID string) string {
+	return (&v1alpha1.AzureResourceID{SubscriptionID: subscriptionID}).String()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/2020-09-01/monitor/mgmt/insights"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
+)
+
+const settingsPath = "/subscriptions/sub/providers/microsoft.insights/diagnosticSettings/io.triggermesh.azureactivitylogssource.default.logs"
+
+func newActivityLogsSource(t *testing.T, categories ...string) *v1alpha1.AzureActivityLogsSource {
+	var namespaceID v1alpha1.AzureResourceID
+	assert.NoError(t, namespaceID.UnmarshalJSON([]byte(`"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.EventHub/namespaces/ns"`)))
+	src := &v1alpha1.AzureActivityLogsSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default"},
+	}
+	src.Spec.SubscriptionID = "sub"
+	src.Spec.Categories = categories
+	src.Spec.Destination.EventHubs.NamespaceID = namespaceID
+	return src
+}
+
+func newActivityLogsClients(url string) (insights.EventCategoriesClient, insights.DiagnosticSettingsClient) {
+	eventCatCli := insights.NewEventCategoriesClientWithBaseURI(url, "sub")
+	eventCatCli.Authorizer = autorest.NullAuthorizer{}
+	diagSettingsCli := insights.NewDiagnosticSettingsClientWithBaseURI(url, "sub")
+	diagSettingsCli.Authorizer = autorest.NullAuthorizer{}
+	return eventCatCli, diagSettingsCli
+}
+
+func TestEnsureDiagnosticSettings(t *testing.T) {
+	var settings insights.DiagnosticSettingsResource
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/providers/microsoft.insights/eventcategories"):
+			_, _ = w.Write([]byte(`{"value":[{"value":"Administrative"},{"value":"Security"},{"value":"Policy"}]}`))
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, settingsPath):
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&settings))
+			_, _ = w.Write([]byte(`{"id":"` + settingsPath + `"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	eventCatCli, diagSettingsCli := newActivityLogsClients(server.URL)
+
+	id, err := EnsureDiagnosticSettings(context.Background(), newActivityLogsSource(t, "Security"), eventCatCli, diagSettingsCli)
+	assert.NoError(t, err)
+	assert.Equal(t, settingsPath, id)
+	assert.Equal(t, "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.EventHub/namespaces/ns/authorizationRules/RootManageSharedAccessKey",
+		*settings.EventHubAuthorizationRuleID)
+	assert.Nil(t, settings.EventHubName)
+	enabled := make(map[string]bool)
+	for _, log := range *settings.Logs {
+		enabled[*log.Category] = *log.Enabled
+	}
+	assert.Equal(t, map[string]bool{"Administrative": false, "Security": true, "Policy": false}, enabled)
+
+	_, err = EnsureDiagnosticSettings(context.Background(), newActivityLogsSource(t, "Unknown"), eventCatCli, diagSettingsCli)
+	assert.Error(t, err, "Unknown categories are rejected")
+}
+
+func TestEnsureNoDiagnosticSettings(t *testing.T) {
+	status := http.StatusOK
+	var deleted int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || !strings.HasSuffix(r.URL.Path, settingsPath) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		deleted++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	_, diagSettingsCli := newActivityLogsClients(server.URL)
+	src := newActivityLogsSource(t)
+
+	assert.NoError(t, EnsureNoDiagnosticSettings(context.Background(), src, diagSettingsCli))
+	assert.Equal(t, 1, deleted)
+
+	status = http.StatusNotFound
+	assert.NoError(t, EnsureNoDiagnosticSettings(context.Background(), src, diagSettingsCli), "Missing settings are ignored")
+
+	status = http.StatusForbidden
+	assert.Error(t, EnsureNoDiagnosticSettings(context.Background(), src, diagSettingsCli))
+}
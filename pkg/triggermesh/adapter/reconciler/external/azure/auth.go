@@ -17,14 +17,27 @@ limitations under the License.
 package azure
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/resources/mgmt/resources"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
 	autorestauth "github.com/Azure/go-autorest/autorest/azure/auth"
 )
 
+// Client returns the authorizer of the service principal from the secrets
+// or, if the secrets are nil, the authorizer of the Azure CLI session.
 func Client(secrets map[string]string) (autorest.Authorizer, error) {
+	if secrets == nil {
+		authorizer, err := autorestauth.NewAuthorizerFromCLI()
+		if err != nil {
+			return nil, fmt.Errorf("azure CLI credentials: %w", err)
+		}
+		return authorizer, nil
+	}
 	tenantID, exists := secrets["tenantID"]
 	if !exists {
 		return nil, fmt.Errorf("\"tenantID\" spec value is missing")
@@ -49,3 +62,37 @@ func Client(secrets map[string]string) (autorest.Authorizer, error) {
 	}
 	return authSettings.GetAuthorizer()
 }
+
+// apiVersions are the resource provider API versions
+// used to check the existence of the resources.
+var apiVersions = map[string]string{
+	"microsoft.eventgrid":  "2021-12-01",
+	"microsoft.eventhub":   "2021-11-01",
+	"microsoft.servicebus": "2021-11-01",
+	"microsoft.insights":   "2021-05-01-preview",
+}
+
+// ResourceExists checks if the resource with the given ID exists.
+func ResourceExists(ctx context.Context, authorizer autorest.Authorizer, resourceID string) (bool, error) {
+	sections := strings.Split(strings.TrimPrefix(resourceID, "/"), "/")
+	if len(sections) < 2 || !strings.EqualFold(sections[0], "subscriptions") {
+		return false, fmt.Errorf("unexpected resource ID %q", resourceID)
+	}
+	var apiVersion string
+	for i, section := range sections[:len(sections)-1] {
+		if strings.EqualFold(section, "providers") {
+			apiVersion = apiVersions[strings.ToLower(sections[i+1])]
+			break
+		}
+	}
+	if apiVersion == "" {
+		return false, fmt.Errorf("unsupported resource provider in %q", resourceID)
+	}
+	client := resources.NewClient(sections[1])
+	client.Authorizer = authorizer
+	response, err := client.CheckExistenceByID(ctx, resourceID, apiVersion)
+	if response.Response != nil && response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/servicebus/mgmt/servicebus"
+	"github.com/Azure/go-autorest/autorest"
+
+	"github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
+)
+
+func ServiceBusQueue(src *v1alpha1.AzureServiceBusQueueSource, authorizer autorest.Authorizer) servicebus.QueuesClient {
+	queuesCli := servicebus.NewQueuesClient(src.Spec.QueueID.SubscriptionID)
+	queuesCli.Authorizer = authorizer
+	return queuesCli
+}
+
+// EnsureQueue creates the Service Bus queue if it does not exist and returns
+// its resource ID. Empty ID is returned for the existing queue, so that the
+// queue that is not created by the source is never deleted.
+func EnsureQueue(ctx context.Context, src *v1alpha1.AzureServiceBusQueueSource, cli servicebus.QueuesClient) (string, error) {
+	queueID := src.Spec.QueueID
+	_, err := cli.Get(ctx, queueID.ResourceGroup, queueID.Namespace, queueID.ResourceName)
+	switch {
+	case err == nil:
+		return "", nil
+	case !IsNotFoundErr(err):
+		return "", fmt.Errorf("getting queue: %w", err)
+	}
+	queue, err := cli.CreateOrUpdate(ctx, queueID.ResourceGroup, queueID.Namespace, queueID.ResourceName, servicebus.SBQueue{})
+	if err != nil {
+		return "", fmt.Errorf("creating queue: %w", err)
+	}
+	if queue.ID == nil {
+		return queueID.String(), nil
+	}
+	return *queue.ID, nil
+}
+
+// EnsureNoQueue deletes the Service Bus queue.
+func EnsureNoQueue(ctx context.Context, src *v1alpha1.AzureServiceBusQueueSource, cli servicebus.QueuesClient) error {
+	queueID := src.Spec.QueueID
+	_, err := cli.Delete(ctx, queueID.ResourceGroup, queueID.Namespace, queueID.ResourceName)
+	if err != nil && !IsNotFoundErr(err) {
+		return fmt.Errorf("deleting queue: %w", err)
+	}
+	return nil
+}
@@ -26,8 +26,8 @@ import (
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
 )
 
-func AuditLogsClient(ctx context.Context, src *sourcesv1alpha1.GoogleCloudAuditLogsSource, secrets map[string]string) (*pubsub.Client, *logadmin.Client, error) {
-	psCli, credsCliOpt, err := pubSubClient(ctx, src.Spec.PubSub, secrets)
+func AuditLogsClient(ctx context.Context, src *sourcesv1alpha1.GoogleCloudAuditLogsSource, secrets, endpoints map[string]string) (*pubsub.Client, *logadmin.Client, error) {
+	psCli, credsCliOpt, err := pubSubClient(ctx, src.Spec.PubSub, secrets, endpoints)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -26,9 +26,9 @@ import (
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
 )
 
-func CloudBillingClient(src *sourcesv1alpha1.GoogleCloudBillingSource, secrets map[string]string) (*pubsub.Client, *billing.BudgetClient, error) {
+func CloudBillingClient(src *sourcesv1alpha1.GoogleCloudBillingSource, secrets, endpoints map[string]string) (*pubsub.Client, *billing.BudgetClient, error) {
 	ctx := context.Background()
-	psClient, credsClientOptions, err := pubSubClient(ctx, src.Spec.PubSub, secrets)
+	psClient, credsClientOptions, err := pubSubClient(ctx, src.Spec.PubSub, secrets, endpoints)
 	if err != nil {
 		return nil, nil, err
 	}
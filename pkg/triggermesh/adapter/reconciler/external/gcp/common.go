@@ -19,19 +19,28 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"cloud.google.com/go/pubsub"
+	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
-func pubSubClient(ctx context.Context, spec sourcesv1alpha1.GoogleCloudSourcePubSubSpec, secrets map[string]string) (*pubsub.Client, option.ClientOption, error) {
-	saKey, exists := secrets["serviceAccountKey"]
-	if !exists {
-		return nil, nil, fmt.Errorf("\"serviceAccountKey\" is missing")
+func pubSubClient(ctx context.Context, spec sourcesv1alpha1.GoogleCloudSourcePubSubSpec, secrets, endpoints map[string]string) (*pubsub.Client, option.ClientOption, error) {
+	credsCliOpt, err := credentialsOption(ctx, secrets)
+	if err != nil {
+		if endpoints[pkg.EndpointPubSub] == "" {
+			return nil, nil, err
+		}
+		// the emulator does not need the credentials
+		credsCliOpt = option.WithoutAuthentication()
 	}
-	credsCliOpt := option.WithCredentialsJSON([]byte(saKey))
 
 	var pubsubProject string
 	if project := spec.Project; project != nil {
@@ -39,9 +48,69 @@ func pubSubClient(ctx context.Context, spec sourcesv1alpha1.GoogleCloudSourcePub
 	} else if topic := spec.Topic; topic != nil {
 		pubsubProject = topic.Project
 	}
-	psCli, err := pubsub.NewClient(ctx, pubsubProject, credsCliOpt)
+	psCli, err := pubsub.NewClient(ctx, pubsubProject, pubSubOptions(credsCliOpt, endpoints)...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating Google Cloud Pub/Sub API client: %w", err)
 	}
 	return psCli, credsCliOpt, nil
 }
+
+// credentialsOption returns the service account key from the secrets or,
+// if the secrets are nil, the Application Default Credentials.
+func credentialsOption(ctx context.Context, secrets map[string]string) (option.ClientOption, error) {
+	if secrets == nil {
+		creds, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			return nil, fmt.Errorf("application default credentials: %w", err)
+		}
+		return option.WithCredentials(creds), nil
+	}
+	saKey, exists := secrets["serviceAccountKey"]
+	if !exists {
+		return nil, fmt.Errorf("\"serviceAccountKey\" is missing")
+	}
+	return option.WithCredentialsJSON([]byte(saKey)), nil
+}
+
+// pubSubOptions returns the Pub/Sub client options, the emulator
+// endpoint replaces the credentials.
+func pubSubOptions(credsCliOpt option.ClientOption, endpoints map[string]string) []option.ClientOption {
+	endpoint, set := endpoints[pkg.EndpointPubSub]
+	if !set {
+		return []option.ClientOption{credsCliOpt}
+	}
+	return []option.ClientOption{
+		option.WithEndpoint(endpoint),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}
+}
+
+// PubSubResourceExists checks if the Pub/Sub topic or subscription
+// with the given "projects/{project}/{collection}/{id}" name exists.
+func PubSubResourceExists(ctx context.Context, secrets, endpoints map[string]string, name string) (bool, error) {
+	sections := strings.Split(name, "/")
+	if len(sections) != 4 || sections[0] != "projects" {
+		return false, fmt.Errorf("unexpected Pub/Sub resource name %q", name)
+	}
+	project, collection, id := sections[1], sections[2], sections[3]
+	credsCliOpt, err := credentialsOption(ctx, secrets)
+	if err != nil {
+		if endpoints[pkg.EndpointPubSub] == "" {
+			return false, err
+		}
+		credsCliOpt = option.WithoutAuthentication()
+	}
+	client, err := pubsub.NewClient(ctx, project, pubSubOptions(credsCliOpt, endpoints)...)
+	if err != nil {
+		return false, fmt.Errorf("creating Google Cloud Pub/Sub API client: %w", err)
+	}
+	defer client.Close()
+	switch collection {
+	case "topics":
+		return client.Topic(id).Exists(ctx)
+	case "subscriptions":
+		return client.Subscription(id).Exists(ctx)
+	}
+	return false, fmt.Errorf("unexpected Pub/Sub resource type %q", collection)
+}
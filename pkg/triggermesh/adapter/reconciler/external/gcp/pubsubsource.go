@@ -18,18 +18,23 @@ package gcp
 
 import (
 	"context"
-	"fmt"
 
 	"cloud.google.com/go/pubsub"
 	"google.golang.org/api/option"
 
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
-func PubSubClient(src *sourcesv1alpha1.GoogleCloudPubSubSource, secrets map[string]string) (*pubsub.Client, error) {
-	saKey, exists := secrets["serviceAccountKey"]
-	if !exists {
-		return nil, fmt.Errorf("\"serviceAccountKey\" is missing")
+func PubSubClient(src *sourcesv1alpha1.GoogleCloudPubSubSource, secrets, endpoints map[string]string) (*pubsub.Client, error) {
+	ctx := context.Background()
+	credsCliOpt, err := credentialsOption(ctx, secrets)
+	if err != nil {
+		if endpoints[pkg.EndpointPubSub] == "" {
+			return nil, err
+		}
+		credsCliOpt = option.WithoutAuthentication()
 	}
-	return pubsub.NewClient(context.Background(), src.Spec.Topic.Project, option.WithCredentialsJSON([]byte(saKey)))
+	return pubsub.NewClient(ctx, src.Spec.Topic.Project, pubSubOptions(credsCliOpt, endpoints)...)
 }
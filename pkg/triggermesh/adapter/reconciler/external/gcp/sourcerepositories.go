@@ -26,8 +26,8 @@ import (
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
 )
 
-func SourceRepoClient(ctx context.Context, src *sourcesv1alpha1.GoogleCloudSourceRepositoriesSource, secrets map[string]string) (*pubsub.Client, *sourcerepo.Service, error) {
-	psCli, credsCliOpt, err := pubSubClient(ctx, src.Spec.PubSub, secrets)
+func SourceRepoClient(ctx context.Context, src *sourcesv1alpha1.GoogleCloudSourceRepositoriesSource, secrets, endpoints map[string]string) (*pubsub.Client, *sourcerepo.Service, error) {
+	psCli, credsCliOpt, err := pubSubClient(ctx, src.Spec.PubSub, secrets, endpoints)
 	if err != nil {
 		return nil, nil, err
 	}
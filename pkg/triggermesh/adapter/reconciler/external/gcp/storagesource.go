@@ -19,21 +19,37 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"cloud.google.com/go/pubsub"
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
 
 	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
-func StorageClient(ctx context.Context, src *sourcesv1alpha1.GoogleCloudStorageSource, secrets map[string]string) (*pubsub.Client, *storage.Client, error) {
-	psCli, credsCliOpt, err := pubSubClient(ctx, src.Spec.PubSub, secrets)
+func StorageClient(ctx context.Context, src *sourcesv1alpha1.GoogleCloudStorageSource, secrets, endpoints map[string]string) (*pubsub.Client, *storage.Client, error) {
+	psCli, credsCliOpt, err := pubSubClient(ctx, src.Spec.PubSub, secrets, endpoints)
 	if err != nil {
 		return nil, nil, err
 	}
-	stCli, err := storage.NewClient(ctx, credsCliOpt)
+	stCliOpts := []option.ClientOption{credsCliOpt}
+	if endpoint, set := endpoints[pkg.EndpointStorage]; set {
+		stCliOpts = []option.ClientOption{option.WithEndpoint(storageEndpoint(endpoint)), option.WithoutAuthentication()}
+	}
+	stCli, err := storage.NewClient(ctx, stCliOpts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating Google Cloud Storage API client: %w", err)
 	}
 	return psCli, stCli, nil
 }
+
+// storageEndpoint returns the JSON API URL of the emulator host.
+func storageEndpoint(endpoint string) string {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/storage/v1/"
+}
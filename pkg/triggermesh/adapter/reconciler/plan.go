@@ -0,0 +1,207 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	sourcesv1alpha1 "github.com/triggermesh/triggermesh/pkg/apis/sources/v1alpha1"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler/external/aws"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler/external/azure"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler/external/gcp"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
+)
+
+// Plan returns the external resources that the component initialization
+// would create or modify. The plan is built from the component spec only,
+// no cloud APIs are called.
+func Plan(object unstructured.Unstructured) ([]string, error) {
+	switch object.GetKind() {
+	case "AWSS3Source":
+		var o *sourcesv1alpha1.AWSS3Source
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		queue := change("create", "SQS queue %q", "s3-events_"+o.Spec.ARN.Resource)
+		if dest := o.Spec.Destination; dest != nil && dest.SQS != nil {
+			queue = change("use", "SQS queue %q", dest.SQS.QueueARN.String())
+		}
+		return []string{
+			queue,
+			change("modify", "S3 bucket %q event notifications", o.Spec.ARN.Resource),
+		}, nil
+	case "AWSEventBridgeSource":
+		var o *sourcesv1alpha1.AWSEventBridgeSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		// queue name is derived the same way as in the source reconciler
+		checksum := crc32.ChecksumIEEE([]byte(o.Namespace + "/" + o.Name))
+		queueName := "io_triggermesh_awseventbridgesources-" + strconv.FormatUint(uint64(checksum), 10)
+		queue := change("create", "SQS queue %q", queueName)
+		if dest := o.Spec.Destination; dest != nil && dest.SQS != nil {
+			queue = change("use", "SQS queue %q", dest.SQS.QueueARN.String())
+		}
+		return []string{
+			queue,
+			change("create", "EventBridge rule on event bus %q", o.Spec.ARN.String()),
+			change("modify", "SQS queue access policy to allow the EventBridge rule"),
+		}, nil
+	case "AzureEventGridSource":
+		var o *sourcesv1alpha1.AzureEventGridSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return []string{
+			change("create", "Event Grid system topic for %q", o.Spec.Scope.String()),
+			eventHubChange(o.Spec.Endpoint.EventHubs),
+			change("create", "Event Grid subscription on the system topic"),
+		}, nil
+	case "AzureServiceBusTopicSource":
+		var o *sourcesv1alpha1.AzureServiceBusTopicSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return []string{
+			change("create", "Service Bus subscription on topic %q", o.Spec.TopicID.String()),
+		}, nil
+	case "AzureBlobStorageSource":
+		var o *sourcesv1alpha1.AzureBlobStorageSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return []string{
+			eventHubChange(o.Spec.Endpoint.EventHubs),
+			change("create", "Event Grid subscription on storage account %q", o.Spec.StorageAccountID.String()),
+		}, nil
+	case "GoogleCloudBillingSource":
+		var o *sourcesv1alpha1.GoogleCloudBillingSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return append(pubSubChanges(o.Spec.PubSub),
+			change("modify", "budget %q notifications of billing account %q", o.Spec.BudgetID, o.Spec.BillingAccountID),
+		), nil
+	case "GoogleCloudPubSubSource":
+		var o *sourcesv1alpha1.GoogleCloudPubSubSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return []string{
+			change("create", "Pub/Sub subscription to topic %q", o.Spec.Topic.String()),
+		}, nil
+	case "GoogleCloudAuditLogsSource":
+		var o *sourcesv1alpha1.GoogleCloudAuditLogsSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return append(pubSubChanges(o.Spec.PubSub),
+			change("create", "Cloud Logging sink for %q method %q", o.Spec.ServiceName, o.Spec.MethodName),
+		), nil
+	case "GoogleCloudStorageSource":
+		var o *sourcesv1alpha1.GoogleCloudStorageSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return append(pubSubChanges(o.Spec.PubSub),
+			change("create", "Cloud Storage bucket %q notification configuration", o.Spec.Bucket),
+		), nil
+	case "GoogleCloudSourceRepositoriesSource":
+		var o *sourcesv1alpha1.GoogleCloudSourceRepositoriesSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return append(pubSubChanges(o.Spec.PubSub),
+			change("modify", "Cloud Source repository %q topic configuration", o.Spec.Repository.String()),
+		), nil
+	case "AzureActivityLogsSource":
+		var o *sourcesv1alpha1.AzureActivityLogsSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return []string{
+			change("create", "diagnostic settings %q in subscription %q", azure.ActivityLogsDiagsName(o), o.Spec.SubscriptionID),
+		}, nil
+	case "AzureServiceBusQueueSource":
+		var o *sourcesv1alpha1.AzureServiceBusQueueSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		return []string{
+			change("create", "Service Bus queue %q if it does not exist", o.Spec.QueueID.String()),
+		}, nil
+	case "ZendeskSource":
+		return nil, fmt.Errorf("this component is multitenant and not suitable for local env")
+	}
+	return nil, nil
+}
+
+func change(action, format string, a ...interface{}) string {
+	return fmt.Sprintf("%-6s %s", action, fmt.Sprintf(format, a...))
+}
+
+func eventHubChange(dest sourcesv1alpha1.AzureEventGridSourceDestinationEventHubs) string {
+	if dest.HubName != nil {
+		return change("use", "Event Hub %q in namespace %q", *dest.HubName, dest.NamespaceID.String())
+	}
+	return change("create", "Event Hub in namespace %q", dest.NamespaceID.String())
+}
+
+func pubSubChanges(spec sourcesv1alpha1.GoogleCloudSourcePubSubSpec) []string {
+	topic := change("create", "Pub/Sub topic")
+	switch {
+	case spec.Topic != nil:
+		topic = change("use", "Pub/Sub topic %q", spec.Topic.String())
+	case spec.Project != nil:
+		topic = change("create", "Pub/Sub topic in project %q", *spec.Project)
+	}
+	return []string{topic, change("create", "Pub/Sub subscription to the topic")}
+}
+
+// Exists checks if the external resource recorded in the component status
+// under the given key still exists. Only read-only cloud APIs are called.
+func Exists(ctx context.Context, object unstructured.Unstructured, secrets map[string]string, key, value string) (bool, error) {
+	if object.GetAnnotations()[triggermesh.CredentialsAnnotation] == triggermesh.NativeCredentials {
+		// nil secrets make the clients use the default credentials chain
+		secrets = nil
+	}
+	endpoints, err := pkg.ParseEndpoints(object.GetAnnotations()[triggermesh.EndpointsAnnotation])
+	if err != nil {
+		return false, fmt.Errorf("endpoints annotation: %w", err)
+	}
+	switch key {
+	case "queueARN":
+		return aws.QueueExists(secrets, endpoints[pkg.EndpointAWS], value)
+	case "topic", "subscription":
+		return gcp.PubSubResourceExists(ctx, secrets, endpoints, value)
+	case "subscriptionID", "eventHubID", "diagnosticSettingsID", "queueID":
+		authorizer, err := azure.Client(secrets)
+		if err != nil {
+			return false, err
+		}
+		return azure.ResourceExists(ctx, authorizer, value)
+	}
+	return false, fmt.Errorf("unknown resource %q", key)
+}
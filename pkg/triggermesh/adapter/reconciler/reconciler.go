@@ -37,19 +37,29 @@ import (
 	tmgcprepo "github.com/triggermesh/triggermesh/pkg/sources/reconciler/googlecloudsourcerepositoriessource"
 	tmgcpstorage "github.com/triggermesh/triggermesh/pkg/sources/reconciler/googlecloudstoragesource"
 
+	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler/external/aws"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler/external/azure"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter/reconciler/external/gcp"
+	"github.com/triggermesh/tmctl/pkg/triggermesh/pkg"
 )
 
 func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructured, secrets map[string]string) (map[string]interface{}, error) {
+	if object.GetAnnotations()[triggermesh.CredentialsAnnotation] == triggermesh.NativeCredentials {
+		// nil secrets make the clients use the default credentials chain
+		secrets = nil
+	}
+	endpoints, err := pkg.ParseEndpoints(object.GetAnnotations()[triggermesh.EndpointsAnnotation])
+	if err != nil {
+		return nil, fmt.Errorf("endpoints annotation: %w", err)
+	}
 	switch object.GetKind() {
 	case "AWSS3Source":
 		var o *sourcesv1alpha1.AWSS3Source
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
 			return nil, err
 		}
-		s3Client, sqsClient, err := aws.S3Client(o, secrets)
+		s3Client, sqsClient, err := aws.S3Client(o, secrets, endpoints[pkg.EndpointAWS])
 		if err != nil {
 			return nil, err
 		}
@@ -65,7 +75,7 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 			return nil, err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		ebClient, sqsClient, err := aws.EBClient(o, secrets)
+		ebClient, sqsClient, err := aws.EBClient(o, secrets, endpoints[pkg.EndpointAWS])
 		if err != nil {
 			return nil, err
 		}
@@ -147,7 +157,7 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 			return nil, err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psClient, billingClient, err := gcp.CloudBillingClient(o, secrets)
+		psClient, billingClient, err := gcp.CloudBillingClient(o, secrets, endpoints)
 		if err != nil {
 			return nil, err
 		}
@@ -168,7 +178,7 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 			return nil, err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		client, err := gcp.PubSubClient(o, secrets)
+		client, err := gcp.PubSubClient(o, secrets, endpoints)
 		if err != nil {
 			return nil, err
 		}
@@ -182,7 +192,7 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 			return nil, err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psCli, laCli, err := gcp.AuditLogsClient(ctx, o, secrets)
+		psCli, laCli, err := gcp.AuditLogsClient(ctx, o, secrets, endpoints)
 		if err != nil {
 			return nil, err
 		}
@@ -197,7 +207,7 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 			return nil, err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psCli, stCli, err := gcp.StorageClient(ctx, o, secrets)
+		psCli, stCli, err := gcp.StorageClient(ctx, o, secrets, endpoints)
 		if err != nil {
 			return nil, err
 		}
@@ -213,7 +223,7 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 			return nil, err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psCli, repoCli, err := gcp.SourceRepoClient(ctx, o, secrets)
+		psCli, repoCli, err := gcp.SourceRepoClient(ctx, o, secrets, endpoints)
 		if err != nil {
 			return nil, err
 		}
@@ -228,7 +238,44 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 		return map[string]interface{}{"subscription": o.Status.Subscription.String()}, tmgcprepo.EnsureTopicAssociated(ctx, repoCli, topic, publishServiceAccount)
 
 	case "AzureActivityLogsSource":
-		return nil, fmt.Errorf("this component is not suitable for local env yet")
+		var o *sourcesv1alpha1.AzureActivityLogsSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		authorizer, err := azure.Client(secrets)
+		if err != nil {
+			return nil, err
+		}
+		eventCatCli, diagSettingsCli, err := azure.ActivityLogsClient(o, authorizer)
+		if err != nil {
+			return nil, err
+		}
+		diagSettingsID, err := azure.EnsureDiagnosticSettings(ctx, o, eventCatCli, diagSettingsCli)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"diagnosticSettingsID": diagSettingsID}, nil
+	case "AzureServiceBusQueueSource":
+		var o *sourcesv1alpha1.AzureServiceBusQueueSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return nil, err
+		}
+		authorizer, err := azure.Client(secrets)
+		if err != nil {
+			return nil, err
+		}
+		queueID, err := azure.EnsureQueue(ctx, o, azure.ServiceBusQueue(o, authorizer))
+		if err != nil {
+			return nil, err
+		}
+		if queueID == "" {
+			// the queue either existed before the source or was created on previous start
+			queueID = externalResource(object, "queueID")
+		}
+		if queueID == "" {
+			return nil, nil
+		}
+		return map[string]interface{}{"queueID": queueID}, nil
 	case "ZendeskSource":
 		return nil, fmt.Errorf("this component is multitenant and not suitable for local env")
 	}
@@ -236,6 +283,14 @@ func InitializeAndGetStatus(ctx context.Context, object unstructured.Unstructure
 }
 
 func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map[string]string) error {
+	if object.GetAnnotations()[triggermesh.CredentialsAnnotation] == triggermesh.NativeCredentials {
+		// nil secrets make the clients use the default credentials chain
+		secrets = nil
+	}
+	endpoints, err := pkg.ParseEndpoints(object.GetAnnotations()[triggermesh.EndpointsAnnotation])
+	if err != nil {
+		return fmt.Errorf("endpoints annotation: %w", err)
+	}
 	switch object.GetKind() {
 	case "AWSS3Source":
 		var o *sourcesv1alpha1.AWSS3Source
@@ -243,7 +298,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		s3Client, sqsClient, err := aws.S3Client(o, secrets)
+		s3Client, sqsClient, err := aws.S3Client(o, secrets, endpoints[pkg.EndpointAWS])
 		if err != nil {
 			return err
 		}
@@ -260,7 +315,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		ebClient, sqsClient, err := aws.EBClient(o, secrets)
+		ebClient, sqsClient, err := aws.EBClient(o, secrets, endpoints[pkg.EndpointAWS])
 		if err != nil {
 			return err
 		}
@@ -326,7 +381,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psClient, billingClient, err := gcp.CloudBillingClient(o, secrets)
+		psClient, billingClient, err := gcp.CloudBillingClient(o, secrets, endpoints)
 		if err != nil {
 			return err
 		}
@@ -340,7 +395,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		client, err := gcp.PubSubClient(o, secrets)
+		client, err := gcp.PubSubClient(o, secrets, endpoints)
 		if err != nil {
 			return err
 		}
@@ -354,7 +409,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psCli, laCli, err := gcp.AuditLogsClient(ctx, o, secrets)
+		psCli, laCli, err := gcp.AuditLogsClient(ctx, o, secrets, endpoints)
 		if err != nil {
 			return err
 		}
@@ -368,7 +423,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psCli, stCli, err := gcp.StorageClient(ctx, o, secrets)
+		psCli, stCli, err := gcp.StorageClient(ctx, o, secrets, endpoints)
 		if err != nil {
 			return err
 		}
@@ -382,7 +437,7 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 			return err
 		}
 		ctx = commonv1alpha1.WithReconcilable(ctx, o)
-		psCli, repoCli, err := gcp.SourceRepoClient(ctx, o, secrets)
+		psCli, repoCli, err := gcp.SourceRepoClient(ctx, o, secrets, endpoints)
 		if err != nil {
 			return err
 		}
@@ -392,9 +447,46 @@ func Finalize(ctx context.Context, object unstructured.Unstructured, secrets map
 		return tmgcprepo.EnsureNoPubSub(ctx, psCli)
 
 	case "AzureActivityLogsSource":
-		return fmt.Errorf("this component is not suitable for local env yet")
+		var o *sourcesv1alpha1.AzureActivityLogsSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return err
+		}
+		authorizer, err := azure.Client(secrets)
+		if err != nil {
+			return err
+		}
+		_, diagSettingsCli, err := azure.ActivityLogsClient(o, authorizer)
+		if err != nil {
+			return err
+		}
+		return azure.EnsureNoDiagnosticSettings(ctx, o, diagSettingsCli)
+	case "AzureServiceBusQueueSource":
+		if externalResource(object, "queueID") == "" {
+			// queue was not created by the source
+			return nil
+		}
+		var o *sourcesv1alpha1.AzureServiceBusQueueSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &o); err != nil {
+			return err
+		}
+		authorizer, err := azure.Client(secrets)
+		if err != nil {
+			return err
+		}
+		return azure.EnsureNoQueue(ctx, o, azure.ServiceBusQueue(o, authorizer))
 	case "ZendeskSource":
 		return fmt.Errorf("this component is multitenant and not suitable for local env")
 	}
 	return nil
 }
+
+// externalResource returns the value recorded under the key
+// in the object's external resources annotation.
+func externalResource(object unstructured.Unstructured, key string) string {
+	for _, resource := range strings.Split(object.GetAnnotations()[triggermesh.ExternalResourcesAnnotation], ",") {
+		if entry := strings.Split(resource, "="); len(entry) == 2 && entry[0] == key {
+			return entry[1]
+		}
+	}
+	return ""
+}
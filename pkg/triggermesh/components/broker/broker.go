@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +33,7 @@ import (
 	"github.com/digitalocean/godo"
 	"github.com/triggermesh/tmctl/pkg/config"
 	"github.com/triggermesh/tmctl/pkg/docker"
+	"github.com/triggermesh/tmctl/pkg/file"
 	"github.com/triggermesh/tmctl/pkg/kubernetes"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/adapter"
@@ -52,7 +54,8 @@ const (
 )
 
 type Broker struct {
-	Name string
+	Name       string
+	ConfigBase string
 
 	image      string
 	entrypoint []string
@@ -98,8 +101,8 @@ func (b *Broker) AsDockerComposeObject(additionalEnvs map[string]string) (interf
 
 func (b *Broker) AsDigitalOceanObject(additionalEnvs map[string]string) (interface{}, error) {
 	// Get the image and tag
-	imageSplit := strings.Split(b.image, "/")[2]
-	image := strings.Split(imageSplit, ":")
+	imageSplit := strings.Split(b.image, "/")
+	image := strings.Split(imageSplit[len(imageSplit)-1], ":")
 
 	var env []*godo.AppVariableDefinition
 	for k, v := range additionalEnvs {
@@ -147,9 +150,14 @@ func (b *Broker) asContainer(additionalEnvs map[string]string) (*docker.Containe
 
 	co = append(co, docker.WithEntrypoint(b.entrypoint))
 
-	bind := fmt.Sprintf("%s:/etc/triggermesh/broker.conf",
-		filepath.Join(config.HomeAbsPath(), b.Name, triggermesh.BrokerConfigFile))
+	// only the configuration directory is mounted, file bind would
+	// keep the replaced file inode and miss the updates
+	bind := fmt.Sprintf("%s:/etc/triggermesh:ro", filepath.Join(b.ConfigBase, b.Name, triggermesh.BrokerConfigDir))
 	ho = append(ho, docker.WithVolumeBind(bind))
+	if runtime.GOOS != "windows" {
+		// private configuration files are readable by the owner only
+		co = append(co, docker.WithUser(fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())))
+	}
 
 	name := o.GetName()
 	if !strings.HasSuffix(name, "-broker") {
@@ -167,6 +175,10 @@ func (b *Broker) GetKind() string {
 	return BrokerKind
 }
 
+func (b *Broker) GetImage() string {
+	return b.image
+}
+
 func (b *Broker) GetName() string {
 	return b.Name
 }
@@ -249,32 +261,25 @@ func (b *Broker) Logs(ctx context.Context, since time.Time, follow bool) (io.Rea
 func CreateBrokerConfig(configHome, broker string) (string, error) {
 	brokerHome := filepath.Join(configHome, broker)
 	manifestFile := filepath.Join(brokerHome, triggermesh.ManifestFile)
-	// create config folder
-	if err := os.MkdirAll(brokerHome, os.ModePerm); err != nil {
+	// create config folders
+	if err := os.MkdirAll(filepath.Join(brokerHome, triggermesh.BrokerConfigDir), file.DirPerm); err != nil {
 		return "", fmt.Errorf("broker dir creation: %w", err)
 	}
 	// create empty manifest
-	if _, err := os.Stat(manifestFile); os.IsNotExist(err) {
-		if _, err := os.Create(manifestFile); err != nil {
-			return "", fmt.Errorf("manifest file creation: %w", err)
-		}
-	} else if err != nil {
-		return "", fmt.Errorf("manifest file access: %w", err)
+	if err := file.Create(manifestFile, file.PrivatePerm); err != nil {
+		return "", fmt.Errorf("manifest file creation: %w", err)
 	}
 	brokerConfigPath := filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
-	if _, err := os.Stat(brokerConfigPath); os.IsNotExist(err) {
-		if _, err := os.Create(brokerConfigPath); err != nil {
-			return "", fmt.Errorf("creating broker config: %w", err)
-		}
-	} else if err != nil {
-		return "", fmt.Errorf("broker config read: %w", err)
+	if err := file.Create(brokerConfigPath, file.PrivatePerm); err != nil {
+		return "", fmt.Errorf("creating broker config: %w", err)
 	}
 	return brokerConfigPath, nil
 }
 
-func New(name string, brokerConfig config.BrokerConfig) (triggermesh.Component, error) {
+func New(name, configBase string, brokerConfig config.BrokerConfig) (triggermesh.Component, error) {
 	return &Broker{
-		Name: name,
+		Name:       name,
+		ConfigBase: configBase,
 
 		image:      image(brokerConfig),
 		entrypoint: brokerEntrypoint(brokerConfig),
@@ -284,9 +289,9 @@ func New(name string, brokerConfig config.BrokerConfig) (triggermesh.Component,
 func image(c config.BrokerConfig) string {
 	switch {
 	case c.Memory != nil:
-		return config.MemoryBrokerImage + ":" + c.Version
+		return docker.MirrorImage(config.MemoryBrokerImage + ":" + c.Version)
 	case c.Redis != nil:
-		return config.RedisBrokerImage + ":" + c.Version
+		return docker.MirrorImage(config.RedisBrokerImage + ":" + c.Version)
 	}
 	return ""
 }
@@ -25,6 +25,8 @@ import (
 
 	eventingbroker "github.com/triggermesh/brokers/pkg/config/broker"
 
+	"github.com/triggermesh/tmctl/pkg/file"
+	"github.com/triggermesh/tmctl/pkg/history"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 )
 
@@ -52,16 +54,56 @@ func readBrokerConfig(path string) (Configuration, error) {
 	return config, yaml.Unmarshal(data, &config)
 }
 
-func writeBrokerConfig(path string, configuration *Configuration) error {
+// writeBrokerConfig replaces the configuration file atomically, the broker
+// container mounts the configuration directory and sees the renamed file.
+func writeBrokerConfig(brokerHome string, configuration *Configuration) error {
 	out, err := yaml.Marshal(configuration)
 	if err != nil {
 		return fmt.Errorf("marshal broker configuration: %w", err)
 	}
-	return os.WriteFile(path, out, os.ModePerm)
+	return history.Record(brokerHome, func() error {
+		return file.WriteAtomic(filepath.Join(brokerHome, triggermesh.BrokerConfigFile), out, file.PrivatePerm)
+	})
+}
+
+// RestoreConfig writes the saved broker configuration back.
+func RestoreConfig(brokerHome string, data []byte) error {
+	path := filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
+	unlock, err := file.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return history.Record(brokerHome, func() error {
+		return file.WriteAtomic(path, data, file.PrivatePerm)
+	})
+}
+
+// MigrateConfig moves the configuration file created by the previous
+// CLI versions in the broker directory to the configuration directory.
+func MigrateConfig(brokerHome string) error {
+	legacy := filepath.Join(brokerHome, filepath.Base(triggermesh.BrokerConfigFile))
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	path := filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), file.DirPerm); err != nil {
+		return err
+	}
+	return os.Rename(legacy, path)
 }
 
 func (t *Trigger) WriteLocalConfig() error {
-	configFile := filepath.Join(t.ConfigBase, t.Broker.Name, triggermesh.BrokerConfigFile)
+	brokerHome := filepath.Join(t.ConfigBase, t.Broker.Name)
+	configFile := filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
+	unlock, err := file.Lock(configFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	configuration, err := readBrokerConfig(configFile)
 	if err != nil {
 		return fmt.Errorf("broker config: %w", err)
@@ -87,17 +129,23 @@ func (t *Trigger) WriteLocalConfig() error {
 			},
 		}
 	}
-	return writeBrokerConfig(configFile, &configuration)
+	return writeBrokerConfig(brokerHome, &configuration)
 }
 
 func (t *Trigger) RemoveFromLocalConfig() error {
-	configFile := filepath.Join(t.ConfigBase, t.Broker.Name, triggermesh.BrokerConfigFile)
+	brokerHome := filepath.Join(t.ConfigBase, t.Broker.Name)
+	configFile := filepath.Join(brokerHome, triggermesh.BrokerConfigFile)
+	unlock, err := file.Lock(configFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	configuration, err := readBrokerConfig(configFile)
 	if err != nil {
 		return fmt.Errorf("broker config: %w", err)
 	}
 	delete(configuration.Triggers, t.Name)
-	return writeBrokerConfig(configFile, &configuration)
+	return writeBrokerConfig(brokerHome, &configuration)
 }
 
 func GetTargetTriggers(target, broker, configBase string) ([]triggermesh.Component, error) {
@@ -27,7 +27,9 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"github.com/triggermesh/tmctl/pkg/config"
+	"github.com/triggermesh/tmctl/pkg/docker"
 	"github.com/triggermesh/tmctl/pkg/manifest"
+	"github.com/triggermesh/tmctl/pkg/secrets"
 	"github.com/triggermesh/tmctl/pkg/triggermesh"
 	tmbroker "github.com/triggermesh/tmctl/pkg/triggermesh/components/broker"
 	"github.com/triggermesh/tmctl/pkg/triggermesh/components/secret"
@@ -47,6 +49,14 @@ func GetObject(name string, config *config.Config, manifest *manifest.Manifest,
 		if !set {
 			return nil, fmt.Errorf("context label not set")
 		}
+		version := config.Triggermesh.ComponentsVersion
+		if pinned, set := object.Metadata.Annotations[triggermesh.VersionAnnotation]; set && pinned != version {
+			pinnedCRDs, err := crd.Fetch(config.CacheHome, pinned)
+			if err != nil {
+				return nil, fmt.Errorf("%s CRD: %w", pinned, err)
+			}
+			crds, version = pinnedCRDs, pinned
+		}
 		crd := crds[strings.ToLower(object.Kind)]
 		switch object.APIVersion {
 		case "sources.triggermesh.io/v1alpha1":
@@ -60,15 +70,18 @@ func GetObject(name string, config *config.Config, manifest *manifest.Manifest,
 					}
 				}
 			}
-			return source.New(object.Metadata.Name, object.Kind, broker, config.Triggermesh.ComponentsVersion, crd, object.Spec, status), nil
+			s := source.New(object.Metadata.Name, object.Kind, broker, version, crd, object.Spec, status)
+			return Annotate(s, object.Metadata.Annotations), nil
 		case "targets.triggermesh.io/v1alpha1":
-			return target.New(object.Metadata.Name, object.Kind, broker, config.Triggermesh.ComponentsVersion, crd, object.Spec), nil
+			t := target.New(object.Metadata.Name, object.Kind, broker, version, crd, object.Spec)
+			return Annotate(t, object.Metadata.Annotations), nil
 		case "flow.triggermesh.io/v1alpha1":
-			return transformation.New(object.Metadata.Name, object.Kind, broker, config.Triggermesh.ComponentsVersion, crd, object.Spec), nil
+			t := transformation.New(object.Metadata.Name, object.Kind, broker, version, crd, object.Spec)
+			return Annotate(t, object.Metadata.Annotations), nil
 		case "eventing.triggermesh.io/v1alpha1":
 			switch object.Kind {
 			case "RedisBroker":
-				return tmbroker.New(object.Metadata.Name, config.Triggermesh.Broker)
+				return tmbroker.New(object.Metadata.Name, filepath.Dir(filepath.Dir(manifest.Path)), config.Triggermesh.Broker)
 			case "Trigger":
 				brokerConfigPath := filepath.Dir(manifest.Path)
 				baseConfigPath := filepath.Dir(brokerConfigPath)
@@ -112,7 +125,8 @@ func GetObject(name string, config *config.Config, manifest *manifest.Manifest,
 					params[name.(string)] = value.(string)
 				}
 			}
-			return service.New(name, image, broker, service.Role(role), params), nil
+			s := service.New(name, image, broker, service.Role(role), params)
+			return Annotate(s, object.Metadata.Annotations), nil
 		case "v1":
 			if object.Kind == "Secret" {
 				return secret.New(object.Metadata.Name, broker, object.Data), nil
@@ -122,6 +136,43 @@ func GetObject(name string, config *config.Config, manifest *manifest.Manifest,
 	return nil, nil
 }
 
+// Annotate sets the annotations on the component if it supports them.
+func Annotate(c triggermesh.Component, annotations map[string]string) triggermesh.Component {
+	if a, ok := c.(triggermesh.Annotated); ok {
+		for k, v := range annotations {
+			a.SetAnnotation(k, v)
+		}
+	}
+	return c
+}
+
+// PinHostPort persists the host port of the running container in the
+// component annotations, so that the port stays the same after restarts.
+func PinHostPort(c triggermesh.Component, container *docker.Container, manifest *manifest.Manifest) error {
+	a, ok := c.(triggermesh.Annotated)
+	if !ok || container == nil {
+		return nil
+	}
+	if _, set := a.GetAnnotations()[triggermesh.HostPortAnnotation]; set {
+		return nil
+	}
+	port := container.HostPort()
+	if port == "" {
+		return nil
+	}
+	a.SetAnnotation(triggermesh.HostPortAnnotation, port)
+	// only the annotation is persisted, the component spec may
+	// contain the runtime values that must not be written
+	object, err := c.AsK8sObject()
+	if err != nil {
+		return fmt.Errorf("creating k8s object: %w", err)
+	}
+	if err := manifest.SetAnnotation(object.Kind, object.Metadata.Name, triggermesh.HostPortAnnotation, port); err != nil {
+		return fmt.Errorf("persisting host port: %w", err)
+	}
+	return nil
+}
+
 func ProcessSecrets(p triggermesh.Parent, manifest *manifest.Manifest) ([]triggermesh.Component, map[string]string, error) {
 	secrets := readSecrets(p, manifest)
 	plainSecretsEnv, err := decodeSecrets(secrets)
@@ -148,10 +199,21 @@ func readSecrets(p triggermesh.Parent, manifest *manifest.Manifest) []triggermes
 	return secrets
 }
 
-func decodeSecrets(secrets []triggermesh.Component) (map[string]string, error) {
+func decodeSecrets(components []triggermesh.Component) (map[string]string, error) {
 	result := make(map[string]string)
-	for _, secret := range secrets {
+	for _, secret := range components {
 		for k, v := range secret.GetSpec() {
+			if secrets.IsEncrypted(v.(string)) {
+				return nil, fmt.Errorf("%s: %w", secret.GetName(), secrets.ErrNoKey)
+			}
+			if secrets.IsReference(v.(string)) {
+				plainValue, err := secrets.Resolve(v.(string))
+				if err != nil {
+					return nil, fmt.Errorf("resolving secret reference: %w", err)
+				}
+				result[k] = plainValue
+				continue
+			}
 			plainValue, err := base64.StdEncoding.DecodeString(v.(string))
 			if err != nil {
 				return nil, fmt.Errorf("decoding secret value: %w", err)
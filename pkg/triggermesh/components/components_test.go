@@ -31,7 +31,7 @@ import (
 const version = "v1.21.1"
 
 func TestGetObject(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 	c := &config.Config{
 		Triggermesh: config.TmConfig{ComponentsVersion: version},
@@ -53,7 +53,7 @@ func TestGetObject(t *testing.T) {
 }
 
 func TestProcessSecrets(t *testing.T) {
-	m := manifest.New(test.Manifest())
+	m := manifest.New(test.Manifest(t))
 	assert.NoError(t, m.Read())
 
 	specs := map[string]map[string]string{
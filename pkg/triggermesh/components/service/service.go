@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 	"time"
 
@@ -52,6 +51,7 @@ var (
 	_ triggermesh.Producer   = (*Service)(nil)
 	_ triggermesh.Runnable   = (*Service)(nil)
 	_ triggermesh.Exportable = (*Service)(nil)
+	_ triggermesh.Annotated  = (*Service)(nil)
 )
 
 type Role string
@@ -61,8 +61,9 @@ type Service struct {
 	Broker string
 	Image  string
 
-	role   Role
-	params map[string]string
+	role        Role
+	params      map[string]string
+	annotations map[string]string
 }
 
 func (s *Service) asUnstructured() (unstructured.Unstructured, error) {
@@ -71,6 +72,7 @@ func (s *Service) asUnstructured() (unstructured.Unstructured, error) {
 	u.SetKind(Kind)
 	u.SetName(s.Name)
 	u.SetNamespace(triggermesh.Namespace)
+	u.SetAnnotations(s.annotations)
 	return u, unstructured.SetNestedField(u.Object, kserviceSpec(s.Image, s.params), "spec")
 }
 
@@ -92,6 +94,7 @@ func (s *Service) AsK8sObject() (kubernetes.Object, error) {
 				triggermesh.ContextLabel: s.Broker,
 				RoleLabel:                string(s.role),
 			},
+			Annotations: s.annotations,
 		},
 		Spec: kserviceSpec(s.Image, manifestParams),
 	}, nil
@@ -103,12 +106,18 @@ func (s *Service) AsDockerComposeObject(additionalEnvs map[string]string) (inter
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return &docker.ComposeService{
+	compose := &docker.ComposeService{
 		ContainerName: s.Name,
 		Image:         s.Image,
 		Environment:   pkg.EnvsToString(envs),
-		Ports:         []string{strconv.Itoa(pkg.OpenPort()) + ":8080"},
-	}, nil
+		Ports:         adapter.ComposePorts(s.annotations),
+	}
+	overrides, err := adapter.ParseOverrides(s.annotations)
+	if err != nil {
+		return nil, err
+	}
+	overrides.ApplyToComposeService(compose)
+	return compose, nil
 }
 
 func (s *Service) AsDigitalOceanObject(additionalEnvs map[string]string) (interface{}, error) {
@@ -132,6 +141,12 @@ func (s *Service) AsDigitalOceanObject(additionalEnvs map[string]string) (interf
 		registry = imageSplit[1]
 	}
 
+	overrides, err := adapter.ParseOverrides(s.annotations)
+	if err != nil {
+		return nil, err
+	}
+	envs = overrides.MergeDigitalOceanEnv(envs)
+
 	return godo.AppServiceSpec{
 		Name: s.Name,
 		Image: &godo.ImageSourceSpec{
@@ -152,7 +167,11 @@ func (s *Service) AsKubernetesDeployment(additionalEnvs map[string]string) (inte
 	for k, v := range additionalEnvs {
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
-	return kubernetes.CreateDeployment(s.Name, s.Image, envs), nil
+	overrides, err := adapter.ParseOverrides(s.annotations)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.ApplyToDeployment(kubernetes.CreateDeployment(s.Name, s.Image, envs)), nil
 }
 
 func (s *Service) asContainer(additionalEnvs map[string]string) (*docker.Container, error) {
@@ -179,6 +198,10 @@ func (s *Service) GetKind() string {
 	return Kind
 }
 
+func (s *Service) GetImage() string {
+	return s.Image
+}
+
 func (s *Service) GetName() string {
 	return s.Name
 }
@@ -201,6 +224,17 @@ func (s *Service) SetSpec(spec map[string]interface{}) {
 	}
 }
 
+func (s *Service) GetAnnotations() map[string]string {
+	return s.annotations
+}
+
+func (s *Service) SetAnnotation(key, value string) {
+	if s.annotations == nil {
+		s.annotations = make(map[string]string, 1)
+	}
+	s.annotations[key] = value
+}
+
 func (s *Service) IsSource() bool {
 	return s.role == Producer
 }
@@ -48,6 +48,7 @@ var (
 	_ triggermesh.Runnable     = (*Source)(nil)
 	_ triggermesh.Parent       = (*Source)(nil)
 	_ triggermesh.Exportable   = (*Source)(nil)
+	_ triggermesh.Annotated    = (*Source)(nil)
 )
 
 type Source struct {
@@ -59,8 +60,9 @@ type Source struct {
 	Kind    string
 	Version string
 
-	spec   map[string]interface{}
-	status map[string]interface{}
+	spec        map[string]interface{}
+	status      map[string]interface{}
+	annotations map[string]string
 }
 
 func (s *Source) asUnstructured() (unstructured.Unstructured, error) {
@@ -111,7 +113,11 @@ func (s *Source) AsKubernetesDeployment(additionalEnvs map[string]string) (inter
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return kubernetes.CreateDeployment(s.Name, image, envs), nil
+	overrides, err := adapter.ParseOverrides(s.annotations)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.ApplyToDeployment(kubernetes.CreateDeployment(s.Name, image, envs)), nil
 }
 
 func (s *Source) AsDockerComposeObject(additionalEnvs map[string]string) (interface{}, error) {
@@ -144,12 +150,18 @@ func (s *Source) AsDockerComposeObject(additionalEnvs map[string]string) (interf
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return &docker.ComposeService{
+	compose := &docker.ComposeService{
 		ContainerName: s.Name,
 		Image:         image,
 		Environment:   pkg.EnvsToString(envs),
-		Ports:         []string{"8080"},
-	}, nil
+		Ports:         adapter.ComposePorts(s.annotations),
+	}
+	overrides, err := adapter.ParseOverrides(s.annotations)
+	if err != nil {
+		return nil, err
+	}
+	overrides.ApplyToComposeService(compose)
+	return compose, nil
 }
 
 func (s *Source) AsDigitalOceanObject(additionalEnvs map[string]string) (interface{}, error) {
@@ -186,6 +198,12 @@ func (s *Source) AsDigitalOceanObject(additionalEnvs map[string]string) (interfa
 	imageURI := strings.Split(adapter.Image(o, ""), "/")
 	adapterName := strings.TrimRight(imageURI[len(imageURI)-1], ":")
 
+	overrides, err := adapter.ParseOverrides(s.annotations)
+	if err != nil {
+		return nil, err
+	}
+	envs = overrides.MergeDigitalOceanEnv(envs)
+
 	return godo.AppWorkerSpec{
 		Name: s.Name,
 		Image: &godo.ImageSourceSpec{
@@ -207,7 +225,10 @@ func (s *Source) getMeta() kubernetes.Metadata {
 		Labels: map[string]string{
 			triggermesh.ContextLabel: s.Broker,
 		},
-		Annotations: make(map[string]string, 0),
+		Annotations: make(map[string]string, len(s.annotations)),
+	}
+	for k, v := range s.annotations {
+		meta.Annotations[k] = v
 	}
 	var externalResources []string
 	for k, v := range s.status {
@@ -245,6 +266,12 @@ func (s *Source) GetKind() string {
 	return s.Kind
 }
 
+func (s *Source) GetImage() string {
+	o := unstructured.Unstructured{}
+	o.SetKind(s.Kind)
+	return adapter.Image(o, s.Version)
+}
+
 func (s *Source) GetAPIVersion() string {
 	o, err := s.AsK8sObject()
 	if err != nil {
@@ -261,6 +288,26 @@ func (s *Source) SetSpec(spec map[string]interface{}) {
 	s.spec = spec
 }
 
+func (s *Source) GetAnnotations() map[string]string {
+	return s.annotations
+}
+
+// SetAnnotation sets the object annotation. External resources annotation
+// is managed by the source status and cannot be set directly, version
+// annotation pins the adapter version of the source.
+func (s *Source) SetAnnotation(key, value string) {
+	if key == triggermesh.ExternalResourcesAnnotation {
+		return
+	}
+	if key == triggermesh.VersionAnnotation {
+		s.Version = value
+	}
+	if s.annotations == nil {
+		s.annotations = make(map[string]string, 1)
+	}
+	s.annotations[key] = value
+}
+
 func (s *Source) GetEventTypes() ([]string, error) {
 	// try GetEventTypes method first
 	o, err := s.asUnstructured()
@@ -387,6 +434,22 @@ func (s *Source) Finalize(ctx context.Context, secrets map[string]string) error
 	return adapter.Finalize(ctx, u, secrets)
 }
 
+func (s *Source) Plan() ([]string, error) {
+	u, err := s.asUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	return adapter.Plan(u)
+}
+
+func (s *Source) ExternalResourceExists(ctx context.Context, secrets map[string]string, key string) (bool, error) {
+	u, err := s.asUnstructured()
+	if err != nil {
+		return false, err
+	}
+	return adapter.ExternalResourceExists(ctx, u, secrets, key, fmt.Sprint(s.status[key]))
+}
+
 func (s *Source) UpdateStatus(status map[string]interface{}) {
 	s.status = status
 }
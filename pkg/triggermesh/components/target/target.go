@@ -21,7 +21,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +46,7 @@ var (
 	_ triggermesh.Runnable   = (*Target)(nil)
 	_ triggermesh.Parent     = (*Target)(nil)
 	_ triggermesh.Exportable = (*Target)(nil)
+	_ triggermesh.Annotated  = (*Target)(nil)
 )
 
 type Target struct {
@@ -58,7 +58,8 @@ type Target struct {
 	Version string
 	Kind    string
 
-	spec map[string]interface{}
+	spec        map[string]interface{}
+	annotations map[string]string
 }
 
 func (t *Target) asUnstructured() (unstructured.Unstructured, error) {
@@ -76,6 +77,7 @@ func (t *Target) getMeta() kubernetes.Metadata {
 		Labels: map[string]string{
 			triggermesh.ContextLabel: t.Broker,
 		},
+		Annotations: t.annotations,
 	}
 }
 
@@ -107,12 +109,18 @@ func (t *Target) AsDockerComposeObject(additionalEnvs map[string]string) (interf
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return &docker.ComposeService{
+	compose := &docker.ComposeService{
 		ContainerName: t.Name,
 		Image:         image,
 		Environment:   pkg.EnvsToString(envs),
-		Ports:         []string{strconv.Itoa(pkg.OpenPort()) + ":8080"},
-	}, nil
+		Ports:         adapter.ComposePorts(t.annotations),
+	}
+	overrides, err := adapter.ParseOverrides(t.annotations)
+	if err != nil {
+		return nil, err
+	}
+	overrides.ApplyToComposeService(compose)
+	return compose, nil
 }
 
 func (t *Target) AsDigitalOceanObject(additionalEnvs map[string]string) (interface{}, error) {
@@ -146,6 +154,12 @@ func (t *Target) AsDigitalOceanObject(additionalEnvs map[string]string) (interfa
 	imageURI := strings.Split(adapter.Image(o, ""), "/")
 	adapterName := strings.TrimRight(imageURI[len(imageURI)-1], ":")
 
+	overrides, err := adapter.ParseOverrides(t.annotations)
+	if err != nil {
+		return nil, err
+	}
+	envs = overrides.MergeDigitalOceanEnv(envs)
+
 	return godo.AppServiceSpec{
 		Name: t.Name,
 		Image: &godo.ImageSourceSpec{
@@ -188,7 +202,11 @@ func (t *Target) AsKubernetesDeployment(additionalEnvs map[string]string) (inter
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return kubernetes.CreateDeployment(t.Name, image, envs), nil
+	overrides, err := adapter.ParseOverrides(t.annotations)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.ApplyToDeployment(kubernetes.CreateDeployment(t.Name, image, envs)), nil
 }
 
 func (t *Target) asContainer(additionalEnvs map[string]string) (*docker.Container, error) {
@@ -217,6 +235,12 @@ func (t *Target) GetKind() string {
 	return t.Kind
 }
 
+func (t *Target) GetImage() string {
+	o := unstructured.Unstructured{}
+	o.SetKind(t.Kind)
+	return adapter.Image(o, t.Version)
+}
+
 func (t *Target) GetAPIVersion() string {
 	o, err := t.AsK8sObject()
 	if err != nil {
@@ -233,6 +257,22 @@ func (t *Target) SetSpec(spec map[string]interface{}) {
 	t.spec = spec
 }
 
+func (t *Target) GetAnnotations() map[string]string {
+	return t.annotations
+}
+
+// SetAnnotation sets the object annotation. Version annotation
+// pins the adapter version of the target.
+func (t *Target) SetAnnotation(key, value string) {
+	if key == triggermesh.VersionAnnotation {
+		t.Version = value
+	}
+	if t.annotations == nil {
+		t.annotations = make(map[string]string, 1)
+	}
+	t.annotations[key] = value
+}
+
 func (t *Target) GetPort(ctx context.Context) (string, error) {
 	container, err := t.Info(ctx)
 	if err != nil {
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +46,7 @@ var (
 	_ triggermesh.Producer   = (*Transformation)(nil)
 	_ triggermesh.Runnable   = (*Transformation)(nil)
 	_ triggermesh.Exportable = (*Transformation)(nil)
+	_ triggermesh.Annotated  = (*Transformation)(nil)
 )
 
 type Transformation struct {
@@ -55,8 +55,9 @@ type Transformation struct {
 	Broker  string
 	Version string
 
-	spec   map[string]interface{}
-	labels map[string]string
+	spec        map[string]interface{}
+	labels      map[string]string
+	annotations map[string]string
 }
 
 func (t *Transformation) asUnstructured() (unstructured.Unstructured, error) {
@@ -69,9 +70,10 @@ func (t *Transformation) AsK8sObject() (kubernetes.Object, error) {
 
 func (t *Transformation) getMeta() kubernetes.Metadata {
 	return kubernetes.Metadata{
-		Name:      t.GetName(),
-		Namespace: triggermesh.Namespace,
-		Labels:    t.labels,
+		Name:        t.GetName(),
+		Namespace:   triggermesh.Namespace,
+		Labels:      t.labels,
+		Annotations: t.annotations,
 	}
 }
 
@@ -103,12 +105,18 @@ func (t *Transformation) AsDockerComposeObject(additionalEnvs map[string]string)
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return &docker.ComposeService{
+	compose := &docker.ComposeService{
 		ContainerName: t.Name,
 		Image:         image,
 		Environment:   pkg.EnvsToString(envs),
-		Ports:         []string{strconv.Itoa(pkg.OpenPort()) + ":8080"},
-	}, nil
+		Ports:         adapter.ComposePorts(t.annotations),
+	}
+	overrides, err := adapter.ParseOverrides(t.annotations)
+	if err != nil {
+		return nil, err
+	}
+	overrides.ApplyToComposeService(compose)
+	return compose, nil
 }
 
 func (t *Transformation) AsDigitalOceanObject(additionalEnvs map[string]string) (interface{}, error) {
@@ -142,6 +150,12 @@ func (t *Transformation) AsDigitalOceanObject(additionalEnvs map[string]string)
 	imageURI := strings.Split(adapter.Image(o, ""), "/")
 	adapterName := strings.TrimRight(imageURI[len(imageURI)-1], ":")
 
+	overrides, err := adapter.ParseOverrides(t.annotations)
+	if err != nil {
+		return nil, err
+	}
+	envs = overrides.MergeDigitalOceanEnv(envs)
+
 	return godo.AppServiceSpec{
 		Name: t.Name,
 		Image: &godo.ImageSourceSpec{
@@ -184,7 +198,11 @@ func (t *Transformation) AsKubernetesDeployment(additionalEnvs map[string]string
 		envs = append(envs, corev1.EnvVar{Name: k, Value: v})
 	}
 
-	return kubernetes.CreateDeployment(t.Name, image, envs), nil
+	overrides, err := adapter.ParseOverrides(t.annotations)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.ApplyToDeployment(kubernetes.CreateDeployment(t.Name, image, envs)), nil
 }
 
 func (t *Transformation) asContainer(additionalEnvs map[string]string) (*docker.Container, error) {
@@ -213,6 +231,12 @@ func (t *Transformation) GetKind() string {
 	return "transformation"
 }
 
+func (t *Transformation) GetImage() string {
+	o := unstructured.Unstructured{}
+	o.SetKind(t.GetKind())
+	return adapter.Image(o, t.Version)
+}
+
 func (t *Transformation) GetAPIVersion() string {
 	o, err := t.AsK8sObject()
 	if err != nil {
@@ -389,3 +413,19 @@ func (t *Transformation) getContextTransformationValue(key string) []string {
 func (t *Transformation) SetLabel(key, value string) {
 	t.labels[key] = value
 }
+
+func (t *Transformation) GetAnnotations() map[string]string {
+	return t.annotations
+}
+
+// SetAnnotation sets the object annotation. Version annotation
+// pins the adapter version of the transformation.
+func (t *Transformation) SetAnnotation(key, value string) {
+	if key == triggermesh.VersionAnnotation {
+		t.Version = value
+	}
+	if t.annotations == nil {
+		t.annotations = make(map[string]string, 1)
+	}
+	t.annotations[key] = value
+}
@@ -18,13 +18,40 @@ package triggermesh
 
 // TriggerMesh constant values used as default paths, configs, etc.
 const (
-	Namespace        = "local"
-	ManifestFile     = "manifest.yaml"
-	BrokerConfigFile = "broker.conf"
+	Namespace    = "local"
+	ManifestFile = "manifest.yaml"
+	// BrokerConfigDir is the broker directory with the configuration
+	// file, the only directory mounted into the broker container.
+	BrokerConfigDir  = "conf"
+	BrokerConfigFile = BrokerConfigDir + "/broker.conf"
 
 	UserInputTag = "<user_input>"
 
 	// objects meta
 	ContextLabel                = "triggermesh.io/context"
 	ExternalResourcesAnnotation = "triggermesh.io/external-resources"
+	RestartPolicyAnnotation     = "triggermesh.io/restart-policy"
+	HostPortAnnotation          = "triggermesh.io/host-port"
+	ResourcesAnnotation         = "triggermesh.io/resources"
+	EnvAnnotation               = "triggermesh.io/env"
+	MountsAnnotation            = "triggermesh.io/mounts"
+	VersionAnnotation           = "triggermesh.io/version"
+	CredentialsAnnotation       = "triggermesh.io/credentials"
+	EndpointsAnnotation         = "triggermesh.io/endpoints"
+
+	// NativeCredentials is the credentials annotation value that makes
+	// the component use the developer cloud credentials chain.
+	NativeCredentials = "native"
 )
+
+// LocalRuntimeAnnotations configure the local containers of the components,
+// they are not exported to the cluster manifests.
+var LocalRuntimeAnnotations = []string{
+	RestartPolicyAnnotation,
+	HostPortAnnotation,
+	ResourcesAnnotation,
+	EnvAnnotation,
+	MountsAnnotation,
+	CredentialsAnnotation,
+	EndpointsAnnotation,
+}
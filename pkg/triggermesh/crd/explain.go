@@ -0,0 +1,128 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Field is the documented spec field.
+type Field struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	// Secret is true for the secret references, their values
+	// are passed as plain strings and stored in the secret.
+	Secret  bool
+	Enum    []string
+	Default string
+	Fields  []Field
+}
+
+// Explain returns the spec field with the nested fields for requested schema path.
+func (s *Schema) Explain(path ...string) (Field, error) {
+	field := newField("spec", s.schema, false)
+	schema := s.schema
+	for i, key := range path {
+		if key == "" {
+			continue
+		}
+		if schema.Items != nil && schema.Items.Schema != nil {
+			schema = *schema.Items.Schema
+		}
+		nested, exists := schema.Properties[key]
+		if !exists || field.Secret {
+			return Field{}, fmt.Errorf("field %q does not exist, available fields are: %s",
+				strings.Join(append([]string{"spec"}, path[:i+1]...), "."), propertyKeysAsString(schema.Properties))
+		}
+		field = newField(key, nested, isRequired(schema, key))
+		schema = nested
+	}
+	return field, nil
+}
+
+func newField(name string, schema spec.Schema, required bool) Field {
+	field := Field{
+		Name:        name,
+		Type:        schemaType(schema),
+		Description: strings.TrimSpace(schema.Description),
+		Required:    required,
+	}
+	for _, value := range schema.Enum {
+		field.Enum = append(field.Enum, fmt.Sprintf("%v", value))
+	}
+	if schema.Default != nil {
+		field.Default = fmt.Sprintf("%v", schema.Default)
+		if _, scalar := schema.Default.(string); !scalar {
+			if jsn, err := json.Marshal(schema.Default); err == nil {
+				field.Default = string(jsn)
+			}
+		}
+	}
+	if _, secret := isSecretRef(schema); secret {
+		field.Type = "string"
+		field.Secret = true
+		return field
+	}
+	properties := schema
+	if schema.Items != nil && schema.Items.Schema != nil {
+		properties = *schema.Items.Schema
+	}
+	names := make([]string, 0, len(properties.Properties))
+	for name := range properties.Properties {
+		if name == "sink" {
+			// sink is managed by the CLI
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field.Fields = append(field.Fields, newField(name, properties.Properties[name], isRequired(properties, name)))
+	}
+	return field
+}
+
+func schemaType(schema spec.Schema) string {
+	typ := strings.Join(schema.Type, ",")
+	switch {
+	case typ == "array" && schema.Items != nil && schema.Items.Schema != nil:
+		return "[]" + schemaType(*schema.Items.Schema)
+	case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return "map[string]" + schemaType(*schema.AdditionalProperties.Schema)
+	case typ == "" && len(schema.Properties) != 0:
+		return "object"
+	case typ == "":
+		return "any"
+	}
+	return typ
+}
+
+func isRequired(schema spec.Schema, name string) bool {
+	for _, required := range schema.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+	"github.com/triggermesh/tmctl/test"
+)
+
+func TestExplain(t *testing.T) {
+	schema, err := crd.ServedSchema(test.CRD()["awss3source"])
+	assert.NoError(t, err)
+
+	field, err := schema.Explain("auth", "credentials")
+	assert.NoError(t, err)
+	assert.Equal(t, "object", field.Type)
+	assert.Len(t, field.Fields, 2)
+	assert.Equal(t, "accessKeyID", field.Fields[0].Name)
+	assert.True(t, field.Fields[0].Secret)
+	assert.Empty(t, field.Fields[0].Fields)
+
+	field, err = schema.Explain("destination", "sqs", "queueARN")
+	assert.NoError(t, err)
+	assert.True(t, field.Required)
+	assert.Equal(t, "string", field.Type)
+
+	_, err = schema.Explain("auth", "credentials", "accessKeyID", "valueFromSecret")
+	assert.Error(t, err)
+	_, err = schema.Explain("nonexisting")
+	assert.Error(t, err)
+}
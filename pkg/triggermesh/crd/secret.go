@@ -22,6 +22,8 @@ import (
 	"strings"
 
 	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/triggermesh/tmctl/pkg/secrets"
 )
 
 // secret objects in TriggerMesh are named
@@ -43,6 +45,10 @@ func ExtractSecrets(componentName string, schema Schema, spec map[string]interfa
 			if key, ok := isSecretRef(nestedSchema); ok {
 				if secretValue, ok := v.(string); ok {
 					result[k] = base64.StdEncoding.EncodeToString([]byte(secretValue))
+					if secrets.IsReference(secretValue) {
+						// references are resolved when the secret is used
+						result[k] = secretValue
+					}
 				} else {
 					return nil, fmt.Errorf("%q is expected to contain secret string, got %T", k, v)
 				}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// FieldChange is the spec field that does not exist in the new CRD version.
+type FieldChange struct {
+	Path string
+	// RenamedTo is set if the field was renamed in the spec.
+	RenamedTo string
+	// Candidates are the new CRD fields that may replace the removed one.
+	Candidates []string
+}
+
+func (c FieldChange) String() string {
+	switch {
+	case c.RenamedTo != "":
+		return fmt.Sprintf("%q is renamed to %q", c.Path, c.RenamedTo)
+	case len(c.Candidates) != 0:
+		return fmt.Sprintf("%q is removed, possible replacements: %s", c.Path, strings.Join(c.Candidates, ", "))
+	}
+	return fmt.Sprintf("%q is removed", c.Path)
+}
+
+// Removed returns true if the field has no replacement in the spec.
+func (c FieldChange) Removed() bool {
+	return c.RenamedTo == ""
+}
+
+// ServedSchema returns the spec schema of the served CRD version.
+func ServedSchema(c CRD) (*Schema, error) {
+	for _, v := range c.Spec.Versions {
+		if v.Served {
+			return GetSchema(v.Schema.OpenAPIV3Schema.Properties.Spec)
+		}
+	}
+	return nil, fmt.Errorf("CRD %q schema not found", c.Spec.Names.Kind)
+}
+
+// MigrateSpec returns the copy of the spec adjusted to the new CRD.
+// Fields that have the new counterpart with the same name, ignoring
+// the case and separators, are renamed. The rest of the unknown fields are
+// reported and, if drop is true, removed from the spec.
+func MigrateSpec(object map[string]interface{}, oldCRD, newCRD CRD, drop bool) (map[string]interface{}, []FieldChange, error) {
+	newSchema, err := ServedSchema(newCRD)
+	if err != nil {
+		return nil, nil, err
+	}
+	var oldSchema *spec.Schema
+	if s, err := ServedSchema(oldCRD); err == nil {
+		oldSchema = &s.schema
+	}
+	result := deepCopy(object).(map[string]interface{})
+	var changes []FieldChange
+	migrate(oldSchema, newSchema.schema, result, "", drop, &changes)
+	return result, changes, nil
+}
+
+func migrate(oldSchema *spec.Schema, newSchema spec.Schema, object map[string]interface{}, prefix string, drop bool, changes *[]FieldChange) {
+	if newSchema.AdditionalProperties != nil || len(newSchema.Properties) == 0 {
+		// free-form object
+		return
+	}
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := joinPath(prefix, k)
+		property, exists := newSchema.Properties[k]
+		if !exists {
+			change := FieldChange{
+				Path:       path,
+				Candidates: newFields(oldSchema, newSchema, object),
+			}
+			if renamed := sameField(k, change.Candidates); renamed != "" {
+				object[renamed] = object[k]
+				delete(object, k)
+				change.RenamedTo = joinPath(prefix, renamed)
+				change.Candidates = nil
+			} else if drop {
+				delete(object, k)
+			}
+			*changes = append(*changes, change)
+			continue
+		}
+		var oldProperty *spec.Schema
+		if oldSchema != nil {
+			if p, exists := oldSchema.Properties[k]; exists {
+				oldProperty = &p
+			}
+		}
+		switch value := object[k].(type) {
+		case map[string]interface{}:
+			migrate(oldProperty, property, value, path, drop, changes)
+		case []interface{}:
+			if property.Items == nil || property.Items.Schema == nil {
+				continue
+			}
+			var oldItems *spec.Schema
+			if oldProperty != nil && oldProperty.Items != nil {
+				oldItems = oldProperty.Items.Schema
+			}
+			for i, item := range value {
+				if nested, ok := item.(map[string]interface{}); ok {
+					migrate(oldItems, *property.Items.Schema, nested, fmt.Sprintf("%s[%d]", path, i), drop, changes)
+				}
+			}
+		}
+	}
+}
+
+// newFields returns the properties that appeared in the new schema
+// and are not set in the object yet.
+func newFields(oldSchema *spec.Schema, newSchema spec.Schema, object map[string]interface{}) []string {
+	var result []string
+	for k := range newSchema.Properties {
+		if _, set := object[k]; set {
+			continue
+		}
+		if oldSchema != nil {
+			if _, existed := oldSchema.Properties[k]; existed {
+				continue
+			}
+		}
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sameField returns the only candidate with the same name as the field,
+// ignoring the case and separators.
+func sameField(field string, candidates []string) string {
+	var result string
+	for _, candidate := range candidates {
+		if normalize(candidate) == normalize(field) {
+			if result != "" {
+				return ""
+			}
+			result = candidate
+		}
+	}
+	return result
+}
+
+func normalize(field string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(field))
+}
+
+func joinPath(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			result[k] = deepCopy(val)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, val := range v {
+			result[i] = deepCopy(val)
+		}
+		return result
+	}
+	return value
+}
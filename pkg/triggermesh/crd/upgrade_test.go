@@ -0,0 +1,56 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
+	"github.com/triggermesh/tmctl/test"
+)
+
+func TestMigrateSpec(t *testing.T) {
+	oldCRD := test.CRD()["httptarget"]
+	newCRD := test.CRD()["httptarget"]
+
+	properties := newCRD.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties.Spec["properties"].(map[string]interface{})
+	properties["skip_verify"] = properties["skipVerify"]
+	delete(properties, "skipVerify")
+	delete(properties, "method")
+
+	object := map[string]interface{}{
+		"endpoint":   "https://example.com",
+		"method":     "GET",
+		"skipVerify": true,
+	}
+
+	spec, changes, err := crd.MigrateSpec(object, oldCRD, newCRD, false)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.True(t, changes[0].Removed())
+	assert.Equal(t, "method", changes[0].Path)
+	assert.Equal(t, "skip_verify", changes[1].RenamedTo)
+	assert.Equal(t, true, spec["skip_verify"])
+	assert.Equal(t, "GET", spec["method"])
+	assert.Contains(t, object, "skipVerify", "source object must not be modified")
+
+	spec, _, err = crd.MigrateSpec(object, oldCRD, newCRD, true)
+	assert.NoError(t, err)
+	assert.NotContains(t, spec, "method")
+}
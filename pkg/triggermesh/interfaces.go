@@ -43,6 +43,15 @@ type Runnable interface {
 	Stop(context.Context) error
 	Info(context.Context) (*docker.Container, error)
 	Logs(ctx context.Context, since time.Time, follow bool) (io.ReadCloser, error)
+
+	GetImage() string
+}
+
+// Annotated is implemented by the components that keep their runtime
+// parameters, such as the container restart policy, in the object annotations.
+type Annotated interface {
+	GetAnnotations() map[string]string
+	SetAnnotation(key, value string)
 }
 
 // Producer is implemeted by all components that produce events.
@@ -69,9 +78,11 @@ type Parent interface {
 type Reconcilable interface {
 	Initialize(context.Context, map[string]string) (map[string]interface{}, error)
 	Finalize(context.Context, map[string]string) error
+	Plan() ([]string, error)
 
 	UpdateStatus(map[string]interface{})
 	GetExternalResources() map[string]interface{}
+	ExternalResourceExists(context.Context, map[string]string, string) (bool, error)
 }
 
 type Exportable interface {
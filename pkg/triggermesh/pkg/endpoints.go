@@ -0,0 +1,87 @@
+/*
+Copyright 2023 TriggerMesh Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Services which endpoints can be overridden, e.g. to use the emulators.
+const (
+	// EndpointAWS is the URL of all AWS services, e.g. http://localstack:4566.
+	EndpointAWS = "aws"
+	// EndpointPubSub is the host:port of the Google Cloud Pub/Sub emulator.
+	EndpointPubSub = "pubsub"
+	// EndpointStorage is the host:port or the URL of the Google Cloud Storage emulator.
+	EndpointStorage = "storage"
+)
+
+// ParseEndpoints reads the comma separated "service=endpoint" overrides.
+func ParseEndpoints(value string) (map[string]string, error) {
+	endpoints := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		service, endpoint, found := strings.Cut(item, "=")
+		if !found || endpoint == "" {
+			return nil, fmt.Errorf("%q is not a service=endpoint pair", item)
+		}
+		if err := validateEndpoint(service, endpoint); err != nil {
+			return nil, err
+		}
+		endpoints[service] = endpoint
+	}
+	return endpoints, nil
+}
+
+// FormatEndpoints is the reverse of ParseEndpoints.
+func FormatEndpoints(endpoints map[string]string) string {
+	result := make([]string, 0, len(endpoints))
+	for service, endpoint := range endpoints {
+		result = append(result, service+"="+endpoint)
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
+func validateEndpoint(service, endpoint string) error {
+	switch service {
+	case EndpointAWS:
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s endpoint %q must be the URL, e.g. http://localhost:4566", service, endpoint)
+		}
+	case EndpointPubSub:
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return fmt.Errorf("%s endpoint %q must be host:port, e.g. localhost:8085", service, endpoint)
+		}
+	case EndpointStorage:
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("%s endpoint %q must be host:port or the URL", service, endpoint)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported endpoint service %q, expected %q, %q or %q",
+			service, EndpointAWS, EndpointPubSub, EndpointStorage)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package pkg
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -79,3 +80,22 @@ func OpenPort() int {
 	listener.Close()
 	return listener.Addr().(*net.TCPAddr).Port
 }
+
+// ParsePort validates the host port value.
+func ParsePort(port string) (int, error) {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return 0, fmt.Errorf("invalid port %q, must be a number between 1 and 65535", port)
+	}
+	return p, nil
+}
+
+// PortAvailable returns true if the host port can be bound.
+func PortAvailable(port string) bool {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
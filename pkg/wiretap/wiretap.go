@@ -42,7 +42,7 @@ type Wiretap struct {
 }
 
 const (
-	image = "gcr.io/knative-releases/knative.dev/eventing/cmd/event_display"
+	Image = "gcr.io/knative-releases/knative.dev/eventing/cmd/event_display"
 	port  = "8080/tcp"
 )
 
@@ -60,17 +60,17 @@ func New(broker, configBase string) (*Wiretap, error) {
 
 func (w *Wiretap) CreateAdapter(ctx context.Context) (io.ReadCloser, error) {
 	co := []docker.ContainerOption{
-		docker.WithImage(image),
+		docker.WithImage(Image),
 		docker.WithPort(port),
 		docker.WithEnv([]string{"K_CONFIG_TRACING={}"}),
 	}
 	ho := []docker.HostOption{
-		docker.WithHostPortBinding(port),
+		docker.WithHostPortBinding(port, ""),
 		docker.WithExtraHost(),
 	}
 	container := &docker.Container{
 		Name:                   fmt.Sprintf("%s-wiretap", w.Broker),
-		Image:                  image,
+		Image:                  Image,
 		CreateHostOptions:      ho,
 		CreateContainerOptions: co,
 	}
@@ -109,7 +109,7 @@ func (w *Wiretap) CreateTrigger() error {
 }
 
 func (w *Wiretap) BrokerLogs(ctx context.Context, c config.BrokerConfig) (io.ReadCloser, error) {
-	bro, err := tmbroker.New(w.Broker, c)
+	bro, err := tmbroker.New(w.Broker, w.ConfigBase, c)
 	if err != nil {
 		return nil, err
 	}
@@ -19,14 +19,27 @@ package test
 import (
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
+	"testing"
 
 	"github.com/triggermesh/tmctl/pkg/triggermesh/crd"
 )
 
-func Manifest() string {
+// Manifest returns the path of the test manifest copy in the test
+// temporary directory, so that the tests do not change the fixtures.
+func Manifest(t testing.TB) string {
+	t.Helper()
 	_, filename, _, _ := runtime.Caller(0)
-	return path.Dir(filename) + "/fixtures/manifest.yaml"
+	data, err := os.ReadFile(path.Dir(filename) + "/fixtures/manifest.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(manifest, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
 }
 
 func CRD() map[string]crd.CRD {